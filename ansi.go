@@ -3,10 +3,7 @@ package ansi
 import (
 	"fmt"
 	"os"
-	"runtime"
-	"sort"
 	"strings"
-	"sync"
 	_ "time"
 
 	"golang.org/x/term"
@@ -17,70 +14,15 @@ import (
 // --------------------
 
 // CaptureKey reads a key press from stdin in raw mode and returns a key type and value.
-// It returns one of "Character", "Arrow", "Special" (or "error" if something goes wrong).
+// It returns one of "Character", "Arrow", "Special", "Paste" (or "error" if something goes wrong).
+// "Paste" carries several characters that arrived at once, such as pasted text, and is only
+// returned once turned on with KeyReader.DetectPaste.
+// Control keys are reported as "Special" with values like "ctrl-r", "alt-b", "tab",
+// "shift-tab", "home", "end", "delete", "pageup" and "pagedown", and modified arrows as
+// "Arrow" with values like "ctrl-left".
+// Input is read through the KeyReader set with SetKeyReader.
 func CaptureKey() (string, string) {
-	fd := int(os.Stdin.Fd())
-	oldState, err := term.MakeRaw(fd)
-	if err != nil {
-		return "error", err.Error()
-	}
-	defer term.Restore(fd, oldState)
-
-	b := make([]byte, 3)
-	n, err := os.Stdin.Read(b)
-	if err != nil {
-		return "error", err.Error()
-	}
-	keyStr := string(b[:n])
-
-	if runtime.GOOS == "windows" {
-		if n == 1 && b[0] == 27 {
-			return "Special", "escape"
-		}
-		if n > 0 && (b[0] == 0 || b[0] == 224) {
-			if n < 2 {
-				os.Stdin.Read(b[1:2])
-			}
-			switch b[1] {
-			case 'H':
-				return "Arrow", "up"
-			case 'P':
-				return "Arrow", "down"
-			case 'K':
-				return "Arrow", "left"
-			case 'M':
-				return "Arrow", "right"
-			}
-		}
-		if n == 1 {
-			switch b[0] {
-			case 8:
-				return "Special", "backspace"
-			case 13:
-				return "Special", "enter"
-			}
-		}
-		return "Character", keyStr
-	} else {
-		switch keyStr {
-		case "\x1b":
-			return "Special", "escape"
-		case "\x1b[A":
-			return "Arrow", "up"
-		case "\x1b[B":
-			return "Arrow", "down"
-		case "\x1b[C":
-			return "Arrow", "right"
-		case "\x1b[D":
-			return "Arrow", "left"
-		case "\x7f":
-			return "Special", "backspace"
-		case "\r", "\n":
-			return "Special", "enter"
-		default:
-			return "Character", keyStr
-		}
-	}
+	return defaultKeyReader().CaptureKey()
 }
 
 
@@ -211,194 +153,6 @@ func NPrint(str, character string, newline, empty bool) {
 	}
 }
 
-// --------------------
-// DInput with Autocompletion
-// --------------------
-
-// findClosestMatch returns the first completion that starts with input.
-func findClosestMatch(input string, completions []string) string {
-	if input == "" {
-		return ""
-	}
-	for _, word := range completions {
-		if strings.HasPrefix(word, input) {
-			return word
-		}
-	}
-	return ""
-}
-
-// autocomplete appends the remaining characters of the closest match.
-func autocomplete(input string, completions []string) string {
-	closest := findClosestMatch(input, completions)
-	if closest != "" && closest != input {
-		return input + closest[len(input):]
-	}
-	return input
-}
-
-// DInput provides an interactive input prompt with autocomplete based on a list of completions.
-func DInput(completions []string, prompt string) string {
-	var text []rune
-	NPrint(prompt+" ", "#", false, true)
-	for {
-		keyType, key := CaptureKey()
-		if keyType == "Special" {
-			if key == "enter" {
-				break
-			} else if key == "backspace" && len(text) > 0 {
-				text = text[:len(text)-1]
-			}
-		} else if keyType == "Character" {
-			text = append(text, []rune(key)...)
-		}
-		un := string(text)
-		fin := ""
-		// Process each word separately, coloring correctly if it matches a completion.
-		words := strings.Split(un, " ")
-		for i, word := range words {
-			if word != "" {
-				match := false
-				for _, comp := range completions {
-					if word == comp {
-						match = true
-						break
-					}
-				}
-				if match {
-					fin += Green + word + End
-				} else {
-					fin += Red + word + End
-				}
-				if i < len(words)-1 {
-					fin += " "
-				}
-			}
-		}
-		// Autocomplete for the last word
-		lastWord := ""
-		if len(words) > 0 {
-			lastWord = words[len(words)-1]
-		}
-		autoWord := autocomplete(lastWord, completions)
-		if len(autoWord) > len(lastWord) {
-			fin += Faint + autoWord[len(lastWord):] + End
-		}
-		NPrint(prompt+" "+fin, "#", false, true)
-	}
-	fmt.Println()
-	return string(text)
-}
-
-// --------------------
-// MultiProgressBar
-// --------------------
-
-// ProgressBar represents an individual progress bar.
-type ProgressBar struct {
-	Progress int
-	Total    int
-	Line     int
-}
-
-// MultiProgressBar manages several progress bars concurrently.
-type MultiProgressBar struct {
-	Bars map[string]*ProgressBar
-	Lock sync.Mutex
-}
-
-// NewMultiProgressBar creates and returns a new MultiProgressBar.
-func NewMultiProgressBar() *MultiProgressBar {
-	return &MultiProgressBar{
-		Bars: make(map[string]*ProgressBar),
-	}
-}
-
-// AddBar adds a new progress bar with the given name and total.
-func (mpb *MultiProgressBar) AddBar(name string, total int) {
-	mpb.Lock.Lock()
-	defer mpb.Lock.Unlock()
-	mpb.Bars[name] = &ProgressBar{Progress: 0, Total: total, Line: len(mpb.Bars)}
-}
-
-// UpdateBar updates the progress of a named bar.
-func (mpb *MultiProgressBar) UpdateBar(name string, progress int) {
-	mpb.Lock.Lock()
-	defer mpb.Lock.Unlock()
-	if bar, ok := mpb.Bars[name]; ok {
-		if progress > bar.Total {
-			bar.Progress = bar.Total
-		} else {
-			bar.Progress = progress
-		}
-		mpb.draw()
-	}
-}
-
-// draw renders all the progress bars.
-func (mpb *MultiProgressBar) draw() {
-	// Move cursor up for the number of bars and clear each line.
-	for i := 0; i < len(mpb.Bars); i++ {
-		fmt.Print("\033[F") // Move cursor up one line.
-		fmt.Print("\033[K") // Clear the line.
-	}
-	// Sort the bars by their line number.
-	type barEntry struct {
-		Name string
-		Bar  *ProgressBar
-	}
-	var entries []barEntry
-	for name, bar := range mpb.Bars {
-		entries = append(entries, barEntry{Name: name, Bar: bar})
-	}
-	sort.Slice(entries, func(i, j int) bool {
-		return entries[i].Bar.Line < entries[j].Bar.Line
-	})
-	// Draw each progress bar.
-	for _, entry := range entries {
-		bar := entry.Bar
-		percent := 1.0
-		if bar.Total > 0 {
-			percent = float64(bar.Progress) / float64(bar.Total)
-		}
-		barLen := 50
-		filledLen := int(float64(barLen) * percent)
-		filled := strings.Repeat(Green+"█"+End, filledLen)
-		empty := strings.Repeat("-", barLen-filledLen)
-		fmt.Printf("%s: [%s%s] %d/%d\n", entry.Name, filled, empty, bar.Progress, bar.Total)
-	}
-	// Reset any attributes.
-	fmt.Print("\033[0m")
-}
-
-// FinishBar sets a progress bar to complete.
-func (mpb *MultiProgressBar) FinishBar(name string) {
-	mpb.Lock.Lock()
-	defer mpb.Lock.Unlock()
-	if bar, ok := mpb.Bars[name]; ok {
-		bar.Progress = bar.Total
-		mpb.draw()
-	}
-}
-
-// RemoveBar removes a progress bar.
-func (mpb *MultiProgressBar) RemoveBar(name string) {
-	mpb.Lock.Lock()
-	defer mpb.Lock.Unlock()
-	delete(mpb.Bars, name)
-	mpb.recalculateLines()
-	mpb.draw()
-}
-
-// recalculateLines resets the line numbers for each progress bar.
-func (mpb *MultiProgressBar) recalculateLines() {
-	lineNum := 0
-	for _, bar := range mpb.Bars {
-		bar.Line = lineNum
-		lineNum++
-	}
-}
-
 // --------------------
 // Screen Management
 // --------------------
@@ -437,6 +191,7 @@ func Menu(options []string) int {
   max := len(options)
   for true {
     ctype, char := CaptureKey()
+    if ctype == "error" { break }
     if ctype == "Special" {
       if char == "enter" { break }
     } else if ctype == "Arrow" {
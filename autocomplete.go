@@ -0,0 +1,264 @@
+package ansi
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// --------------------
+// Autocomplete
+// --------------------
+
+// SuggestionSource computes suggestions like a Completer, but may be slow, as
+// when it asks a server. An Autocomplete calls it in a goroutine of its own
+// and cancels ctx once the text changes again.
+type SuggestionSource func(ctx context.Context, line string, pos int) ([]Suggestion, error)
+
+// Autocomplete offers suggestions for the word before the cursor of any text
+// field, like the Input prompt does, and shows them in a dropdown list. The
+// text fields of a Form and an Editor take one in their Autocomplete field.
+// To attach it to another field, call Update whenever the text or the cursor
+// changes, send it each key before the field, inserting the suggestion it
+// reports with Accepted, and draw Lines below the field. It is safe for use
+// from several goroutines.
+type Autocomplete struct {
+	Completer Completer        // Computes the suggestions as the text changes.
+	Source    SuggestionSource // Computes them in the background, if Completer is nil.
+	Delay     time.Duration    // Time without changes before Source is called.
+	Max       int              // Entries of the dropdown shown at once. If 0, there is no dropdown.
+
+	// OnChange, if set, is called when suggestions from Source arrive, from
+	// the goroutine that called it, so the field can be redrawn.
+	OnChange func()
+
+	mu          sync.Mutex
+	line        string
+	pos         int
+	updated     bool // Whether Update has been called.
+	suggestions []Suggestion
+	index       int  // Selected entry, or -1 if none is selected.
+	hidden      bool // Whether the dropdown was closed with Escape.
+	accepted    *Suggestion
+	loading     bool
+	err         error              // Error of the last call of Source.
+	cancel      context.CancelFunc // Cancels the running call of Source.
+}
+
+// NewAutocomplete creates an autocomplete with suggestions from completer
+// and a dropdown of up to 5 entries.
+func NewAutocomplete(completer Completer) *Autocomplete {
+	return &Autocomplete{Completer: completer, Max: 5, index: -1}
+}
+
+// NewAsyncAutocomplete creates an autocomplete with suggestions from
+// source, called once the text has not changed for 150ms, and a dropdown
+// of up to 5 entries.
+func NewAsyncAutocomplete(source SuggestionSource) *Autocomplete {
+	return &Autocomplete{Source: source, Delay: 150 * time.Millisecond, Max: 5, index: -1}
+}
+
+// Update gives the text of the field and the rune position of its cursor,
+// computing the suggestions again if either changed.
+func (a *Autocomplete) Update(line string, pos int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.updated && line == a.line && pos == a.pos {
+		return
+	}
+	a.updated, a.line, a.pos = true, line, pos
+	if a.cancel != nil {
+		a.cancel()
+		a.cancel = nil
+	}
+	a.index, a.err = -1, nil
+	switch {
+	case a.Completer != nil:
+		a.suggestions = a.Completer(line, pos)
+	case a.Source != nil:
+		a.suggestions, a.loading = nil, true
+		ctx, cancel := context.WithCancel(context.Background())
+		a.cancel = cancel
+		go a.fetch(ctx, line, pos)
+	default:
+		a.suggestions = nil
+	}
+}
+
+// fetch calls Source after the delay, unless ctx is canceled first.
+func (a *Autocomplete) fetch(ctx context.Context, line string, pos int) {
+	if a.Delay > 0 {
+		timer := time.NewTimer(a.Delay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+	}
+	suggestions, err := a.Source(ctx, line, pos)
+	a.mu.Lock()
+	if ctx.Err() != nil {
+		a.mu.Unlock()
+		return
+	}
+	a.suggestions, a.err, a.loading = suggestions, err, false
+	onChange := a.OnChange
+	a.mu.Unlock()
+	if onChange != nil {
+		onChange()
+	}
+}
+
+// Close cancels the call of Source that is running, if any.
+func (a *Autocomplete) Close() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.cancel != nil {
+		a.cancel()
+		a.cancel = nil
+	}
+	a.loading = false
+}
+
+// Suggestions returns the suggestions for the text last given to Update.
+func (a *Autocomplete) Suggestions() []Suggestion {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.suggestions
+}
+
+// Loading reports whether suggestions are being fetched from Source.
+func (a *Autocomplete) Loading() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.loading
+}
+
+// Err returns the error of the last call of Source, if it failed.
+func (a *Autocomplete) Err() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.err
+}
+
+// Best returns the suggestion selected in the dropdown or, if none is, the
+// first one, which a field may show as a hint after its cursor.
+func (a *Autocomplete) Best() (Suggestion, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	switch {
+	case a.index >= 0 && a.index < len(a.suggestions):
+		return a.suggestions[a.index], true
+	case len(a.suggestions) > 0:
+		return a.suggestions[0], true
+	}
+	return Suggestion{}, false
+}
+
+// Accepted returns the suggestion chosen with Enter by the last key given to
+// HandleKey, if it chose one.
+func (a *Autocomplete) Accepted() (Suggestion, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.accepted == nil {
+		return Suggestion{}, false
+	}
+	s := *a.accepted
+	a.accepted = nil
+	return s, true
+}
+
+// HandleKey handles the keys of the dropdown and reports whether key was one
+// of them: Tab and the arrow keys move through the list, Enter accepts the
+// selected entry and Escape closes the list. Any other key drops the
+// selection, and keys other than arrows open the list again.
+func (a *Autocomplete) HandleKey(keyType, key string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.accepted = nil
+	if n := len(a.suggestions); a.Max > 0 && !a.hidden && n > 0 {
+		switch {
+		case keyType == "Special" && key == "tab", keyType == "Arrow" && key == "down":
+			a.index = (a.index + 1) % n
+			return true
+		case keyType == "Special" && key == "shift-tab", keyType == "Arrow" && key == "up":
+			if a.index <= 0 {
+				a.index = n
+			}
+			a.index--
+			return true
+		case keyType == "Special" && key == "enter" && a.index >= 0:
+			s := a.suggestions[min(a.index, n-1)]
+			a.accepted = &s
+			a.index = -1
+			a.hidden = true
+			return true
+		case keyType == "Special" && key == "escape":
+			a.index = -1
+			a.hidden = true
+			return true
+		}
+	}
+	a.index = -1
+	if keyType != "Arrow" && key != "tab" && key != "shift-tab" {
+		a.hidden = false
+	}
+	return false
+}
+
+// Insert returns line with the word before rune position pos replaced by s,
+// and the position after it.
+func (a *Autocomplete) Insert(line string, pos int, s Suggestion) (string, int) {
+	rs := []rune(line)
+	start := wordBefore(rs, pos)
+	text := []rune(s.Text)
+	return string(rs[:start]) + string(text) + string(rs[pos:]), start + len(text)
+}
+
+// Lines returns the dropdown, up to Max entries and height rows of width
+// columns, scrolled to show the selected entry. While Source runs it shows
+// "…", and if it failed its error.
+func (a *Autocomplete) Lines(width, height int) []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	n := min(a.Max, height)
+	switch {
+	case n <= 0 || a.hidden:
+		return nil
+	case a.loading && len(a.suggestions) == 0:
+		return []string{Faint + "…" + End}
+	case a.err != nil:
+		return []string{truncateWidth(Red+a.err.Error()+End, width)}
+	}
+	start := 0
+	if a.index >= n {
+		start = a.index - n + 1
+	}
+	var lines []string
+	for i := start; i < min(start+n, len(a.suggestions)); i++ {
+		item := a.suggestions[i].Text
+		if i == a.index {
+			item = Negative + item + End
+		}
+		if desc := a.suggestions[i].Description; desc != "" {
+			item += "  " + Faint + desc + End
+		}
+		lines = append(lines, truncateWidth(item, width))
+	}
+	return lines
+}
+
+// hint returns the text shown faint after the cursor for the best
+// suggestion, when word is the part of the word before the cursor.
+func (a *Autocomplete) hint(word string) string {
+	s, ok := a.Best()
+	if !ok || s.Text == word {
+		return ""
+	}
+	if strings.HasPrefix(s.Text, word) {
+		return s.Text[len(word):]
+	}
+	return " → " + s.Text
+}
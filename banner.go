@@ -0,0 +1,228 @@
+package ansi
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// --------------------
+// Banner
+// --------------------
+
+// Font is a font of big letters for a Banner, each drawn over Height lines.
+type Font struct {
+	Height int
+	Glyphs map[rune][]string // Lines of each letter, all as wide.
+}
+
+// glyph returns the lines of r, in upper case if the font has no lower case,
+// or of "?" if it has neither.
+func (f *Font) glyph(r rune) []string {
+	for _, c := range []rune{r, []rune(strings.ToUpper(string(r)))[0], '?'} {
+		if g, ok := f.Glyphs[c]; ok {
+			return g
+		}
+	}
+	return make([]string, f.Height)
+}
+
+// BlockFont is the built-in font of Banner: capital letters, digits and
+// common punctuation in full blocks, five lines high.
+var BlockFont = blockFont(map[rune]string{
+	'A': " ## |#  #|####|#  #|#  #", 'B': "### |#  #|### |#  #|### ", 'C': " ###|#   |#   |#   | ###",
+	'D': "### |#  #|#  #|#  #|### ", 'E': "####|#   |### |#   |####", 'F': "####|#   |### |#   |#   ",
+	'G': " ###|#   |# ##|#  #| ###", 'H': "#  #|#  #|####|#  #|#  #", 'I': "###| # | # | # |###",
+	'J': "  ##|   #|   #|#  #| ## ", 'K': "#  #|# # |##  |# # |#  #", 'L': "#   |#   |#   |#   |####",
+	'M': "#   #|## ##|# # #|#   #|#   #", 'N': "#   #|##  #|# # #|#  ##|#   #", 'O': " ## |#  #|#  #|#  #| ## ",
+	'P': "### |#  #|### |#   |#   ", 'Q': " ## |#  #|#  #|# ##| ###", 'R': "### |#  #|### |# # |#  #",
+	'S': " ###|#   | ## |   #|### ", 'T': "#####|  #  |  #  |  #  |  #  ", 'U': "#  #|#  #|#  #|#  #| ## ",
+	'V': "#   #|#   #|#   #| # # |  #  ", 'W': "#   #|#   #|# # #|## ##|#   #", 'X': "#   #| # # |  #  | # # |#   #",
+	'Y': "#   #| # # |  #  |  #  |  #  ", 'Z': "####|   #|  # | #  |####",
+	'0': " ## |# ##|## #|#  #| ## ", '1': " # |## | # | # |###", '2': " ## |#  #|  # | #  |####",
+	'3': "### |   #| ## |   #|### ", '4': "#  #|#  #|####|   #|   #", '5': "####|#   |### |   #|### ",
+	'6': " ## |#   |### |#  #| ## ", '7': "####|   #|  # | #  | #  ", '8': " ## |#  #| ## |#  #| ## ",
+	'9': " ## |#  #| ###|   #| ## ",
+	' ': "  |  |  |  |  ", '!': "#|#|#| |#", '?': "### |   #| ## |    | #  ", '.': " | | | |#",
+	',': "  |  |  | #|# ", ':': " |#| |#| ", ';': "  | #|  | #|# ", '\'': "#|#| | | ", '"': "# #|# #|   |   |   ",
+	'-': "   |   |###|   |   ", '+': "   | # |###| # |   ", '=': "   |###|   |###|   ", '_': "    |    |    |    |####",
+	'/': "    #|   # |  #  | #   |#    ", '(': " #|# |# |# | #", ')': "# | #| #| #|# ",
+	'#': " # # |#####| # # |#####| # # ", '%': "#   #|   # |  #  | #   |#   #", '*': "     |# # #| ### |# # #|     ",
+	'<': "  #| # |#  | # |  #", '>': "#  | # |  #| # |#  ",
+})
+
+// blockFont builds a font from glyphs written as rows separated by "|",
+// with "#" for a block.
+func blockFont(glyphs map[rune]string) *Font {
+	f := &Font{Height: 5, Glyphs: map[rune][]string{}}
+	for r, g := range glyphs {
+		f.Glyphs[r] = strings.Split(strings.ReplaceAll(g, "#", "█"), "|")
+	}
+	return f
+}
+
+// LoadFiglet reads a font in the FIGlet format (.flf) of the figlet
+// program, with the printable ASCII characters it defines.
+func LoadFiglet(r io.Reader) (*Font, error) {
+	sc := bufio.NewScanner(r)
+	if !sc.Scan() {
+		return nil, fmt.Errorf("figlet font: missing header")
+	}
+	header := strings.Fields(sc.Text())
+	if len(header) < 6 || !strings.HasPrefix(header[0], "flf2a") || len(header[0]) < 6 {
+		return nil, fmt.Errorf("figlet font: bad header %q", sc.Text())
+	}
+	hardBlank := header[0][5:6]
+	height, err1 := strconv.Atoi(header[1])
+	comments, err2 := strconv.Atoi(header[5])
+	if err1 != nil || err2 != nil || height <= 0 {
+		return nil, fmt.Errorf("figlet font: bad header %q", sc.Text())
+	}
+	for range comments {
+		sc.Scan()
+	}
+
+	f := &Font{Height: height, Glyphs: map[rune][]string{}}
+	for c := ' '; c <= '~'; c++ {
+		lines := make([]string, height)
+		for i := range lines {
+			if !sc.Scan() {
+				if err := sc.Err(); err != nil {
+					return nil, err
+				}
+				return f, nil
+			}
+			// Lines end with one end mark, the last line of a letter with two.
+			line := strings.TrimRight(sc.Text(), " \r")
+			if line != "" {
+				line = strings.TrimRight(line, line[len(line)-1:])
+			}
+			lines[i] = strings.ReplaceAll(line, hardBlank, " ")
+		}
+		width := 0
+		for _, l := range lines {
+			width = max(width, visibleWidth(l))
+		}
+		for i, l := range lines {
+			lines[i] = l + strings.Repeat(" ", width-visibleWidth(l))
+		}
+		f.Glyphs[c] = lines
+	}
+	return f, sc.Err()
+}
+
+// Banner draws text in big letters, for titles and splash screens, colored
+// with a style or with a gradient from left to right.
+type Banner struct {
+	Text     string    // Lines are separated by "\n".
+	Font     *Font     // BlockFont if not set with NewBanner.
+	Style    string    // Style of the letters, if there is no Gradient.
+	Gradient HeatScale // Colors blended across the width of the banner.
+	Spacing  int       // Columns between letters. The default is 1.
+}
+
+// NewBanner creates a banner of text in BlockFont.
+func NewBanner(text string) *Banner {
+	return &Banner{Text: text, Font: BlockFont, Spacing: 1}
+}
+
+// Print prints the banner to stdout.
+func (b *Banner) Print() {
+	fmt.Print(b.String())
+}
+
+// String renders the banner in the width of the terminal, ending with a
+// newline.
+func (b *Banner) String() string {
+	width, _ := termSize(os.Stdout)
+	return strings.Join(b.Lines(width, math.MaxInt), "\n") + "\n"
+}
+
+// Lines returns the banner cut to width columns and height rows, for
+// drawing it in a Pane, GridCell or Tab. Lines of Text are separated by a
+// blank line.
+func (b *Banner) Lines(width, height int) []string {
+	font := b.Font
+	if font == nil {
+		font = BlockFont
+	}
+	var lines []string
+	for i, text := range strings.Split(b.Text, "\n") {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		rows := make([]string, font.Height)
+		for j, r := range []rune(text) {
+			g := font.glyph(r)
+			for k := range rows {
+				if j > 0 {
+					rows[k] += strings.Repeat(" ", max(b.Spacing, 0))
+				}
+				if k < len(g) {
+					rows[k] += g[k]
+				}
+			}
+		}
+		lines = append(lines, rows...)
+	}
+
+	bannerWidth := 0
+	for _, l := range lines {
+		bannerWidth = max(bannerWidth, visibleWidth(l))
+	}
+	lines = lines[:min(len(lines), max(height, 0))]
+	for i, l := range lines {
+		l = truncateWidth(l, width)
+		switch {
+		case len(b.Gradient) > 0:
+			lines[i] = b.gradient(l, bannerWidth)
+		case b.Style != "" && l != "":
+			lines[i] = b.Style + l + End
+		default:
+			lines[i] = l
+		}
+	}
+	return lines
+}
+
+// gradient colors each column of line with the color of the gradient at
+// that point of a banner width columns wide.
+func (b *Banner) gradient(line string, width int) string {
+	var sb strings.Builder
+	col := 0
+	for _, r := range line {
+		if r == ' ' {
+			sb.WriteRune(r)
+		} else {
+			sb.WriteString(b.Gradient.At(float64(col)/float64(max(width-1, 1))).Fg() + string(r))
+		}
+		col += runeWidth(r)
+	}
+	if col > 0 {
+		sb.WriteString(End)
+	}
+	return sb.String()
+}
+
+// Render draws the banner in the middle of screen, clearing the rest of it,
+// for a splash screen on the alternate screen.
+func (b *Banner) Render(screen Rect) string {
+	lines := b.Lines(screen.Width, screen.Height)
+	width := 0
+	for _, l := range lines {
+		width = max(width, visibleWidth(l))
+	}
+	pad := strings.Repeat(" ", max(screen.Width-width, 0)/2)
+	top := max(screen.Height-len(lines), 0) / 2
+	return renderRect(screen, func(int, int) []string {
+		out := make([]string, top, top+len(lines))
+		for _, l := range lines {
+			out = append(out, pad+l)
+		}
+		return out
+	})
+}
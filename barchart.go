@@ -0,0 +1,536 @@
+package ansi
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strings"
+)
+
+// --------------------
+// Bar Chart
+// --------------------
+
+// chartPalette are the styles given to series that have none.
+var chartPalette = []string{Cyan, Green, Yellow, Purple, Blue, Red}
+
+// eighthsWide and eighthsHigh are the blocks filling one to seven eighths of
+// a cell from the left and from the bottom.
+var (
+	eighthsWide = []rune("▏▎▍▌▋▊▉")
+	eighthsHigh = []rune("▁▂▃▄▅▆▇")
+)
+
+// BarSeries is a series of values of a BarChart, one for each label.
+type BarSeries struct {
+	Name   string
+	Values []float64
+	Style  string // The default is a color of its own.
+}
+
+// BarChart draws one or more series of values as bars, side by side or
+// stacked, scaled to fit the space they are given, with a legend of the
+// series when there are several. Negative values count as zero.
+type BarChart struct {
+	Labels     []string // Label of each group of bars.
+	Series     []BarSeries
+	Horizontal bool      // Whether the bars grow to the right, one row per bar, instead of upwards.
+	Stacked    bool      // Whether the series are stacked in one bar per label.
+	ShowValues bool      // Whether values are shown next to or above the bars.
+	Axes       bool      // Whether a scale of values is drawn along the bars.
+	ValueAxis  ChartAxis // Ticks and title of the scale, and format and unit of all values shown.
+	Max        float64   // Value of a full bar. The default is the largest value, or sum when stacked.
+	Width      int       // Width of String; 0 uses the width of the terminal.
+	Height     int       // Height of String for vertical bars; 0 uses 10 rows.
+}
+
+// NewBarChart creates a bar chart with labels.
+func NewBarChart(labels ...string) *BarChart {
+	return &BarChart{Labels: labels}
+}
+
+// Add adds a series of values with style, or a color of its own if style is "".
+func (c *BarChart) Add(name string, values []float64, style string) {
+	c.Series = append(c.Series, BarSeries{name, values, style})
+}
+
+// Print prints the chart to stdout.
+func (c *BarChart) Print() {
+	fmt.Print(c.String())
+}
+
+// String renders the chart in Width and Height, ending with a newline.
+func (c *BarChart) String() string {
+	width := c.Width
+	if width <= 0 {
+		width, _ = termSize(os.Stdout)
+	}
+	height := c.Height
+	if height <= 0 {
+		height = 10
+	}
+	if c.Horizontal {
+		height = math.MaxInt
+	}
+	lines := c.Lines(width, height)
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// style returns the style of series i.
+func (c *BarChart) style(i int) string {
+	if c.Series[i].Style != "" {
+		return c.Series[i].Style
+	}
+	return chartPalette[i%len(chartPalette)]
+}
+
+// groups returns the number of groups of bars.
+func (c *BarChart) groups() int {
+	n := len(c.Labels)
+	for _, s := range c.Series {
+		n = max(n, len(s.Values))
+	}
+	return n
+}
+
+// value returns value i of series s, or 0 if there is none or it is
+// negative, NaN or infinite.
+func (c *BarChart) value(s, i int) float64 {
+	if values := c.Series[s].Values; i < len(values) && finite(values[i]) && values[i] > 0 {
+		return values[i]
+	}
+	return 0
+}
+
+// label returns the label of group i, or "".
+func (c *BarChart) label(i int) string {
+	if i < len(c.Labels) {
+		return c.Labels[i]
+	}
+	return ""
+}
+
+// scale returns the value of a full bar.
+func (c *BarChart) scale() float64 {
+	if finite(c.Max) && c.Max > 0 {
+		return c.Max
+	}
+	top := 0.0
+	for i := range c.groups() {
+		sum := 0.0
+		for s := range c.Series {
+			if c.Stacked {
+				sum += c.value(s, i)
+			} else {
+				top = max(top, c.value(s, i))
+			}
+		}
+		top = max(top, sum)
+	}
+	// A sum too large for a float64 still has to scale the bars.
+	return min(top, math.MaxFloat64)
+}
+
+// legend returns the legend of the series.
+func (c *BarChart) legend(width int) []string {
+	names, styles := make([]string, len(c.Series)), make([]string, len(c.Series))
+	for i, s := range c.Series {
+		names[i], styles[i] = s.Name, c.style(i)
+	}
+	return chartLegend(names, styles, width)
+}
+
+// chartLegend returns the names of the series of a chart in their styles,
+// on as many lines of width columns as they need, or nil if there is only
+// one series.
+func chartLegend(names, styles []string, width int) []string {
+	if len(names) < 2 {
+		return nil
+	}
+	var lines []string
+	line := ""
+	for i, name := range names {
+		part := truncateWidth(styles[i]+"■"+End+" "+name, width)
+		switch {
+		case line == "":
+			line = part
+		case visibleWidth(line)+2+visibleWidth(part) <= width:
+			line += "  " + part
+		default:
+			lines = append(lines, line)
+			line = part
+		}
+	}
+	return append(lines, line)
+}
+
+// ChartAxis is how an axis of a chart is marked: the values of its ticks,
+// their unit and a title.
+type ChartAxis struct {
+	Title  string
+	Unit   string               // Appended to the values, like "ms" or "%".
+	Ticks  int                  // Values marked, at round numbers. Below 3 only the ends are marked.
+	Format func(float64) string // Formats the values. The default is FormatCount.
+}
+
+// label returns v formatted as a value of the axis.
+func (a ChartAxis) label(v float64) string {
+	format := a.Format
+	if format == nil {
+		format = FormatCount
+	}
+	return format(v) + a.Unit
+}
+
+// ticks returns the values marked on the axis from lo to hi: about Ticks
+// multiples of a round step, or lo and hi.
+func (a ChartAxis) ticks(lo, hi float64) []float64 {
+	if a.Ticks < 3 || !(hi > lo) || math.IsInf(hi-lo, 0) {
+		return []float64{lo, hi}
+	}
+	step := niceStep((hi - lo) / float64(a.Ticks-1))
+	var ticks []float64
+	for k := math.Ceil(lo / step); k*step <= hi+step/1e6; k++ {
+		v := k * step
+		if v == 0 {
+			v = 0 // Not -0.
+		}
+		ticks = append(ticks, v)
+	}
+	return ticks
+}
+
+// marks returns cols, the columns of the ticks, if they are marked on the
+// line of the axis: only round values are, not the ends.
+func (a ChartAxis) marks(cols []int) []int {
+	if a.Ticks < 3 {
+		return nil
+	}
+	return cols
+}
+
+// niceStep returns the smallest of 1, 2 and 5 times a power of ten that is
+// at least step.
+func niceStep(step float64) float64 {
+	p := math.Pow(10, math.Floor(math.Log10(step)))
+	for _, m := range []float64{1, 2, 5} {
+		if m*p >= step*(1-1e-9) {
+			return m * p
+		}
+	}
+	return 10 * p
+}
+
+// finite reports whether v is neither NaN nor infinite, and so can be drawn.
+func finite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+// axisLabels returns a line of width columns with each label centered on its
+// column in cols, leaving out those that would touch the label before.
+func axisLabels(cols []int, labels []string, width int) string {
+	line := []rune(strings.Repeat(" ", max(width, 0)))
+	next := 0
+	for i, label := range labels {
+		rs := []rune(label)
+		start := max(min(cols[i]-len(rs)/2, width-len(rs)), 0)
+		if start < next || start+len(rs) > width {
+			continue
+		}
+		copy(line[start:], rs)
+		next = start + len(rs) + 1
+	}
+	return strings.TrimRight(string(line), " ")
+}
+
+// axisLine returns a horizontal axis of width columns from a corner, with
+// a tick at each of cols; the corner is column -1.
+func axisLine(cols []int, width int) string {
+	line := []rune("└" + strings.Repeat("─", max(width, 0)))
+	for _, col := range cols {
+		if col >= 0 && col < width {
+			line[col+1] = '┬'
+		}
+	}
+	return string(line)
+}
+
+// Lines returns the chart fitted in width columns and height rows, for
+// drawing it in a Pane, GridCell or Tab.
+func (c *BarChart) Lines(width, height int) []string {
+	if len(c.Series) == 0 || c.groups() == 0 || width <= 0 || height <= 0 {
+		return nil
+	}
+	legend := c.legend(width)
+	var lines []string
+	if c.Horizontal {
+		lines = c.horizontal(width)
+	} else {
+		lines = c.vertical(width, height-len(legend))
+	}
+	lines = append(lines, legend...)
+	return lines[:min(len(lines), height)]
+}
+
+// axis returns the values marked on the scale and the width of the widest
+// of their labels, if the scale is drawn.
+func (c *BarChart) axis(scale float64) ([]float64, int) {
+	if !c.Axes {
+		return nil, 0
+	}
+	ticks := c.ValueAxis.ticks(0, scale)
+	labelWidth := 0
+	for _, v := range ticks {
+		labelWidth = max(labelWidth, visibleWidth(c.ValueAxis.label(v)))
+	}
+	return ticks, labelWidth
+}
+
+// horizontal returns the rows of a chart of bars growing to the right.
+func (c *BarChart) horizontal(width int) []string {
+	labelWidth := 0
+	for i := range c.groups() {
+		labelWidth = max(labelWidth, visibleWidth(c.label(i)))
+	}
+	labelWidth = min(labelWidth, width/3)
+	valueWidth := 0
+	if c.ShowValues {
+		for i := range c.groups() {
+			sum := 0.0
+			for s := range c.Series {
+				valueWidth = max(valueWidth, visibleWidth(c.ValueAxis.label(c.value(s, i))))
+				sum += c.value(s, i)
+			}
+			if c.Stacked {
+				valueWidth = max(valueWidth, visibleWidth(c.ValueAxis.label(sum)))
+			}
+		}
+		valueWidth++
+	}
+	area := max(width-labelWidth-1-valueWidth, 1)
+	scale := c.scale()
+	cells := func(v float64) float64 {
+		if scale <= 0 {
+			return 0
+		}
+		return min(v/scale, 1) * float64(area)
+	}
+	sep := " "
+	if c.Axes {
+		sep = Faint + "│" + End
+	}
+
+	var lines []string
+	for i := range c.groups() {
+		label := truncateWidth(c.label(i), labelWidth)
+		label += strings.Repeat(" ", labelWidth-visibleWidth(label)) + sep
+		if c.Stacked {
+			var sb strings.Builder
+			sum, drawn := 0.0, 0
+			for s := range c.Series {
+				sum += c.value(s, i)
+				end := int(math.Round(cells(sum)))
+				sb.WriteString(c.style(s) + strings.Repeat("█", max(end-drawn, 0)) + End)
+				drawn = max(end, drawn)
+			}
+			line := label + sb.String()
+			if c.ShowValues {
+				line += " " + Faint + c.ValueAxis.label(sum) + End
+			}
+			lines = append(lines, line)
+			continue
+		}
+		if i > 0 && len(c.Series) > 1 {
+			lines = append(lines, strings.TrimRight(strings.Repeat(" ", labelWidth)+sep, " "))
+		}
+		for s := range c.Series {
+			v := c.value(s, i)
+			line := label + c.style(s) + hbar(cells(v)) + End
+			if c.ShowValues {
+				line += " " + Faint + c.ValueAxis.label(v) + End
+			}
+			lines = append(lines, line)
+			label = strings.Repeat(" ", labelWidth) + sep
+		}
+	}
+
+	if ticks, _ := c.axis(scale); ticks != nil {
+		cols, labels := make([]int, len(ticks)), make([]string, len(ticks))
+		for i, v := range ticks {
+			cols[i] = int(math.Round(cells(v))) - 1
+			labels[i] = c.ValueAxis.label(v)
+		}
+		lines = append(lines, Faint+strings.Repeat(" ", labelWidth)+axisLine(c.ValueAxis.marks(cols), area)+End)
+		for i := range cols {
+			cols[i] += labelWidth + 1
+		}
+		lines = append(lines, Faint+axisLabels(cols, labels, width)+End)
+		if title := c.ValueAxis.Title; title != "" {
+			lines = append(lines, Faint+strings.Repeat(" ", labelWidth+1)+truncateWidth(centerText(title, area), area)+End)
+		}
+	}
+	return lines
+}
+
+// hbar returns a horizontal bar n cells long, ending in a partial block, or
+// "" if n is not a positive number.
+func hbar(n float64) string {
+	if !finite(n) || n <= 0 {
+		return ""
+	}
+	full := int(n)
+	bar := strings.Repeat("█", full)
+	if eighths := int((n - float64(full)) * 8); eighths > 0 {
+		bar += string(eighthsWide[eighths-1])
+	}
+	return bar
+}
+
+// vertical returns the rows of a chart of bars growing upwards, with the
+// labels below them.
+func (c *BarChart) vertical(width, height int) []string {
+	scale := c.scale()
+	ticks, labelWidth := c.axis(scale)
+	area := width
+	rows := height - 1
+	if c.Axes {
+		area -= labelWidth + 1
+		rows--
+		if c.ValueAxis.Title != "" {
+			rows--
+		}
+	}
+	if c.ShowValues {
+		rows--
+	}
+	groups := c.groups()
+	perGroup := len(c.Series)
+	if c.Stacked {
+		perGroup = 1
+	}
+	if rows < 1 || area < 1 {
+		return nil
+	}
+	barWidth := max((area-(groups-1))/(groups*perGroup), 1)
+	groupWidth := barWidth * perGroup
+	eighths := func(v float64) int {
+		if scale <= 0 {
+			return 0
+		}
+		return int(math.Round(min(v/scale, 1) * float64(rows*8)))
+	}
+
+	// The style and glyph of each row, from the bottom, of each column of bars.
+	var columns [][][2]string
+	var values []string
+	for i := range groups {
+		if c.Stacked {
+			column := make([][2]string, rows)
+			sum, drawn := 0.0, 0
+			for s := range c.Series {
+				sum += c.value(s, i)
+				end := (eighths(sum) + 4) / 8
+				for row := drawn; row < end; row++ {
+					column[row] = [2]string{c.style(s), "█"}
+				}
+				drawn = end
+			}
+			columns = append(columns, column)
+			values = append(values, c.ValueAxis.label(sum))
+			continue
+		}
+		for s := range c.Series {
+			column := make([][2]string, rows)
+			n := eighths(c.value(s, i))
+			for row := range rows {
+				switch {
+				case n >= (row+1)*8:
+					column[row] = [2]string{c.style(s), "█"}
+				case n > row*8:
+					column[row] = [2]string{c.style(s), string(eighthsHigh[n-row*8-1])}
+				}
+			}
+			columns = append(columns, column)
+			values = append(values, c.ValueAxis.label(c.value(s, i)))
+		}
+	}
+
+	// The label of the scale at each row from the bottom, -1 being the
+	// baseline, and the margin they are drawn in.
+	marks := make(map[int]string)
+	for _, v := range ticks {
+		row := -1
+		if scale > 0 {
+			row = int(math.Round(v/scale*float64(rows))) - 1
+		}
+		marks[row] = c.ValueAxis.label(v)
+	}
+	margin := func(row int) string {
+		if !c.Axes {
+			return ""
+		}
+		label, ok := marks[row]
+		axis := "│"
+		if ok {
+			axis = "┤"
+		}
+		return Faint + strings.Repeat(" ", labelWidth-visibleWidth(label)) + label + axis + End
+	}
+	blank := ""
+	if c.Axes {
+		blank = strings.Repeat(" ", labelWidth+1)
+	}
+
+	var lines []string
+	if title := c.ValueAxis.Title; c.Axes && title != "" {
+		lines = append(lines, Faint+title+End)
+	}
+	if c.ShowValues {
+		var sb strings.Builder
+		sb.WriteString(blank)
+		for j, value := range values {
+			if j > 0 && j%perGroup == 0 {
+				sb.WriteByte(' ')
+			}
+			value = truncateWidth(value, barWidth)
+			sb.WriteString(Faint + value + End + strings.Repeat(" ", barWidth-visibleWidth(value)))
+		}
+		lines = append(lines, sb.String())
+	}
+	for row := rows - 1; row >= 0; row-- {
+		var sb strings.Builder
+		sb.WriteString(margin(row))
+		for j, column := range columns {
+			if j > 0 && j%perGroup == 0 {
+				sb.WriteByte(' ')
+			}
+			if cell := column[row]; cell[1] != "" {
+				sb.WriteString(cell[0] + strings.Repeat(cell[1], barWidth) + End)
+			} else {
+				sb.WriteString(strings.Repeat(" ", barWidth))
+			}
+		}
+		lines = append(lines, sb.String())
+	}
+	if c.Axes {
+		label := marks[-1]
+		lines = append(lines, Faint+strings.Repeat(" ", labelWidth-visibleWidth(label))+label+axisLine(nil, groups*groupWidth+groups-1)+End)
+	}
+	var sb strings.Builder
+	sb.WriteString(blank)
+	for i := range groups {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		label := truncateWidth(c.label(i), groupWidth)
+		sb.WriteString(label + strings.Repeat(" ", groupWidth-visibleWidth(label)))
+	}
+	lines = append(lines, sb.String())
+	for i, line := range lines {
+		lines[i] = truncateWidth(strings.TrimRight(line, " "), width)
+	}
+	return lines
+}
@@ -0,0 +1,40 @@
+package ansi
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestBarChartNonFinite(t *testing.T) {
+	values := []float64{math.Inf(1), math.NaN(), math.Inf(-1), 4, math.MaxFloat64}
+	for _, mode := range []struct {
+		name                string
+		horizontal, stacked bool
+	}{
+		{"vertical", false, false},
+		{"vertical stacked", false, true},
+		{"horizontal", true, false},
+		{"horizontal stacked", true, true},
+	} {
+		t.Run(mode.name, func(t *testing.T) {
+			c := NewBarChart("a", "b", "c", "d", "e")
+			c.Add("x", values, "")
+			c.Add("y", values, "")
+			c.Horizontal, c.Stacked = mode.horizontal, mode.stacked
+			c.ShowValues, c.Axes = true, true
+			if lines := c.Lines(60, 20); len(lines) == 0 {
+				t.Error("no lines")
+			}
+		})
+	}
+
+	for _, n := range []float64{math.NaN(), math.Inf(1), math.Inf(-1), -2} {
+		if got := hbar(n); got != "" {
+			t.Errorf("hbar(%v) = %q, want \"\"", n, got)
+		}
+	}
+	if got := hbar(2.5); got != strings.Repeat("█", 2)+"▌" {
+		t.Errorf("hbar(2.5) = %q", got)
+	}
+}
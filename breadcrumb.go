@@ -0,0 +1,132 @@
+package ansi
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// --------------------
+// Breadcrumb
+// --------------------
+
+// Breadcrumb shows the path to the current place in an app, like
+// "Home ▸ Settings ▸ Network". When it is too wide the segments in the
+// middle are replaced by "…", keeping the first and the last.
+type Breadcrumb struct {
+	Segments       []string
+	Separator      string // Between the segments. The default is " ▸ ".
+	SeparatorStyle string // The default is Faint.
+
+	// Style, if set, returns the style of segment i of the n segments,
+	// instead of Bold for the last and none for the others.
+	Style func(i, n int, segment string) string
+}
+
+// NewBreadcrumb creates a breadcrumb of segments.
+func NewBreadcrumb(segments ...string) *Breadcrumb {
+	return &Breadcrumb{Segments: segments, Separator: " ▸ ", SeparatorStyle: Faint}
+}
+
+// Push adds a segment at the end.
+func (b *Breadcrumb) Push(segment string) {
+	b.Segments = append(b.Segments, segment)
+}
+
+// Pop removes the last segment and returns it, or "" if there is none.
+func (b *Breadcrumb) Pop() string {
+	n := len(b.Segments)
+	if n == 0 {
+		return ""
+	}
+	last := b.Segments[n-1]
+	b.Segments = b.Segments[:n-1]
+	return last
+}
+
+// style returns the style of segment i.
+func (b *Breadcrumb) style(i int) string {
+	if b.Style != nil {
+		return b.Style(i, len(b.Segments), b.Segments[i])
+	}
+	if i == len(b.Segments)-1 {
+		return Bold
+	}
+	return ""
+}
+
+// Line returns the breadcrumb fitted in width columns. Segments after the
+// first are replaced by "…" until it fits; if it still does not, the first
+// goes too and then the last is truncated.
+func (b *Breadcrumb) Line(width int) string {
+	n := len(b.Segments)
+	if n == 0 || width <= 0 {
+		return ""
+	}
+	// shown holds the indexes of the segments shown, with -1 for "…".
+	shown := make([]int, n)
+	for i := range shown {
+		shown[i] = i
+	}
+	sepWidth := visibleWidth(b.Separator)
+	lineWidth := func() int {
+		w := sepWidth * (len(shown) - 1)
+		for _, i := range shown {
+			if i < 0 {
+				w++
+			} else {
+				w += visibleWidth(b.Segments[i])
+			}
+		}
+		return w
+	}
+	for hidden := 1; lineWidth() > width && hidden < n; hidden++ {
+		if hidden < n-1 {
+			// Hide segments 1 to hidden, after the first.
+			shown = append([]int{0, -1}, shown[len(shown)-(n-1-hidden):]...)
+		} else {
+			shown = []int{-1, n - 1}
+		}
+	}
+
+	var sb strings.Builder
+	for j, i := range shown {
+		if j > 0 {
+			sb.WriteString(b.SeparatorStyle + b.Separator + End)
+		}
+		if i < 0 {
+			sb.WriteString(b.SeparatorStyle + "…" + End)
+			continue
+		}
+		text := b.Segments[i]
+		if j == len(shown)-1 {
+			text = truncateWidth(text, max(width-(lineWidth()-visibleWidth(text)), 1))
+		}
+		if style := b.style(i); style != "" {
+			text = style + text + End
+		}
+		sb.WriteString(text)
+	}
+	return truncateWidth(sb.String(), width)
+}
+
+// Lines returns the breadcrumb on a line of width columns, for drawing it
+// in a Pane, GridCell or Tab.
+func (b *Breadcrumb) Lines(width, height int) []string {
+	if height <= 0 {
+		return nil
+	}
+	return []string{b.Line(width)}
+}
+
+// Print prints the breadcrumb to stdout.
+func (b *Breadcrumb) Print() {
+	fmt.Print(b.String())
+}
+
+// String renders the breadcrumb in the width of the terminal, ending with a
+// newline.
+func (b *Breadcrumb) String() string {
+	width, _ := termSize(os.Stdout)
+	return b.Line(width) + "\n"
+}
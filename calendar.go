@@ -0,0 +1,213 @@
+package ansi
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// --------------------
+// Calendar
+// --------------------
+
+// Calendar shows a month, one week per row, with today and the selected day
+// highlighted. The selection is moved with the arrow keys.
+type Calendar struct {
+	Selected time.Time
+	Today    time.Time    // Marked in Cyan. The default of NewCalendar is the current day.
+	FirstDay time.Weekday // Day each week starts on. The default is Sunday.
+	Min, Max time.Time    // Earliest and latest days that can be selected, if not zero.
+	Keys     *KeyMap      // Keys moving the selection. The default is CalendarKeys.
+}
+
+// CalendarKeys returns the default keys of a Calendar, whose actions are
+// "prev-day", "next-day", "prev-week", "next-week", "prev-month",
+// "next-month", "month-start", "month-end" and "today". The short help
+// names each pair of moves once, as in "←→ day".
+func CalendarKeys() *KeyMap {
+	return NewKeyMap(
+		KeyBinding{Name: "prev-day", Keys: []string{"left", "h"}, Label: "←→", Help: "day"},
+		KeyBinding{Name: "next-day", Keys: []string{"right", "l"}, Help: "next day", Extra: true},
+		KeyBinding{Name: "prev-week", Keys: []string{"up", "k"}, Label: "↑↓", Help: "week"},
+		KeyBinding{Name: "next-week", Keys: []string{"down", "j"}, Help: "next week", Extra: true},
+		KeyBinding{Name: "prev-month", Keys: []string{"pageup", "<"}, Label: "pgup/pgdn", Help: "month"},
+		KeyBinding{Name: "next-month", Keys: []string{"pagedown", ">"}, Help: "next month", Extra: true},
+		KeyBinding{Name: "month-start", Keys: []string{"home"}, Help: "start of month", Extra: true},
+		KeyBinding{Name: "month-end", Keys: []string{"end"}, Help: "end of month", Extra: true},
+		KeyBinding{Name: "today", Keys: []string{"t"}, Help: "today"},
+	)
+}
+
+// keyMap returns Keys, set to CalendarKeys if it is nil.
+func (c *Calendar) keyMap() *KeyMap {
+	if c.Keys == nil {
+		c.Keys = CalendarKeys()
+	}
+	return c.Keys
+}
+
+// NewCalendar creates a calendar with selected as the selected day.
+func NewCalendar(selected time.Time) *Calendar {
+	return &Calendar{Selected: dateOf(selected), Today: dateOf(time.Now())}
+}
+
+// dateOf returns the start of the day of t.
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// clamp limits t to the days between Min and Max.
+func (c *Calendar) clamp(t time.Time) time.Time {
+	if !c.Min.IsZero() && t.Before(dateOf(c.Min)) {
+		return dateOf(c.Min)
+	}
+	if !c.Max.IsZero() && t.After(dateOf(c.Max)) {
+		return dateOf(c.Max)
+	}
+	return t
+}
+
+// HandleKey moves the selection with the keys bound in Keys and reports
+// whether the key was used. By default left and right (or h and l) move by
+// a day, up and down (or k and j) by a week, PageUp and PageDown (or < and
+// >) by a month, Home and End to the start and end of the month, and t to
+// today.
+func (c *Calendar) HandleKey(keyType, key string) bool {
+	s := c.Selected
+	switch c.keyMap().Match(keyType, key) {
+	case "prev-day":
+		s = s.AddDate(0, 0, -1)
+	case "next-day":
+		s = s.AddDate(0, 0, 1)
+	case "prev-week":
+		s = s.AddDate(0, 0, -7)
+	case "next-week":
+		s = s.AddDate(0, 0, 7)
+	case "prev-month":
+		s = addMonths(s, -1)
+	case "next-month":
+		s = addMonths(s, 1)
+	case "month-start":
+		s = s.AddDate(0, 0, 1-s.Day())
+	case "month-end":
+		s = addMonths(s.AddDate(0, 0, 1-s.Day()), 1).AddDate(0, 0, -1)
+	case "today":
+		s = c.Today
+	default:
+		return false
+	}
+	c.Selected = c.clamp(s)
+	return true
+}
+
+// addMonths returns t moved by n months, on the last day of the month if it
+// has fewer days than the day of t.
+func addMonths(t time.Time, n int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(n), 1, 0, 0, 0, 0, t.Location())
+	last := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(t.Day(), last)-1)
+}
+
+// Lines returns the month of the selected day: its name, the names of the
+// days of the week and six rows of weeks, 20 columns wide.
+func (c *Calendar) Lines() []string {
+	s := c.Selected
+	first := time.Date(s.Year(), s.Month(), 1, 0, 0, 0, 0, s.Location())
+	lines := []string{
+		centerText(Bold+first.Format("January 2006")+End, 20),
+	}
+	var names []string
+	for i := range 7 {
+		names = append(names, (time.Weekday((int(c.FirstDay) + i) % 7)).String()[:2])
+	}
+	lines = append(lines, Faint+strings.Join(names, " ")+End)
+
+	day := first.AddDate(0, 0, -((int(first.Weekday()) - int(c.FirstDay) + 7) % 7))
+	for range 6 {
+		var week []string
+		for range 7 {
+			text := fmt.Sprintf("%2d", day.Day())
+			switch {
+			case day.Equal(s):
+				text = Negative + text + End
+			case day.Month() != s.Month():
+				text = Faint + text + End
+			case day.Equal(dateOf(c.Today)):
+				text = Cyan + Bold + text + End
+			case c.clamp(day) != day:
+				text = Faint + Crossed + text + End
+			}
+			week = append(week, text)
+			day = day.AddDate(0, 0, 1)
+		}
+		lines = append(lines, strings.Join(week, " "))
+	}
+	return lines
+}
+
+// String renders the month, ending with a newline.
+func (c *Calendar) String() string {
+	return strings.Join(c.Lines(), "\n") + "\n"
+}
+
+// PickDate asks for a date on a calendar: the arrow keys move by days and
+// weeks, PageUp and PageDown by months, t goes to today and Enter chooses.
+// InputDefault sets the day selected first, as "2006-01-02", and
+// InputValidator checks the chosen day in that form. Escape and Ctrl-C
+// cancel with ErrInterrupted. InputPromptStyle, InputErrorStyle, InputWriter
+// and InputKeyReader may also be given in opts.
+func PickDate(prompt string, opts ...InputOption) (time.Time, error) {
+	cfg := &inputConfig{out: os.Stdout, errorStyle: Red}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.keys == nil {
+		cfg.keys = defaultKeyReader()
+	}
+	if cfg.promptStyle != "" {
+		prompt = cfg.promptStyle + prompt + End
+	}
+	cal := NewCalendar(time.Now())
+	if t, err := time.ParseInLocation(time.DateOnly, cfg.defaultText, time.Local); err == nil {
+		cal.Selected = t
+	}
+
+	fmt.Fprint(cfg.out, "\033[?25l")
+	defer fmt.Fprint(cfg.out, "\033[?25h")
+	message := ""
+	for {
+		lines := append([]string{prompt + " " + Cyan + cal.Selected.Format(time.DateOnly) + End}, cal.Lines()...)
+		if message != "" {
+			lines = append(lines, cfg.errorStyle+message+End)
+		} else {
+			width, _ := termSize(cfg.out)
+			lines = append(lines, cal.keyMap().ShortHelp(width))
+		}
+		fmt.Fprintf(cfg.out, "\r\033[J%s\033[%dA", strings.Join(lines, "\r\n"), len(lines)-1)
+
+		keyType, key, err := cfg.keys.readKey()
+		if err != nil {
+			return cal.Selected, err
+		}
+		switch {
+		case keyType == "Special" && key == "enter":
+			if cfg.validate != nil {
+				if err := cfg.validate(cal.Selected.Format(time.DateOnly)); err != nil {
+					message = err.Error()
+					continue
+				}
+			}
+			fmt.Fprintf(cfg.out, "\r\033[J%s %s\n", prompt, Cyan+cal.Selected.Format(time.DateOnly)+End)
+			return cal.Selected, nil
+		case keyType == "Special" && (key == "escape" || key == "ctrl-c"):
+			fmt.Fprint(cfg.out, "\r\033[J"+prompt+"\n")
+			if key == "escape" && cfg.escapeBack {
+				return cal.Selected, errBack
+			}
+			return cal.Selected, ErrInterrupted
+		case cal.HandleKey(keyType, key):
+			message = ""
+		}
+	}
+}
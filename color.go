@@ -0,0 +1,98 @@
+package ansi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// --------------------
+// Color
+// --------------------
+
+// Color is a 24-bit RGB color, for terminals that support true color.
+type Color struct {
+	R, G, B uint8
+}
+
+// RGB returns the color of red, green and blue components.
+func RGB(r, g, b uint8) Color {
+	return Color{r, g, b}
+}
+
+// basicColors are the 16 system colors as xterm shows them by default.
+var basicColors = [16]Color{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0}, {0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0}, {92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// Color256 returns the color of entry n of the 256-color palette as xterm
+// shows it by default: the 16 system colors, a 6×6×6 cube and 24 grays.
+func Color256(n uint8) Color {
+	switch {
+	case n < 16:
+		return basicColors[n]
+	case n < 232:
+		level := func(i uint8) uint8 {
+			if i == 0 {
+				return 0
+			}
+			return 55 + i*40
+		}
+		n -= 16
+		return Color{level(n / 36), level(n / 6 % 6), level(n % 6)}
+	default:
+		gray := 8 + (n-232)*10
+		return Color{gray, gray, gray}
+	}
+}
+
+// ParseHex parses a color written as "#rrggbb" or "#rgb", with or without
+// the "#".
+func ParseHex(s string) (Color, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	n, err := strconv.ParseUint(hex, 16, 32)
+	if len(hex) != 6 || err != nil {
+		return Color{}, fmt.Errorf("%q is not a hex color", s)
+	}
+	return Color{uint8(n >> 16), uint8(n >> 8), uint8(n)}, nil
+}
+
+// Hex returns the color written as "#rrggbb".
+func (c Color) Hex() string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// Fg returns the sequence setting the text color to c.
+func (c Color) Fg() string {
+	return fmt.Sprintf("\033[38;2;%d;%d;%dm", c.R, c.G, c.B)
+}
+
+// Bg returns the sequence setting the background color to c.
+func (c Color) Bg() string {
+	return fmt.Sprintf("\033[48;2;%d;%d;%dm", c.R, c.G, c.B)
+}
+
+// Blend returns the color a fraction t of the way from c to d.
+func (c Color) Blend(d Color, t float64) Color {
+	t = min(max(t, 0), 1)
+	mix := func(a, b uint8) uint8 {
+		return uint8(float64(a) + (float64(b)-float64(a))*t + 0.5)
+	}
+	return Color{mix(c.R, d.R), mix(c.G, d.G), mix(c.B, d.B)}
+}
+
+// luminance returns the relative brightness of c, from 0 to 1.
+func (c Color) luminance() float64 {
+	return (0.299*float64(c.R) + 0.587*float64(c.G) + 0.114*float64(c.B)) / 255
+}
+
+// distance returns how far apart c and d are, as the square of the distance
+// between them in RGB space.
+func (c Color) distance(d Color) int {
+	dr, dg, db := int(c.R)-int(d.R), int(c.G)-int(d.G), int(c.B)-int(d.B)
+	return dr*dr + dg*dg + db*db
+}
@@ -0,0 +1,256 @@
+package ansi
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// --------------------
+// Color Picker
+// --------------------
+
+// Modes of a ColorPicker.
+const (
+	ColorsBasic = iota // The 16 system colors.
+	Colors256          // The 256-color palette.
+	ColorsRGB          // Sliders for red, green and blue.
+)
+
+// ColorPicker picks a Color from the 16 system colors, from the 256-color
+// palette or by setting its red, green and blue with sliders. Colors of the
+// palettes are those xterm shows by default, as returned by Color256.
+type ColorPicker struct {
+	Mode int // ColorsBasic, Colors256 or ColorsRGB.
+
+	index   int // Selected entry of the palette.
+	rgb     [3]*Slider
+	channel int // Selected slider.
+}
+
+// NewColorPicker creates a color picker showing the 256-color palette, with
+// the entry nearest to c selected and the sliders set to c.
+func NewColorPicker(c Color) *ColorPicker {
+	p := &ColorPicker{Mode: Colors256}
+	for i, name := range []string{"R", "G", "B"} {
+		p.rgb[i] = NewSlider(name, 0, 255, 0)
+		p.rgb[i].Style = []string{Red, Green, Blue}[i]
+	}
+	p.SetColor(c)
+	return p
+}
+
+// SetColor sets the sliders to c and selects the palette entry nearest to it.
+func (p *ColorPicker) SetColor(c Color) {
+	p.rgb[0].SetValue(float64(c.R))
+	p.rgb[1].SetValue(float64(c.G))
+	p.rgb[2].SetValue(float64(c.B))
+	n := 256
+	if p.Mode == ColorsBasic {
+		n = 16
+	}
+	p.index = nearestColor(c, n)
+}
+
+// nearestColor returns the entry of the first n of the 256-color palette
+// nearest to c.
+func nearestColor(c Color, n int) int {
+	best := 0
+	for i := range n {
+		if c.distance(Color256(uint8(i))) < c.distance(Color256(uint8(best))) {
+			best = i
+		}
+	}
+	return best
+}
+
+// Color returns the chosen color.
+func (p *ColorPicker) Color() Color {
+	if p.Mode == ColorsRGB {
+		return Color{uint8(p.rgb[0].Value), uint8(p.rgb[1].Value), uint8(p.rgb[2].Value)}
+	}
+	return Color256(uint8(p.index))
+}
+
+// setMode switches to mode, carrying the chosen color over.
+func (p *ColorPicker) setMode(mode int) {
+	c := p.Color()
+	p.Mode = (mode + 3) % 3
+	p.SetColor(c)
+}
+
+// HandleKey changes the color and reports whether the key was used: Tab and
+// Shift-Tab switch modes; in the palettes the arrow keys (or hjkl) move the
+// selection, and in RGB mode up and down choose a slider and the other keys
+// move it as in Slider.HandleKey.
+func (p *ColorPicker) HandleKey(keyType, key string) bool {
+	if keyType == "Special" && key == "tab" {
+		p.setMode(p.Mode + 1)
+		return true
+	}
+	if keyType == "Special" && key == "shift-tab" {
+		p.setMode(p.Mode - 1)
+		return true
+	}
+	if p.Mode == ColorsRGB {
+		switch {
+		case keyType == "Arrow" && key == "up", keyType == "Character" && key == "k":
+			p.channel = max(p.channel-1, 0)
+		case keyType == "Arrow" && key == "down", keyType == "Character" && key == "j":
+			p.channel = min(p.channel+1, 2)
+		default:
+			return p.rgb[p.channel].HandleKey(keyType, key)
+		}
+		return true
+	}
+
+	size, cols := 256, 16
+	if p.Mode == ColorsBasic {
+		size, cols = 16, 8
+	}
+	i := p.index
+	switch {
+	case keyType == "Arrow" && key == "left", keyType == "Character" && key == "h":
+		i--
+	case keyType == "Arrow" && key == "right", keyType == "Character" && key == "l":
+		i++
+	case keyType == "Arrow" && key == "up", keyType == "Character" && key == "k":
+		i -= cols
+	case keyType == "Arrow" && key == "down", keyType == "Character" && key == "j":
+		i += cols
+	case keyType == "Special" && key == "home":
+		i = 0
+	case keyType == "Special" && key == "end":
+		i = size - 1
+	default:
+		return false
+	}
+	if i >= 0 && i < size {
+		p.index = i
+	}
+	return true
+}
+
+// Lines returns the picker: the modes, a preview of the color with its hex
+// value, and the palette or sliders, cut to width columns and height rows.
+// It is 32 columns wide.
+func (p *ColorPicker) Lines(width, height int) []string {
+	var modes []string
+	for i, name := range []string{"16", "256", "RGB"} {
+		if i == p.Mode {
+			modes = append(modes, Negative+" "+name+" "+End)
+		} else {
+			modes = append(modes, Faint+" "+name+" "+End)
+		}
+	}
+	c := p.Color()
+	preview := c.Bg() + "      " + End + " " + c.Hex()
+	if p.Mode != ColorsRGB {
+		preview += Faint + fmt.Sprintf(" (%d)", p.index) + End
+	}
+	lines := []string{strings.Join(modes, " "), preview}
+
+	switch p.Mode {
+	case ColorsRGB:
+		for i, s := range p.rgb {
+			line, _ := s.line(30)
+			mark := "  "
+			if i == p.channel {
+				mark = Bold + "› " + End
+			}
+			lines = append(lines, mark+line)
+		}
+	default:
+		size, cols, cell := 256, 16, 2
+		if p.Mode == ColorsBasic {
+			size, cols, cell = 16, 8, 4
+		}
+		for row := range size / cols {
+			var sb strings.Builder
+			for col := range cols {
+				i := row*cols + col
+				swatch := Color256(uint8(i))
+				text := strings.Repeat(" ", cell)
+				if i == p.index {
+					// Mark the selection in black or white, whichever stands out more.
+					fg := RGB(255, 255, 255)
+					if swatch.luminance() > 0.5 {
+						fg = RGB(0, 0, 0)
+					}
+					text = fg.Fg() + centerText("[]", cell)
+				}
+				sb.WriteString(swatch.Bg() + text + End)
+			}
+			lines = append(lines, sb.String())
+		}
+	}
+	lines = lines[:min(len(lines), max(height, 0))]
+	for i, line := range lines {
+		lines[i] = truncateWidth(line, width)
+	}
+	return lines
+}
+
+// PickColor asks for a color from the 16 system colors, the 256-color
+// palette or red, green and blue sliders, switched between with Tab, and
+// returns it as chosen with Enter. InputDefault sets the color selected
+// first on the sliders, as "#rrggbb", and InputValidator checks the chosen
+// color in that form. Escape and Ctrl-C cancel with ErrInterrupted.
+// InputPromptStyle, InputErrorStyle, InputWriter and InputKeyReader may also
+// be given in opts.
+func PickColor(prompt string, opts ...InputOption) (Color, error) {
+	cfg := &inputConfig{out: os.Stdout, errorStyle: Red}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.keys == nil {
+		cfg.keys = defaultKeyReader()
+	}
+	if cfg.promptStyle != "" {
+		prompt = cfg.promptStyle + prompt + End
+	}
+	p := NewColorPicker(Color{})
+	if c, err := ParseHex(cfg.defaultText); err == nil {
+		// The sliders keep the color exactly, where a palette may not have it.
+		p.Mode = ColorsRGB
+		p.SetColor(c)
+	}
+
+	fmt.Fprint(cfg.out, "\033[?25l")
+	defer fmt.Fprint(cfg.out, "\033[?25h")
+	message := ""
+	for {
+		lines := append([]string{prompt}, p.Lines(80, 20)...)
+		if message != "" {
+			lines = append(lines, cfg.errorStyle+message+End)
+		} else {
+			lines = append(lines, Faint+"tab mode · arrows move · enter ok"+End)
+		}
+		fmt.Fprintf(cfg.out, "\r\033[J%s\033[%dA", strings.Join(lines, "\r\n"), len(lines)-1)
+
+		keyType, key, err := cfg.keys.readKey()
+		if err != nil {
+			return p.Color(), err
+		}
+		switch {
+		case keyType == "Special" && key == "enter":
+			c := p.Color()
+			if cfg.validate != nil {
+				if err := cfg.validate(c.Hex()); err != nil {
+					message = err.Error()
+					continue
+				}
+			}
+			fmt.Fprintf(cfg.out, "\r\033[J%s %s\n", prompt, c.Bg()+"  "+End+" "+Cyan+c.Hex()+End)
+			return c, nil
+		case keyType == "Special" && (key == "escape" || key == "ctrl-c"):
+			fmt.Fprint(cfg.out, "\r\033[J"+prompt+"\n")
+			if key == "escape" && cfg.escapeBack {
+				return p.Color(), errBack
+			}
+			return p.Color(), ErrInterrupted
+		case p.HandleKey(keyType, key):
+			message = ""
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package ansi
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// --------------------
+// Completion
+// --------------------
+
+// Suggestion is a completion offered by the Input prompt.
+type Suggestion struct {
+	Text        string // Replaces the word at the cursor when accepted.
+	Description string // Optional note about the suggestion.
+}
+
+// Completer computes suggestions for the word at rune position pos of line,
+// best first. It is called as the user types, so it can offer completions
+// from live data such as flags, file paths or API results.
+type Completer func(line string, pos int) []Suggestion
+
+// InputCompleter sets a function computing the autocomplete suggestions,
+// used instead of the list given to InputCompletions.
+func InputCompleter(completer Completer) InputOption {
+	return func(c *inputConfig) {
+		c.completer = completer
+	}
+}
+
+// PathCompleter is a Completer for file system paths. It lists the entries of
+// the directory named by the word before the cursor that start with the rest
+// of the word, expanding a leading "~" and adding a trailing "/" to
+// directories. Hidden entries are only offered once a "." has been typed, and
+// nothing is offered before the first character of the word.
+func PathCompleter(line string, pos int) []Suggestion {
+	rs := []rune(line)
+	word := string(rs[wordBefore(rs, pos):pos])
+	if word == "" {
+		return nil
+	}
+	dir, base := "", word
+	if i := strings.LastIndex(word, "/"); i >= 0 {
+		dir, base = word[:i+1], word[i+1:]
+	} else if word == "~" {
+		dir, base = "~/", ""
+	}
+	readDir := expandHome(dir)
+	if readDir == "" {
+		readDir = "."
+	}
+	entries, err := os.ReadDir(readDir)
+	if err != nil {
+		return nil
+	}
+	var suggestions []Suggestion
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, base) || (strings.HasPrefix(name, ".") && !strings.HasPrefix(base, ".")) {
+			continue
+		}
+		if entry.IsDir() {
+			name += "/"
+		} else if entry.Type()&os.ModeSymlink != 0 {
+			if info, err := os.Stat(filepath.Join(readDir, name)); err == nil && info.IsDir() {
+				name += "/"
+			}
+		}
+		suggestions = append(suggestions, Suggestion{Text: dir + name})
+	}
+	return suggestions
+}
+
+// findMatches returns the completions that start with input.
+func findMatches(input string, completions []string) []string {
+	if input == "" {
+		return nil
+	}
+	var matches []string
+	for _, word := range completions {
+		if strings.HasPrefix(word, input) {
+			matches = append(matches, word)
+		}
+	}
+	return matches
+}
+
+// wordCompleter returns a Completer offering the words that match the word
+// before the cursor, by prefix or, if fuzzy is set, by fuzzy score.
+func wordCompleter(words []string, fuzzy bool) Completer {
+	return func(line string, pos int) []Suggestion {
+		rs := []rune(line)
+		word := string(rs[wordBefore(rs, pos):pos])
+		var matches []string
+		if !fuzzy {
+			matches = findMatches(word, words)
+		} else if word != "" {
+			matches = fuzzyRank(word, words)
+		}
+		suggestions := make([]Suggestion, len(matches))
+		for i, match := range matches {
+			suggestions[i] = Suggestion{Text: match}
+		}
+		return suggestions
+	}
+}
+
+// wordBefore returns the index where the word ending at pos begins.
+func wordBefore(rs []rune, pos int) int {
+	for pos > 0 && rs[pos-1] != ' ' {
+		pos--
+	}
+	return pos
+}
+
+// currentWord returns the part of the word at the cursor that precedes it.
+func (st *inputState) currentWord() []rune {
+	return st.text[wordBefore(st.text, st.cursor):st.cursor]
+}
+
+// autocomplete returns the Autocomplete of the prompt, creating it from the
+// completer or completions of its settings on first use.
+func (st *inputState) autocomplete() *Autocomplete {
+	if st.complete == nil {
+		completer := st.cfg.completer
+		if completer == nil && len(st.cfg.completions) > 0 {
+			completer = wordCompleter(st.cfg.completions, st.cfg.fuzzy)
+		}
+		st.complete = NewAutocomplete(completer)
+		st.complete.Max = st.cfg.dropdown
+	}
+	return st.complete
+}
+
+// suggestions returns the completions for the word at the cursor, or none for
+// a masked prompt.
+func (st *inputState) suggestions() []Suggestion {
+	if st.cfg.masked {
+		return nil
+	}
+	ac := st.autocomplete()
+	ac.Update(string(st.text), st.cursor)
+	return ac.Suggestions()
+}
+
+// handleTabKey accepts the shown suggestion on Tab, and reports whether key
+// was Tab or Shift-Tab. Pressing either again replaces the accepted text with
+// the next or previous alternative, like shell completion.
+func (st *inputState) handleTabKey(keyType, key string) bool {
+	if keyType != "Special" || (key != "tab" && key != "shift-tab") {
+		st.cycle = nil
+		return false
+	}
+	if st.cycle == nil {
+		suggestions := st.suggestions()
+		if len(suggestions) == 0 {
+			return true
+		}
+		st.cycle = suggestions
+		st.cycleStart = wordBefore(st.text, st.cursor)
+		st.cycleIndex = 0
+		if key == "shift-tab" {
+			st.cycleIndex = len(suggestions) - 1
+		}
+	} else if key == "tab" {
+		st.cycleIndex = (st.cycleIndex + 1) % len(st.cycle)
+	} else {
+		st.cycleIndex = (st.cycleIndex + len(st.cycle) - 1) % len(st.cycle)
+	}
+	st.deleteRange(st.cycleStart, st.cursor)
+	st.insert([]rune(st.cycle[st.cycleIndex].Text))
+	return true
+}
+
+// InputDropdown shows up to n suggestions in a list below the prompt, in
+// addition to the inline suggestion. Tab and the arrow keys move through the
+// list, Enter accepts the selected entry and Escape closes the list.
+func InputDropdown(n int) InputOption {
+	return func(c *inputConfig) {
+		c.dropdown = n
+	}
+}
+
+// accept replaces the word before the cursor with the suggestion.
+func (st *inputState) accept(s Suggestion) {
+	start := wordBefore(st.text, st.cursor)
+	st.deleteRange(start, st.cursor)
+	st.insert([]rune(s.Text))
+}
+
+// handleMenuKey handles the keys navigating the dropdown and reports whether
+// key was one of them.
+func (st *inputState) handleMenuKey(keyType, key string) bool {
+	ac := st.autocomplete()
+	ac.Update(string(st.text), st.cursor)
+	if !ac.HandleKey(keyType, key) {
+		return false
+	}
+	if s, ok := ac.Accepted(); ok {
+		st.accept(s)
+	}
+	return true
+}
+
+// dropdownLines renders the dropdown, aligned below the word being completed.
+func (st *inputState) dropdownLines() []string {
+	col := st.textColumn(wordBefore(st.text, st.cursor))
+	width, _ := termSize(st.cfg.out)
+	lines := st.autocomplete().Lines(max(width-col, 1), st.cfg.dropdown)
+	for i, line := range lines {
+		lines[i] = strings.Repeat(" ", col) + line
+	}
+	return lines
+}
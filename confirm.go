@@ -0,0 +1,70 @@
+package ansi
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// --------------------
+// Confirm
+// --------------------
+
+// ErrInterrupted is returned by prompts cancelled with Ctrl-C or Escape.
+var ErrInterrupted = errors.New("ansi: prompt interrupted")
+
+// Confirm asks a yes/no question answered with a single key press: y or n,
+// or Enter for the default, which is shown capitalized. Escape and Ctrl-C
+// cancel with ErrInterrupted. InputPromptStyle, InputWriter and
+// InputKeyReader may be given in opts.
+func Confirm(prompt string, def bool, opts ...InputOption) (bool, error) {
+	cfg := &inputConfig{out: os.Stdout}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.keys == nil {
+		cfg.keys = defaultKeyReader()
+	}
+
+	choices := "y/" + Bold + "N" + End
+	if def {
+		choices = Bold + "Y" + End + "/n"
+	}
+	if cfg.promptStyle != "" {
+		prompt = cfg.promptStyle + prompt + End
+	}
+	fmt.Fprintf(cfg.out, "\r\033[2K%s [%s] ", prompt, choices)
+
+	answer := def
+	for {
+		keyType, key, err := cfg.keys.readKey()
+		if err != nil {
+			fmt.Fprintln(cfg.out)
+			return def, err
+		}
+		if keyType == "Special" && (key == "escape" || key == "ctrl-c") {
+			fmt.Fprintln(cfg.out)
+			if key == "escape" && cfg.escapeBack {
+				return def, errBack
+			}
+			return def, ErrInterrupted
+		}
+		if keyType == "Special" && key == "enter" {
+			break
+		}
+		if keyType == "Character" && (key == "y" || key == "Y") {
+			answer = true
+			break
+		}
+		if keyType == "Character" && (key == "n" || key == "N") {
+			answer = false
+			break
+		}
+	}
+	if answer {
+		fmt.Fprintln(cfg.out, Green+"Yes"+End)
+	} else {
+		fmt.Fprintln(cfg.out, Red+"No"+End)
+	}
+	return answer, nil
+}
@@ -0,0 +1,136 @@
+package ansi
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// --------------------
+// Details
+// --------------------
+
+// detail is a key and value of Details.
+type detail struct {
+	key, value string
+	style      string // Style of the value, or "" for the default.
+	secret     bool
+}
+
+// Details shows aligned keys and values, as on the "describe" screen of a
+// CLI. Long values wrap under the start of the value, secret values are
+// masked, and empty values show as "<none>".
+type Details struct {
+	KeyStyle    string // The default is Bold.
+	Separator   string // After each key. The default is ":".
+	Mask        string // Shown for secret values. The default is "••••••••".
+	MaxKeyWidth int    // Keys wider are truncated. If 0, a third of the width.
+	Reveal      bool   // Show secret values rather than the mask.
+
+	entries []detail
+}
+
+// NewDetails creates empty details.
+func NewDetails() *Details {
+	return &Details{KeyStyle: Bold, Separator: ":", Mask: "••••••••"}
+}
+
+// Add adds a key and its value at the end.
+func (d *Details) Add(key, value string) {
+	d.entries = append(d.entries, detail{key: key, value: value})
+}
+
+// AddStyled adds a key and its value shown in style, like Green for a
+// status that is healthy.
+func (d *Details) AddStyled(key, value, style string) {
+	d.entries = append(d.entries, detail{key: key, value: value, style: style})
+}
+
+// AddSecret adds a key and a value that is shown as the mask unless Reveal
+// is set. The mask does not tell the length of the value.
+func (d *Details) AddSecret(key, value string) {
+	d.entries = append(d.entries, detail{key: key, value: value, secret: true})
+}
+
+// Set changes the value of key, adding it if it is not there.
+func (d *Details) Set(key, value string) {
+	for i := range d.entries {
+		if d.entries[i].key == key {
+			d.entries[i].value = value
+			return
+		}
+	}
+	d.Add(key, value)
+}
+
+// keyWidth returns the width of the key column for width columns, the
+// separator included.
+func (d *Details) keyWidth(width int) int {
+	limit := d.MaxKeyWidth
+	if limit <= 0 {
+		limit = max(width/3, 1)
+	}
+	w := 0
+	for _, e := range d.entries {
+		w = max(w, min(visibleWidth(e.key), limit))
+	}
+	return w + visibleWidth(d.Separator)
+}
+
+// Lines returns the details fitted in width columns and at most height
+// rows, for drawing them in a Pane, GridCell or Tab.
+func (d *Details) Lines(width, height int) []string {
+	if width <= 0 || height <= 0 {
+		return nil
+	}
+	keyWidth := d.keyWidth(width)
+	valueWidth := max(width-keyWidth-1, 1)
+	indent := strings.Repeat(" ", keyWidth+1)
+
+	var lines []string
+	for _, e := range d.entries {
+		key := truncateWidth(e.key, keyWidth-visibleWidth(d.Separator)) + d.Separator
+		if d.KeyStyle != "" {
+			key = d.KeyStyle + key + End
+		}
+		key = padWidth(key, keyWidth) + " "
+
+		value, style := e.value, e.style
+		switch {
+		case e.secret && !d.Reveal:
+			value, style = d.Mask, Faint
+		case value == "":
+			value, style = "<none>", Faint
+		}
+		for i, row := range wrapText(value, valueWidth) {
+			if style != "" {
+				row = style + row + End
+			}
+			if i == 0 {
+				lines = append(lines, key+row)
+			} else {
+				lines = append(lines, indent+row)
+			}
+		}
+		if len(lines) >= height {
+			return lines[:height]
+		}
+	}
+	return lines
+}
+
+// Print prints the details to stdout.
+func (d *Details) Print() {
+	fmt.Print(d.String())
+}
+
+// String renders the details in the width of the terminal, ending with a
+// newline.
+func (d *Details) String() string {
+	if len(d.entries) == 0 {
+		return ""
+	}
+	width, _ := termSize(os.Stdout)
+	lines := d.Lines(width, 1<<30)
+	return strings.Join(lines, "\n") + "\n"
+}
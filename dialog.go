@@ -0,0 +1,371 @@
+package ansi
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// --------------------
+// Dialog
+// --------------------
+
+// Kinds of Dialog.
+const (
+	DialogMessage = "message"
+	DialogConfirm = "confirm"
+	DialogInput   = "input"
+)
+
+// Dialog is a box drawn in the middle of the screen over whatever is there:
+// a message with an OK button, a yes/no question or a line of input. It can
+// be shown on its own with Run or inside a full-screen view by sending it
+// every key while it is open and drawing it last.
+type Dialog struct {
+	Kind    string // DialogMessage (the default), DialogConfirm or DialogInput.
+	Title   string
+	Message string
+	Width   int    // Width of the box. The default fits the message, up to 60 columns.
+	Border  Border // BorderRounded if not set with NewDialog.
+	Style   string // Style of the border and title. The default is Cyan.
+
+	cfg       *inputConfig
+	st        *inputState // Text of an input dialog.
+	yes       bool        // Focused button of a confirm dialog.
+	err       string      // Validation error of an input dialog.
+	closed    bool
+	canceled  bool
+	cursorRow int // Position of the text cursor of an input dialog in the box.
+	cursorCol int
+}
+
+// NewDialog creates a dialog of kind.
+func NewDialog(kind, title, message string) *Dialog {
+	d := &Dialog{Kind: kind, Title: title, Message: message, Border: BorderRounded, Style: Cyan}
+	d.setup()
+	return d
+}
+
+// setup configures a dialog not configured yet, such as a Dialog literal,
+// with the defaults.
+func (d *Dialog) setup() {
+	if d.cfg == nil {
+		d.configure(&inputConfig{errorStyle: Red})
+	}
+}
+
+// configure sets up the state of the dialog from cfg, whose InputDefault,
+// InputPlaceholder, InputValidator, InputMask and InputErrorStyle are used
+// by input dialogs. InputDefault of "y" or "yes" focuses Yes in a confirm
+// dialog.
+func (d *Dialog) configure(cfg *inputConfig) {
+	d.cfg = cfg
+	d.st = &inputState{cfg: cfg, histPos: -1}
+	d.st.setText([]rune(cfg.defaultText))
+	d.yes = strings.EqualFold(cfg.defaultText, "y") || strings.EqualFold(cfg.defaultText, "yes")
+	d.closed, d.canceled, d.err = false, false, ""
+}
+
+// Open reports whether the dialog has not been closed yet.
+func (d *Dialog) Open() bool {
+	return !d.closed
+}
+
+// Canceled reports whether the dialog was closed with Escape or Ctrl-C.
+func (d *Dialog) Canceled() bool {
+	return d.canceled
+}
+
+// Answer returns the answer of a confirm dialog: true if it was closed on Yes.
+func (d *Dialog) Answer() bool {
+	return d.yes && !d.canceled
+}
+
+// Value returns the text of an input dialog.
+func (d *Dialog) Value() string {
+	d.setup()
+	return string(d.st.text)
+}
+
+// HandleKey handles a key while the dialog is open and reports whether it
+// closed it. Enter accepts, unless the validator refuses the text of an
+// input dialog, and Escape and Ctrl-C cancel. A confirm dialog also answers
+// with y or n, and left, right and Tab move between its buttons; an input
+// dialog edits its text with the usual keys.
+func (d *Dialog) HandleKey(keyType, key string) bool {
+	if d.closed {
+		return false
+	}
+	d.setup()
+	switch {
+	case keyType == "Special" && (key == "escape" || key == "ctrl-c"):
+		d.closed, d.canceled = true, true
+	case keyType == "Special" && key == "enter":
+		if d.Kind == DialogInput && d.cfg.validate != nil {
+			if err := d.cfg.validate(d.Value()); err != nil {
+				d.err = err.Error()
+				return false
+			}
+		}
+		d.closed = true
+	case d.Kind == DialogConfirm:
+		switch {
+		case keyType == "Character" && (key == "y" || key == "Y"):
+			d.yes, d.closed = true, true
+		case keyType == "Character" && (key == "n" || key == "N"):
+			d.yes, d.closed = false, true
+		case keyType == "Arrow" && (key == "left" || key == "right"), keyType == "Special" && (key == "tab" || key == "shift-tab"):
+			d.yes = !d.yes
+		}
+	case d.Kind == DialogInput:
+		d.st.handleEditKey(keyType, key)
+		d.err = ""
+	}
+	return d.closed
+}
+
+// Rect returns where the dialog is drawn in screen.
+func (d *Dialog) Rect(screen Rect) Rect {
+	width := d.Width
+	if width <= 0 {
+		width = max(visibleWidth(d.Title)+6, 30)
+		for _, line := range strings.Split(d.Message, "\n") {
+			width = max(width, visibleWidth(line)+4)
+		}
+		width = min(width, 60)
+	}
+	width = max(min(width, screen.Width-2), 6)
+	height := len(d.lines(width-4)) + 2
+	return Rect{
+		Row:    screen.Row + max((screen.Height-height)/2, 0),
+		Col:    screen.Col + max((screen.Width-width)/2, 0),
+		Width:  width,
+		Height: height,
+	}
+}
+
+// lines returns the rows inside the border, width columns wide, and sets the
+// position of the text cursor within them.
+func (d *Dialog) lines(width int) []string {
+	d.setup()
+	var lines []string
+	for _, line := range strings.Split(d.Message, "\n") {
+		lines = append(lines, wrapText(line, width)...)
+	}
+	lines = append(lines, "")
+	switch d.Kind {
+	case DialogInput:
+		text := []rune(string(d.st.text))
+		cursor := d.st.cursor
+		if d.cfg.masked {
+			text, cursor = []rune(d.st.maskedText()), min(cursor, len([]rune(d.st.maskedText())))
+		}
+		field := ""
+		switch {
+		case len(text) == 0 && d.cfg.placeholder != "":
+			field = Faint + truncateWidth(d.cfg.placeholder, width) + End
+		default:
+			start := 0
+			for runesWidth(text[start:cursor]) > width-1 {
+				start++
+			}
+			field = truncateWidth(string(text[start:]), width)
+			cursor = runesWidth(text[start:cursor])
+		}
+		d.cursorRow, d.cursorCol = len(lines), cursor
+		lines = append(lines, Underline+field+strings.Repeat(" ", max(width-visibleWidth(field), 0))+End)
+		if d.err != "" {
+			lines = append(lines, d.cfg.errorStyle+truncateWidth(d.err, width)+End)
+		}
+		lines = append(lines, Faint+truncateWidth("enter ok · esc cancel", width)+End)
+	case DialogConfirm:
+		yes, no := " Yes ", " No "
+		if d.yes {
+			yes = Negative + yes + End
+		} else {
+			no = Negative + no + End
+		}
+		lines = append(lines, centerText(yes+"   "+no, width))
+	default:
+		lines = append(lines, centerText(Negative+" OK "+End, width))
+	}
+	return lines
+}
+
+// centerText pads s on the left to center it in width columns.
+func centerText(s string, width int) string {
+	return strings.Repeat(" ", max((width-visibleWidth(s))/2, 0)) + s
+}
+
+// Render returns the escape sequences drawing the dialog in the middle of
+// screen. For an input dialog they end with the cursor in its text.
+func (d *Dialog) Render(screen Rect) string {
+	r := d.Rect(screen)
+	s := renderBox(r, d.Border, d.Style, d.Title, d.lines(r.Width-4))
+	if d.Kind == DialogInput {
+		s += fmt.Sprintf("\033[%d;%dH", r.Row+1+d.cursorRow, r.Col+2+d.cursorCol)
+	}
+	return s
+}
+
+// renderBox returns the escape sequences drawing a box of border in style
+// filling r, with title in its top line and lines inside, one column from
+// the border.
+func renderBox(r Rect, b Border, style, title string, lines []string) string {
+	inner := r.Width - 4
+	var sb strings.Builder
+	if title != "" {
+		title = " " + Bold + truncateWidth(title, max(inner-2, 0)) + End + style + " "
+	}
+	top := b.TopLeft + b.Horizontal + title
+	top += strings.Repeat(b.Horizontal, max(r.Width-1-visibleWidth(top), 0)) + b.TopRight
+	sb.WriteString(fmt.Sprintf("\033[%d;%dH%s%s%s", r.Row, r.Col, style, top, End))
+	for i := 0; i < r.Height-2; i++ {
+		line := ""
+		if i < len(lines) {
+			line = truncateWidth(lines[i], inner)
+		}
+		line += strings.Repeat(" ", max(inner-visibleWidth(line), 0))
+		sb.WriteString(fmt.Sprintf("\033[%d;%dH%s%s%s %s %s%s%s", r.Row+1+i, r.Col, style, b.Vertical, End, line, style, b.Vertical, End))
+	}
+	bottom := b.BottomLeft + strings.Repeat(b.Horizontal, max(r.Width-2, 0)) + b.BottomRight
+	sb.WriteString(fmt.Sprintf("\033[%d;%dH%s%s%s", r.Row+r.Height-1, r.Col, style, bottom, End))
+	return sb.String()
+}
+
+// InputBackdrop sets a function returning the escape sequences that redraw
+// the screen below a dialog shown with Run, Alert, ConfirmDialog or
+// InputDialog, used to restore it when the dialog closes. Without it the
+// area of the dialog is cleared.
+func InputBackdrop(redraw func() string) InputOption {
+	return func(c *inputConfig) {
+		c.backdrop = redraw
+	}
+}
+
+// Run shows the dialog over the screen until it is closed, then restores the
+// screen and the cursor. Ctrl-C returns ErrInterrupted; Escape closes the
+// dialog as canceled with a nil error. InputDefault, InputPlaceholder,
+// InputValidator, InputMask, InputErrorStyle, InputBackdrop, InputWriter and
+// InputKeyReader may be given in opts.
+func (d *Dialog) Run(opts ...InputOption) error {
+	cfg := &inputConfig{out: os.Stdout, errorStyle: Red}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.keys == nil {
+		cfg.keys = defaultKeyReader()
+	}
+	d.configure(cfg)
+	if cfg.masked {
+		defer d.st.wipe()
+	}
+
+	cursor := "\033[?25l"
+	if d.Kind == DialogInput {
+		cursor = "\033[?25h"
+	}
+	var area Rect // Everything the dialog has covered.
+	fmt.Fprint(cfg.out, "\0337")
+	defer fmt.Fprint(cfg.out, "\0338\033[?25h")
+	defer func() {
+		fmt.Fprint(cfg.out, d.clear(area))
+	}()
+
+	var prev Rect
+	for {
+		width, height := termSize(cfg.out)
+		screen := Rect{1, 1, width, height}
+		s := ""
+		if r := d.Rect(screen); r != prev {
+			// The dialog moved or changed size, as when an error went away.
+			s = d.clear(prev)
+			prev = r
+			area = union(area, r)
+		}
+		fmt.Fprint(cfg.out, "\033[?25l"+s+d.Render(screen)+cursor)
+
+		read := cfg.keys.readKey
+		if d.Kind == DialogInput {
+			read = cfg.keys.readText
+		}
+		keyType, key, err := read()
+		if err != nil {
+			return err
+		}
+		if d.HandleKey(keyType, key) {
+			if key == "ctrl-c" {
+				return ErrInterrupted
+			}
+			return nil
+		}
+	}
+}
+
+// clear returns the escape sequences blanking r and redrawing the backdrop.
+func (d *Dialog) clear(r Rect) string {
+	var sb strings.Builder
+	for row := 0; row < r.Height; row++ {
+		sb.WriteString(fmt.Sprintf("\033[%d;%dH%s", r.Row+row, r.Col, strings.Repeat(" ", r.Width)))
+	}
+	if d.cfg.backdrop != nil {
+		sb.WriteString(d.cfg.backdrop())
+	}
+	return sb.String()
+}
+
+// union returns the smallest rectangle holding a and b, ignoring empty ones.
+func union(a, b Rect) Rect {
+	if a.Width <= 0 || a.Height <= 0 {
+		return b
+	}
+	if b.Width <= 0 || b.Height <= 0 {
+		return a
+	}
+	row, col := min(a.Row, b.Row), min(a.Col, b.Col)
+	return Rect{
+		Row:    row,
+		Col:    col,
+		Width:  max(a.Col+a.Width, b.Col+b.Width) - col,
+		Height: max(a.Row+a.Height, b.Row+b.Height) - row,
+	}
+}
+
+// Alert shows a message in a dialog until it is dismissed with Enter or
+// Escape. Ctrl-C returns ErrInterrupted. InputBackdrop, InputWriter and
+// InputKeyReader may be given in opts.
+func Alert(title, message string, opts ...InputOption) error {
+	return NewDialog(DialogMessage, title, message).Run(opts...)
+}
+
+// ConfirmDialog asks a yes/no question in a dialog, with Yes focused if def
+// is set. Escape and Ctrl-C cancel with ErrInterrupted. InputBackdrop,
+// InputWriter and InputKeyReader may be given in opts.
+func ConfirmDialog(title, message string, def bool, opts ...InputOption) (bool, error) {
+	d := NewDialog(DialogConfirm, title, message)
+	if def {
+		opts = append([]InputOption{InputDefault("yes")}, opts...)
+	}
+	if err := d.Run(opts...); err != nil {
+		return def, err
+	}
+	if d.Canceled() {
+		return def, ErrInterrupted
+	}
+	return d.Answer(), nil
+}
+
+// InputDialog reads a line of text in a dialog. Escape and Ctrl-C cancel
+// with ErrInterrupted. InputDefault, InputPlaceholder, InputValidator,
+// InputMask, InputErrorStyle, InputBackdrop, InputWriter and InputKeyReader
+// may be given in opts.
+func InputDialog(title, message string, opts ...InputOption) (string, error) {
+	d := NewDialog(DialogInput, title, message)
+	if err := d.Run(opts...); err != nil {
+		return "", err
+	}
+	if d.Canceled() {
+		return "", ErrInterrupted
+	}
+	return d.Value(), nil
+}
@@ -0,0 +1,22 @@
+package ansi
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDialogLiteral(t *testing.T) {
+	d := &Dialog{Kind: DialogInput, Title: "Name", Message: "Who are you?"}
+	if s := d.Render(Rect{1, 1, 80, 24}); !strings.Contains(s, "Who are you?") {
+		t.Errorf("Render() = %q, want the message", s)
+	}
+	for _, key := range []string{"b", "o", "b"} {
+		d.HandleKey("Character", key)
+	}
+	if !d.HandleKey("Special", "enter") || d.Value() != "bob" {
+		t.Errorf("Value() = %q after enter, want closed with %q", d.Value(), "bob")
+	}
+	if got := (&Dialog{Kind: DialogInput}).Value(); got != "" {
+		t.Errorf("Value() of a new literal = %q, want empty", got)
+	}
+}
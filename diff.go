@@ -0,0 +1,425 @@
+package ansi
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// --------------------
+// Diff Viewer
+// --------------------
+
+// diffPair is a step of an edit script: a line of both texts when neither
+// index is -1, a line only of the old text when b is -1, and a line only of
+// the new text when a is -1.
+type diffPair struct {
+	a, b int
+}
+
+// maxDiffEdits is the most edits diffStrings looks for. Texts further apart
+// are shown as all of one replaced by all of the other, which keeps the
+// time and memory of the search bounded.
+const maxDiffEdits = 2000
+
+// diffStrings returns the shortest edit script turning a into b, found with
+// Myers' algorithm, or one replacing all lines between the common first and
+// last lines if that takes more than maxDiffEdits edits.
+func diffStrings(a, b []string) []diffPair {
+	n, m := len(a), len(b)
+	off := n + m
+	v := make([]int, 2*off+2)
+	// v[off-d : off+d+1] as it was before each step d, the part of it that
+	// step reads.
+	var trace [][]int
+search:
+	for d := 0; d <= off; d++ {
+		if d > maxDiffEdits {
+			return diffReplace(a, b)
+		}
+		trace = append(trace, append([]int(nil), v[off-d:off+d+1]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || k != d && v[off+k-1] < v[off+k+1] {
+				x = v[off+k+1]
+			} else {
+				x = v[off+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+			v[off+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	var pairs []diffPair
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d] // v[i] is the x reached on diagonal i-d.
+		k := x - y
+		prev := k - 1
+		if k == -d || k != d && v[d+k-1] < v[d+k+1] {
+			prev = k + 1
+		}
+		prevX := 0
+		if d > 0 {
+			prevX = v[d+prev]
+		}
+		prevY := prevX - prev
+		for x > prevX && y > prevY {
+			x, y = x-1, y-1
+			pairs = append(pairs, diffPair{x, y})
+		}
+		if d == 0 {
+			break
+		}
+		if x == prevX {
+			y--
+			pairs = append(pairs, diffPair{-1, y})
+		} else {
+			x--
+			pairs = append(pairs, diffPair{x, -1})
+		}
+	}
+	for i, j := 0, len(pairs)-1; i < j; i, j = i+1, j-1 {
+		pairs[i], pairs[j] = pairs[j], pairs[i]
+	}
+	return pairs
+}
+
+// diffReplace returns the edit script keeping the first and last lines a
+// and b have in common and replacing all lines between them.
+func diffReplace(a, b []string) []diffPair {
+	head := 0
+	for head < len(a) && head < len(b) && a[head] == b[head] {
+		head++
+	}
+	tail := 0
+	for tail < len(a)-head && tail < len(b)-head && a[len(a)-1-tail] == b[len(b)-1-tail] {
+		tail++
+	}
+	var pairs []diffPair
+	for i := range head {
+		pairs = append(pairs, diffPair{i, i})
+	}
+	for i := head; i < len(a)-tail; i++ {
+		pairs = append(pairs, diffPair{i, -1})
+	}
+	for j := head; j < len(b)-tail; j++ {
+		pairs = append(pairs, diffPair{-1, j})
+	}
+	for i := range tail {
+		pairs = append(pairs, diffPair{len(a) - tail + i, len(b) - tail + i})
+	}
+	return pairs
+}
+
+// diffTokens splits s into words, runs of spaces and single other
+// characters, the units that intra-line changes are shown in.
+func diffTokens(s string) []string {
+	class := func(r rune) int {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			return 1
+		case unicode.IsSpace(r):
+			return 2
+		}
+		return 0
+	}
+	var tokens []string
+	start, last := 0, -1
+	for i, r := range s {
+		c := class(r)
+		if i > 0 && (c == 0 || c != last) {
+			tokens = append(tokens, s[start:i])
+			start = i
+		}
+		last = c
+	}
+	if start < len(s) {
+		tokens = append(tokens, s[start:])
+	}
+	return tokens
+}
+
+// diffRow is a row of a DiffView: a line of the old text on the left and of
+// the new text on the right, either of which may be missing.
+type diffRow struct {
+	left, right int  // Line indexes, or -1 for none.
+	changed     bool // Whether the row is part of a hunk.
+}
+
+// DiffView shows an old and a new text side by side, with line numbers,
+// removed lines in red on the left, added lines in green on the right and,
+// where a line was changed, the words that changed highlighted. It is not
+// safe for use from several goroutines.
+type DiffView struct {
+	OldTitle, NewTitle string // Shown above each side if either is set.
+	RemovedStyle       string // The default is Red.
+	AddedStyle         string // The default is Green.
+
+	old, new []string
+	rows     []diffRow
+	hunks    []int // First row of each hunk.
+	hunk     int   // Current hunk, or -1 before the first.
+	top      int   // First row shown.
+	page     int   // Rows shown by the last Lines.
+}
+
+// diffContext is the number of rows shown above a hunk moved to.
+const diffContext = 3
+
+// NewDiffView creates a view of the changes from old to new.
+func NewDiffView(old, new string) *DiffView {
+	d := &DiffView{RemovedStyle: Red, AddedStyle: Green, hunk: -1}
+	d.SetTexts(old, new)
+	return d
+}
+
+// SetTexts replaces the texts compared, going back to the top.
+func (d *DiffView) SetTexts(old, new string) {
+	split := func(s string) []string {
+		s = strings.TrimSuffix(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+		if s == "" {
+			return nil
+		}
+		lines := strings.Split(s, "\n")
+		for i, line := range lines {
+			lines[i] = strings.ReplaceAll(stripANSI(line), "\t", "    ")
+		}
+		return lines
+	}
+	d.old, d.new = split(old), split(new)
+	d.rows, d.hunks = d.rows[:0], d.hunks[:0]
+
+	// Lines removed and added between two unchanged lines are paired up,
+	// so a changed line shows beside what it became.
+	var removed, added []int
+	flush := func() {
+		if len(removed) == 0 && len(added) == 0 {
+			return
+		}
+		d.hunks = append(d.hunks, len(d.rows))
+		for i := range max(len(removed), len(added)) {
+			row := diffRow{left: -1, right: -1, changed: true}
+			if i < len(removed) {
+				row.left = removed[i]
+			}
+			if i < len(added) {
+				row.right = added[i]
+			}
+			d.rows = append(d.rows, row)
+		}
+		removed, added = removed[:0], added[:0]
+	}
+	for _, p := range diffStrings(d.old, d.new) {
+		switch {
+		case p.b < 0:
+			removed = append(removed, p.a)
+		case p.a < 0:
+			added = append(added, p.b)
+		default:
+			flush()
+			d.rows = append(d.rows, diffRow{left: p.a, right: p.b})
+		}
+	}
+	flush()
+	d.top, d.hunk = 0, -1
+}
+
+// Hunks returns the number of runs of changed lines.
+func (d *DiffView) Hunks() int {
+	return len(d.hunks)
+}
+
+// Hunk returns the index of the current hunk, the last one moved to or
+// scrolled past, or -1 if there is none.
+func (d *DiffView) Hunk() int {
+	return d.hunk
+}
+
+// ScrollTo scrolls to show row first, or as close as possible with the view
+// still full, and makes the last hunk starting near or above it current.
+func (d *DiffView) ScrollTo(row int) {
+	d.top = max(min(row, len(d.rows)-max(d.page, 1)), 0)
+	d.hunk = -1
+	for i, start := range d.hunks {
+		if start <= d.top+diffContext {
+			d.hunk = i
+		}
+	}
+}
+
+// GotoHunk scrolls to show hunk i a few rows below the top and makes it
+// current.
+func (d *DiffView) GotoHunk(i int) {
+	if i < 0 || i >= len(d.hunks) {
+		return
+	}
+	d.top = max(min(d.hunks[i]-diffContext, len(d.rows)-max(d.page, 1)), 0)
+	d.hunk = i
+}
+
+// NextHunk goes to the hunk after the current one.
+func (d *DiffView) NextHunk() {
+	d.GotoHunk(d.hunk + 1)
+}
+
+// PrevHunk goes to the hunk before the current one.
+func (d *DiffView) PrevHunk() {
+	d.GotoHunk(d.hunk - 1)
+}
+
+// HandleKey scrolls with the arrow keys (or j and k), PageUp and PageDown
+// (or b and Space), g and G, and moves between hunks with n and N (or ] and
+// [), and reports whether the key was used.
+func (d *DiffView) HandleKey(keyType, key string) bool {
+	page := max(d.page-1, 1)
+	switch {
+	case keyType == "Arrow" && key == "up", keyType == "Character" && key == "k":
+		d.ScrollTo(d.top - 1)
+	case keyType == "Arrow" && key == "down", keyType == "Character" && key == "j":
+		d.ScrollTo(d.top + 1)
+	case keyType == "Special" && key == "pageup", keyType == "Character" && key == "b":
+		d.ScrollTo(d.top - page)
+	case keyType == "Special" && key == "pagedown", keyType == "Character" && (key == " " || key == "f"):
+		d.ScrollTo(d.top + page)
+	case keyType == "Special" && key == "home", keyType == "Character" && key == "g":
+		d.ScrollTo(0)
+	case keyType == "Special" && key == "end", keyType == "Character" && key == "G":
+		d.ScrollTo(len(d.rows))
+	case keyType == "Character" && (key == "n" || key == "]"):
+		d.NextHunk()
+	case keyType == "Character" && (key == "N" || key == "["):
+		d.PrevHunk()
+	default:
+		return false
+	}
+	return true
+}
+
+// Lines returns the view in width columns and height rows: the titles if
+// set, the rows of both texts split by a line, and a status line with the
+// current hunk.
+func (d *DiffView) Lines(width, height int) []string {
+	if width <= 0 || height <= 0 {
+		return nil
+	}
+	side := max((width-1)/2, 1)
+	var lines []string
+	if d.OldTitle != "" || d.NewTitle != "" {
+		lines = append(lines, padWidth(Bold+truncateWidth(d.OldTitle, side)+End, side)+Faint+"│"+End+Bold+truncateWidth(d.NewTitle, width-side-1)+End)
+	}
+	d.page = max(height-len(lines)-1, 1)
+	d.top = max(min(d.top, len(d.rows)-d.page), 0)
+
+	digits := len(strconv.Itoa(max(len(d.old), len(d.new), 1)))
+	for i := d.top; i < d.top+d.page && len(lines) < height-1; i++ {
+		if i >= len(d.rows) {
+			lines = append(lines, strings.Repeat(" ", side)+Faint+"│"+End)
+			continue
+		}
+		row := d.rows[i]
+		left, right := d.sides(row)
+		lines = append(lines,
+			padWidth(cell(row.left, left, row.changed, "-", d.RemovedStyle, digits, side), side)+
+				Faint+"│"+End+
+				cell(row.right, right, row.changed, "+", d.AddedStyle, digits, width-side-1))
+	}
+
+	status := "no changes"
+	if len(d.hunks) > 0 {
+		status = fmt.Sprintf("hunk %d/%d", d.hunk+1, len(d.hunks))
+		if d.hunk < 0 {
+			status = pluralize(len(d.hunks), "hunk")
+		}
+	}
+	status += fmt.Sprintf(" · rows %d-%d/%d", min(d.top+1, len(d.rows)), min(d.top+d.page, len(d.rows)), len(d.rows))
+	return append(lines, Negative+padWidth(truncateWidth(" "+status, width), width)+End)
+}
+
+// sides returns the text of both sides of row, with the words that differ
+// between them highlighted if it is a changed line.
+func (d *DiffView) sides(row diffRow) (string, string) {
+	var left, right string
+	if row.left >= 0 {
+		left = d.old[row.left]
+	}
+	if row.right >= 0 {
+		right = d.new[row.right]
+	}
+	if !row.changed || row.left < 0 || row.right < 0 {
+		return left, right
+	}
+	a, b := diffTokens(left), diffTokens(right)
+	var sa, sb strings.Builder
+	for _, p := range diffStrings(a, b) {
+		switch {
+		case p.b < 0:
+			sa.WriteString(Negative + a[p.a] + End + d.RemovedStyle)
+		case p.a < 0:
+			sb.WriteString(Negative + b[p.b] + End + d.AddedStyle)
+		default:
+			sa.WriteString(a[p.a])
+			sb.WriteString(b[p.b])
+		}
+	}
+	return sa.String(), sb.String()
+}
+
+// cell returns one side of a row of width columns: the line number, mark
+// and text of line i, in style if it changed, or blank if i is -1.
+func cell(i int, text string, changed bool, mark, style string, digits, width int) string {
+	if i < 0 {
+		return ""
+	}
+	gutter := Faint + fmt.Sprintf("%*d ", digits, i+1) + End
+	if !changed {
+		return truncateWidth(gutter+"  "+text, width)
+	}
+	return truncateWidth(gutter+style+mark+" "+text+End, width)
+}
+
+// padWidth pads s with spaces to width columns.
+func padWidth(s string, width int) string {
+	return s + strings.Repeat(" ", max(width-visibleWidth(s), 0))
+}
+
+// Run shows the view filling the alternate screen until q, Escape or Ctrl-C
+// is pressed. InputWriter and InputKeyReader may be given in opts.
+func (d *DiffView) Run(opts ...InputOption) error {
+	return runScreen(opts, false, func(r Rect) string {
+		return renderRect(r, d.Lines)
+	}, func(keyType, key string) {
+		d.HandleKey(keyType, key)
+	})
+}
+
+// ViewDiff shows the changes from old to new side by side in the alternate
+// screen, as DiffView.Run does. If the output is not a terminal the rows are
+// written to it instead, with "-" and "+" marking the changed lines.
+// InputWriter and InputKeyReader may be given in opts.
+func ViewDiff(old, new string, opts ...InputOption) error {
+	d := NewDiffView(old, new)
+	cfg := &inputConfig{out: os.Stdout}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if !isTerminal(cfg.out) {
+		width, _ := termSize(cfg.out)
+		lines := d.Lines(width, len(d.rows)+1)
+		for _, line := range lines[:len(lines)-1] {
+			if _, err := fmt.Fprintln(cfg.out, strings.TrimRight(stripANSI(line), " ")); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return d.Run(opts...)
+}
@@ -0,0 +1,85 @@
+package ansi
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+// checkDiff checks that pairs is an edit script turning a into b and returns
+// the number of edits in it.
+func checkDiff(t *testing.T, a, b []string, pairs []diffPair) int {
+	t.Helper()
+	i, j, edits := 0, 0, 0
+	for _, p := range pairs {
+		switch {
+		case p.a >= 0 && p.b >= 0:
+			if p.a != i || p.b != j || a[i] != b[j] {
+				t.Fatalf("%v to %v: bad common line %v", a, b, p)
+			}
+			i, j = i+1, j+1
+		case p.a >= 0:
+			if p.a != i {
+				t.Fatalf("%v to %v: bad removed line %v", a, b, p)
+			}
+			i, edits = i+1, edits+1
+		default:
+			if p.b != j {
+				t.Fatalf("%v to %v: bad added line %v", a, b, p)
+			}
+			j, edits = j+1, edits+1
+		}
+	}
+	if i != len(a) || j != len(b) {
+		t.Fatalf("%v to %v: script ends at %d, %d", a, b, i, j)
+	}
+	return edits
+}
+
+func TestDiffStrings(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	lines := func(n int) []string {
+		s := make([]string, n)
+		for i := range s {
+			s[i] = strconv.Itoa(r.Intn(4))
+		}
+		return s
+	}
+	for range 200 {
+		a, b := lines(r.Intn(12)), lines(r.Intn(12))
+		edits := checkDiff(t, a, b, diffStrings(a, b))
+		// The shortest script keeps a longest common subsequence.
+		lcs := make([][]int, len(a)+1)
+		for i := range lcs {
+			lcs[i] = make([]int, len(b)+1)
+		}
+		for i := len(a) - 1; i >= 0; i-- {
+			for j := len(b) - 1; j >= 0; j-- {
+				if a[i] == b[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else {
+					lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+				}
+			}
+		}
+		if want := len(a) + len(b) - 2*lcs[0][0]; edits != want {
+			t.Errorf("%v to %v: %d edits, want %d", a, b, edits, want)
+		}
+	}
+}
+
+func TestDiffStringsFarApart(t *testing.T) {
+	a, b := make([]string, 5000), make([]string, 5000)
+	for i := range a {
+		a[i], b[i] = "a"+strconv.Itoa(i), "b"+strconv.Itoa(i)
+	}
+	a[0], b[0] = "same", "same"
+	a[4999], b[4999] = "end", "end"
+	pairs := diffStrings(a, b)
+	if edits := checkDiff(t, a, b, pairs); edits != 2*4998 {
+		t.Errorf("%d edits, want %d", edits, 2*4998)
+	}
+	if pairs[0] != (diffPair{0, 0}) || pairs[len(pairs)-1] != (diffPair{4999, 4999}) {
+		t.Errorf("first and last lines are not kept: %v, %v", pairs[0], pairs[len(pairs)-1])
+	}
+}
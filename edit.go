@@ -0,0 +1,150 @@
+package ansi
+
+import "unicode"
+
+// --------------------
+// Line Editing
+// --------------------
+
+// setText replaces the line and moves the cursor to its end.
+func (st *inputState) setText(text []rune) {
+	st.text = text
+	st.cursor = len(text)
+}
+
+// insert inserts runes at the cursor.
+func (st *inputState) insert(rs []rune) {
+	text := make([]rune, 0, len(st.text)+len(rs))
+	text = append(text, st.text[:st.cursor]...)
+	text = append(text, rs...)
+	text = append(text, st.text[st.cursor:]...)
+	if st.cfg.masked {
+		clear(st.text)
+	}
+	st.text = text
+	st.cursor += len(rs)
+}
+
+// deleteRange removes the runes between from and to, moving the cursor to from.
+// The removed text is returned.
+func (st *inputState) deleteRange(from, to int) []rune {
+	removed := append([]rune(nil), st.text[from:to]...)
+	text := make([]rune, 0, len(st.text)-(to-from))
+	text = append(text, st.text[:from]...)
+	text = append(text, st.text[to:]...)
+	if st.cfg.masked {
+		clear(st.text)
+	}
+	st.text = text
+	st.cursor = from
+	return removed
+}
+
+// backspace deletes the rune before the cursor.
+func (st *inputState) backspace() {
+	if st.cursor > 0 {
+		st.deleteRange(st.cursor-1, st.cursor)
+	}
+}
+
+// deleteChar deletes the rune under the cursor.
+func (st *inputState) deleteChar() {
+	if st.cursor < len(st.text) {
+		st.deleteRange(st.cursor, st.cursor+1)
+	}
+}
+
+// wordStart returns the start of the word before the cursor.
+func (st *inputState) wordStart() int {
+	i := st.cursor
+	for i > 0 && unicode.IsSpace(st.text[i-1]) {
+		i--
+	}
+	for i > 0 && !unicode.IsSpace(st.text[i-1]) {
+		i--
+	}
+	return i
+}
+
+// wordEnd returns the end of the word after the cursor.
+func (st *inputState) wordEnd() int {
+	i := st.cursor
+	for i < len(st.text) && unicode.IsSpace(st.text[i]) {
+		i++
+	}
+	for i < len(st.text) && !unicode.IsSpace(st.text[i]) {
+		i++
+	}
+	return i
+}
+
+// kill deletes the runes between from and to and stores them for yank.
+func (st *inputState) kill(from, to int) {
+	if from < to {
+		st.killed = st.deleteRange(from, to)
+	}
+}
+
+// handleEditKey applies the emacs-style editing keys and reports whether key
+// was one of them.
+func (st *inputState) handleEditKey(keyType, key string) bool {
+	switch keyType {
+	case "Special":
+		switch key {
+		case "backspace", "ctrl-h":
+			st.backspace()
+		case "delete":
+			st.deleteChar()
+		case "home", "ctrl-a":
+			st.cursor = 0
+		case "end", "ctrl-e":
+			st.cursor = len(st.text)
+		case "ctrl-b":
+			if st.cursor > 0 {
+				st.cursor--
+			}
+		case "ctrl-f":
+			if st.cursor < len(st.text) {
+				st.cursor++
+			}
+		case "alt-b":
+			st.cursor = st.wordStart()
+		case "alt-f":
+			st.cursor = st.wordEnd()
+		case "ctrl-w":
+			st.kill(st.wordStart(), st.cursor)
+		case "alt-d":
+			st.kill(st.cursor, st.wordEnd())
+		case "ctrl-k":
+			st.kill(st.cursor, len(st.text))
+		case "ctrl-u":
+			st.kill(0, st.cursor)
+		case "ctrl-y":
+			st.insert(st.killed)
+		default:
+			return false
+		}
+	case "Arrow":
+		switch key {
+		case "left":
+			if st.cursor > 0 {
+				st.cursor--
+			}
+		case "right":
+			if st.cursor < len(st.text) {
+				st.cursor++
+			}
+		case "ctrl-left", "alt-left":
+			st.cursor = st.wordStart()
+		case "ctrl-right", "alt-right":
+			st.cursor = st.wordEnd()
+		default:
+			return false
+		}
+	case "Character", "Paste":
+		st.insert([]rune(key))
+	default:
+		return false
+	}
+	return true
+}
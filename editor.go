@@ -0,0 +1,491 @@
+package ansi
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// --------------------
+// Editor
+// --------------------
+
+// editorUndoLimit is the number of changes an Editor can undo.
+const editorUndoLimit = 100
+
+// editorRow is a screen row of an Editor: the runes from start to end,
+// without the line break.
+type editorRow struct {
+	start, end int
+}
+
+// editorState is a snapshot of an Editor's text, kept for undo.
+type editorState struct {
+	text   []rune
+	cursor int
+}
+
+// Editor is a multi-line text editor that widgets can embed: it draws into a
+// rectangle of the screen and is driven by the keys passed to HandleKey.
+// Lines longer than the width wrap at spaces. Edit runs one on its own.
+type Editor struct {
+	Row, Col      int // Screen position of the top left corner, counted from 1.
+	Width, Height int // Size in columns and rows.
+
+	// Autocomplete, if set, offers suggestions for the word before the
+	// cursor, which Render shows in a dropdown next to it. While the
+	// dropdown is open, Tab and the arrow keys move through it, Enter
+	// accepts a suggestion and Escape closes it.
+	Autocomplete *Autocomplete
+
+	text   []rune
+	cursor int // Position of the cursor in text.
+	anchor int // Other end of the selection from the cursor, or -1.
+	goal   int // Column kept when moving up and down, or -1.
+	top    int // First row shown.
+	undo   []editorState
+	typing bool // Whether the last change was typing, merged into one undo step.
+}
+
+// NewEditor creates an editor holding text, with the cursor at its end, in
+// a rectangle of the screen.
+func NewEditor(text string, row, col, width, height int) *Editor {
+	e := &Editor{Row: row, Col: col, Width: width, Height: height, anchor: -1, goal: -1}
+	e.SetText(text)
+	return e
+}
+
+// SetText replaces the text, moving the cursor to its end. It cannot be
+// undone.
+func (e *Editor) SetText(text string) {
+	e.text = editorRunes(text)
+	e.cursor = len(e.text)
+	e.anchor, e.goal = -1, -1
+	e.undo = nil
+	e.typing = false
+}
+
+// Text returns the text.
+func (e *Editor) Text() string {
+	return string(e.text)
+}
+
+// Selection returns the selected text, or "" if nothing is selected.
+func (e *Editor) Selection() string {
+	from, to, ok := e.selection()
+	if !ok {
+		return ""
+	}
+	return string(e.text[from:to])
+}
+
+// editorRunes returns text with line breaks as "\n" and tabs as spaces.
+func editorRunes(text string) []rune {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	return []rune(strings.ReplaceAll(text, "\t", "    "))
+}
+
+// HandleKey applies a key, as returned by KeyReader.CaptureKey, and reports
+// whether the editor used it. Characters and Enter insert text, replacing
+// any selection; the arrow keys, Home, End, PageUp and PageDown move, with
+// Shift selecting; Ctrl-Left and Ctrl-Right move by words, Ctrl-A and
+// Ctrl-E to the start and end of the line, Backspace and Delete delete, and
+// Ctrl-Z undoes the last change.
+func (e *Editor) HandleKey(keyType, key string) bool {
+	if ac := e.Autocomplete; ac != nil {
+		if ac.HandleKey(keyType, key) {
+			if s, ok := ac.Accepted(); ok {
+				e.complete(s)
+			}
+			return true
+		}
+		defer e.suggest()
+	}
+	switch keyType {
+	case "Character", "Paste":
+		e.insert(editorRunes(key), keyType == "Character")
+		return true
+	case "Arrow":
+		shift := strings.HasPrefix(key, "shift-")
+		key = strings.TrimPrefix(key, "shift-")
+		switch key {
+		case "left":
+			e.move(e.cursor-1, shift)
+		case "right":
+			e.move(e.cursor+1, shift)
+		case "up":
+			e.moveRows(-1, shift)
+		case "down":
+			e.moveRows(1, shift)
+		case "ctrl-left", "alt-left":
+			e.move(e.wordStart(), false)
+		case "ctrl-right", "alt-right":
+			e.move(e.wordEnd(), false)
+		default:
+			return false
+		}
+		return true
+	case "Special":
+	default:
+		return false
+	}
+	rows := e.layout()
+	row := e.cursorRow(rows)
+	switch key {
+	case "enter":
+		e.insert([]rune{'\n'}, false)
+	case "tab":
+		e.insert([]rune("    "), true)
+	case "backspace", "ctrl-h":
+		if !e.deleteSelection() && e.cursor > 0 {
+			e.remove(e.cursor-1, e.cursor)
+		}
+	case "delete":
+		if !e.deleteSelection() && e.cursor < len(e.text) {
+			e.remove(e.cursor, e.cursor+1)
+		}
+	case "home":
+		e.move(rows[row].start, false)
+	case "end":
+		e.move(e.rowEnd(rows, row), false)
+	case "ctrl-a":
+		e.move(e.lineStart(e.cursor), false)
+	case "ctrl-e":
+		e.move(e.lineEnd(e.cursor), false)
+	case "pageup":
+		e.moveRows(-max(e.Height-1, 1), false)
+	case "pagedown":
+		e.moveRows(max(e.Height-1, 1), false)
+	case "ctrl-z":
+		e.Undo()
+	default:
+		return false
+	}
+	return true
+}
+
+// suggest gives the line of the cursor to the Autocomplete.
+func (e *Editor) suggest() {
+	start := e.lineStart(e.cursor)
+	e.Autocomplete.Update(string(e.text[start:e.lineEnd(e.cursor)]), e.cursor-start)
+}
+
+// complete replaces the word before the cursor with the suggestion s, as
+// one change.
+func (e *Editor) complete(s Suggestion) {
+	start := e.lineStart(e.cursor)
+	e.anchor = start + wordBefore(e.text[start:e.cursor], e.cursor-start)
+	if e.anchor == e.cursor {
+		e.anchor = -1
+	}
+	e.insert([]rune(s.Text), false)
+	e.suggest()
+}
+
+// Undo reverts the last change. Consecutive typed characters are undone
+// together.
+func (e *Editor) Undo() {
+	if len(e.undo) == 0 {
+		return
+	}
+	last := e.undo[len(e.undo)-1]
+	e.undo = e.undo[:len(e.undo)-1]
+	e.text, e.cursor = last.text, last.cursor
+	e.anchor, e.goal = -1, -1
+	e.typing = false
+}
+
+// snapshot saves the text for Undo before a change. Typing after typing
+// adds to the last snapshot's change instead of saving another.
+func (e *Editor) snapshot(typing bool) {
+	if typing && e.typing {
+		return
+	}
+	e.typing = typing
+	e.undo = append(e.undo, editorState{append([]rune(nil), e.text...), e.cursor})
+	if len(e.undo) > editorUndoLimit {
+		e.undo = e.undo[1:]
+	}
+}
+
+// insert replaces the selection, if any, with rs.
+func (e *Editor) insert(rs []rune, typing bool) {
+	if len(rs) == 0 {
+		return
+	}
+	from, to, ok := e.selection()
+	if !ok {
+		from, to = e.cursor, e.cursor
+	}
+	e.snapshot(typing && !ok)
+	e.typing = typing
+	e.text = append(e.text[:from:from], append(rs, e.text[to:]...)...)
+	e.cursor = from + len(rs)
+	e.anchor, e.goal = -1, -1
+}
+
+// remove deletes the runes from from to to.
+func (e *Editor) remove(from, to int) {
+	e.snapshot(false)
+	e.text = append(e.text[:from:from], e.text[to:]...)
+	e.cursor = from
+	e.anchor, e.goal = -1, -1
+}
+
+// deleteSelection deletes the selected text and reports whether there was
+// any.
+func (e *Editor) deleteSelection() bool {
+	from, to, ok := e.selection()
+	if ok {
+		e.remove(from, to)
+	}
+	return ok
+}
+
+// selection returns the selected range and whether there is one.
+func (e *Editor) selection() (int, int, bool) {
+	if e.anchor < 0 || e.anchor == e.cursor {
+		return 0, 0, false
+	}
+	return min(e.anchor, e.cursor), max(e.anchor, e.cursor), true
+}
+
+// move moves the cursor to pos, extending the selection if selecting is set
+// and dropping it otherwise.
+func (e *Editor) move(pos int, selecting bool) {
+	if selecting && e.anchor < 0 {
+		e.anchor = e.cursor
+	} else if !selecting {
+		e.anchor = -1
+	}
+	e.cursor = max(min(pos, len(e.text)), 0)
+	e.goal = -1
+	e.typing = false
+}
+
+// moveRows moves the cursor n screen rows up or down, keeping its column.
+func (e *Editor) moveRows(n int, selecting bool) {
+	rows := e.layout()
+	row := e.cursorRow(rows)
+	goal := e.goal
+	if goal < 0 {
+		goal = runesWidth(e.text[rows[row].start:e.cursor])
+	}
+	target := max(min(row+n, len(rows)-1), 0)
+	pos := rows[target].start
+	for col := 0; pos < e.rowEnd(rows, target); pos++ {
+		col += runeWidth(e.text[pos])
+		if col > goal {
+			break
+		}
+	}
+	switch {
+	case row+n < 0:
+		pos = 0
+	case row+n >= len(rows):
+		pos = len(e.text)
+	}
+	e.move(pos, selecting)
+	e.goal = goal
+}
+
+// layout splits the text into screen rows of the editor's width, wrapping
+// long lines after the last space that fits, or anywhere if none does.
+func (e *Editor) layout() []editorRow {
+	width := max(e.Width, 1)
+	var rows []editorRow
+	start, col, space := 0, 0, -1
+	for i, r := range e.text {
+		if r == '\n' {
+			rows = append(rows, editorRow{start, i})
+			start, col, space = i+1, 0, -1
+			continue
+		}
+		if w := runeWidth(r); col+w > width && i > start {
+			if space >= start {
+				rows = append(rows, editorRow{start, space + 1})
+				start = space + 1
+			} else {
+				rows = append(rows, editorRow{start, i})
+				start = i
+			}
+			col, space = runesWidth(e.text[start:i]), -1
+		}
+		col += runeWidth(r)
+		if unicode.IsSpace(r) {
+			space = i
+		}
+	}
+	return append(rows, editorRow{start, len(e.text)})
+}
+
+// cursorRow returns the row of rows holding the cursor.
+func (e *Editor) cursorRow(rows []editorRow) int {
+	row := 0
+	for i, r := range rows {
+		if r.start <= e.cursor {
+			row = i
+		}
+	}
+	return row
+}
+
+// rowEnd returns the last cursor position within row: its end, or the
+// position before it if the row wraps, since its end starts the next row.
+func (e *Editor) rowEnd(rows []editorRow, row int) int {
+	if row+1 < len(rows) && rows[row+1].start == rows[row].end {
+		return rows[row].end - 1
+	}
+	return rows[row].end
+}
+
+// lineStart returns the start of the line holding pos.
+func (e *Editor) lineStart(pos int) int {
+	for pos > 0 && e.text[pos-1] != '\n' {
+		pos--
+	}
+	return pos
+}
+
+// lineEnd returns the end of the line holding pos.
+func (e *Editor) lineEnd(pos int) int {
+	for pos < len(e.text) && e.text[pos] != '\n' {
+		pos++
+	}
+	return pos
+}
+
+// wordStart returns the start of the word before the cursor.
+func (e *Editor) wordStart() int {
+	i := e.cursor
+	for i > 0 && unicode.IsSpace(e.text[i-1]) {
+		i--
+	}
+	for i > 0 && !unicode.IsSpace(e.text[i-1]) {
+		i--
+	}
+	return i
+}
+
+// wordEnd returns the end of the word after the cursor.
+func (e *Editor) wordEnd() int {
+	i := e.cursor
+	for i < len(e.text) && unicode.IsSpace(e.text[i]) {
+		i++
+	}
+	for i < len(e.text) && !unicode.IsSpace(e.text[i]) {
+		i++
+	}
+	return i
+}
+
+// View returns the rows shown, each padded to the width, scrolled so the
+// cursor is visible. Selected text is shown in Negative.
+func (e *Editor) View() []string {
+	rows := e.layout()
+	row := e.cursorRow(rows)
+	height := max(e.Height, 1)
+	e.top = max(min(e.top, row), row-height+1)
+	from, to, _ := e.selection()
+	view := make([]string, 0, height)
+	for i := e.top; i < e.top+height; i++ {
+		var sb strings.Builder
+		width := 0
+		if i < len(rows) {
+			for pos := rows[i].start; pos < rows[i].end; pos++ {
+				r := string(e.text[pos])
+				if pos >= from && pos < to {
+					r = Negative + r + End
+				}
+				sb.WriteString(r)
+				width += runeWidth(e.text[pos])
+			}
+		}
+		sb.WriteString(strings.Repeat(" ", max(e.Width-width, 0)))
+		view = append(view, sb.String())
+	}
+	return view
+}
+
+// Cursor returns the screen row and column of the cursor, as drawn by the
+// last View or Render.
+func (e *Editor) Cursor() (int, int) {
+	rows := e.layout()
+	row := e.cursorRow(rows)
+	col := runesWidth(e.text[rows[row].start:e.cursor])
+	return e.Row + row - e.top, e.Col + min(col, max(e.Width-1, 0))
+}
+
+// Render returns the escape sequences drawing the editor at its position
+// and moving the cursor to the editor's cursor.
+func (e *Editor) Render() string {
+	var sb strings.Builder
+	for i, row := range e.View() {
+		sb.WriteString(fmt.Sprintf("\033[%d;%dH%s", e.Row+i, e.Col, row))
+	}
+	row, col := e.Cursor()
+	if e.Autocomplete != nil {
+		sb.WriteString(e.dropdown(row, col))
+	}
+	sb.WriteString(fmt.Sprintf("\033[%d;%dH", row, col))
+	return sb.String()
+}
+
+// dropdown returns the escape sequences drawing the suggestions of the
+// Autocomplete within the editor, below the cursor at row and col or above
+// it if they fit only there.
+func (e *Editor) dropdown(row, col int) string {
+	below, above := e.Row+e.Height-1-row, row-e.Row
+	lines := e.Autocomplete.Lines(e.Col+e.Width-col, max(below, above))
+	width := 0
+	for _, line := range lines {
+		width = max(width, visibleWidth(line))
+	}
+	first := row + 1
+	if len(lines) > below {
+		first = row - len(lines)
+	}
+	var sb strings.Builder
+	for i, line := range lines {
+		sb.WriteString(fmt.Sprintf("\033[%d;%dH%s", first+i, col, padWidth(line, width)))
+	}
+	return sb.String()
+}
+
+// Edit lets the user edit text in an Editor filling the alternate screen and
+// returns the result when Ctrl-S or Ctrl-D is pressed. Escape and Ctrl-C
+// cancel with ErrInterrupted. InputWriter and InputKeyReader may be given in
+// opts.
+func Edit(text string, opts ...InputOption) (string, error) {
+	cfg := &inputConfig{out: os.Stdout}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.keys == nil {
+		cfg.keys = defaultKeyReader()
+	}
+	e := NewEditor(text, 1, 1, 0, 0)
+	fmt.Fprint(cfg.out, "\033[?1049h\033[2J")
+	defer fmt.Fprint(cfg.out, "\033[?1049l")
+	for {
+		width, height := termSize(cfg.out)
+		e.Width, e.Height = width, max(height-1, 1)
+		status := truncateWidth(" ^S save · ^Z undo · shift+arrows select · esc cancel", width)
+		fmt.Fprintf(cfg.out, "\033[?25l\033[%d;1H\033[2K%s%s%s%s\033[?25h", height, Faint, status, End, e.Render())
+
+		keyType, key, err := cfg.keys.readText()
+		if err != nil {
+			return e.Text(), err
+		}
+		switch {
+		case keyType == "Special" && (key == "ctrl-s" || key == "ctrl-d"):
+			return e.Text(), nil
+		case keyType == "Special" && (key == "escape" || key == "ctrl-c"):
+			return e.Text(), ErrInterrupted
+		default:
+			e.HandleKey(keyType, key)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package ansi
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// --------------------
+// File Picker
+// --------------------
+
+// FilePicker lets the user browse the file system as a Tree and choose
+// files or directories.
+type FilePicker struct {
+	Dir      string // Where browsing starts; "" for the current directory.
+	Glob     string // Pattern, like "*.go", files must match to be shown; "" for all.
+	Dirs     bool   // Choose directories; files are not shown.
+	Hidden   bool   // Show entries whose names start with ".".
+	Multiple bool   // Choose several entries, as with Tree.PickMany.
+}
+
+// Pick shows the tree below the starting directory, with the size of each
+// file, and returns the paths chosen, joined to Dir. Directories are loaded
+// as they are expanded. Without Multiple a single path is returned: Enter
+// chooses a file, or a directory without subdirectories when Dirs is set,
+// and Space chooses any entry. The keys and opts are those of Tree.Pick.
+func (fp *FilePicker) Pick(opts ...InputOption) ([]string, error) {
+	dir := fp.Dir
+	if dir == "" {
+		dir = "."
+	}
+	root := &TreeNode{Label: Blue + dir + End, Value: dir, Load: fp.load}
+	if err := root.expand(); err != nil {
+		return nil, err
+	}
+	tree := NewTree(root)
+
+	var nodes []*TreeNode
+	var err error
+	if fp.Multiple {
+		nodes, err = tree.PickMany(opts...)
+	} else {
+		var node *TreeNode
+		node, err = tree.Pick(opts...)
+		nodes = []*TreeNode{node}
+	}
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, len(nodes))
+	for i, node := range nodes {
+		paths[i] = node.Value.(string)
+	}
+	return paths, nil
+}
+
+// load reads the directory of node n into nodes, directories first.
+func (fp *FilePicker) load(n *TreeNode) ([]*TreeNode, error) {
+	dir := n.Value.(string)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].IsDir() && !entries[j].IsDir()
+	})
+	var children []*TreeNode
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") && !fp.Hidden {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		if entry.IsDir() {
+			children = append(children, &TreeNode{Label: Blue + name + "/" + End, Value: path, Load: fp.load, Branch: !fp.Dirs})
+			continue
+		}
+		if fp.Dirs {
+			continue
+		}
+		if fp.Glob != "" {
+			if ok, _ := filepath.Match(fp.Glob, name); !ok {
+				continue
+			}
+		}
+		label := name
+		if info, err := entry.Info(); err == nil {
+			label += "  " + Faint + FormatBytes(float64(info.Size())) + End
+		}
+		children = append(children, &TreeNode{Label: label, Value: path})
+	}
+	return children, nil
+}
@@ -0,0 +1,150 @@
+package ansi
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// --------------------
+// Footer
+// --------------------
+
+// Footer keeps one or more status lines at the bottom of the output while
+// text printed through it scrolls above them, like the progress footer of
+// apt. Everything written to the terminal while the footer is shown should go
+// through Println, Printf or Write. It is safe for use from several goroutines.
+type Footer struct {
+	mu      sync.Mutex
+	out     io.Writer
+	plain   *bool
+	lines   []string
+	drawn   int    // Lines of the footer on screen.
+	partial []byte // Text written without a trailing newline yet.
+}
+
+// FooterOption configures a Footer.
+type FooterOption func(*Footer)
+
+// FooterWriter sets where the footer is drawn. It defaults to os.Stdout.
+func FooterWriter(w io.Writer) FooterOption {
+	return func(f *Footer) {
+		f.out = w
+	}
+}
+
+// FooterPlain forces plain-text output on or off. In plain mode text is
+// passed through and the status lines are not shown. By default it is used
+// when the output is not a terminal.
+func FooterPlain(on bool) FooterOption {
+	return func(f *Footer) {
+		f.plain = &on
+	}
+}
+
+// NewFooter creates a footer showing lines.
+func NewFooter(lines []string, opts ...FooterOption) *Footer {
+	f := &Footer{out: os.Stdout, lines: lines}
+	for _, opt := range opts {
+		opt(f)
+	}
+	if f.plain == nil {
+		plain := !isTerminal(f.out)
+		f.plain = &plain
+	}
+	f.mu.Lock()
+	f.draw("")
+	f.mu.Unlock()
+	return f
+}
+
+// SetLines replaces the status lines.
+func (f *Footer) SetLines(lines ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lines = lines
+	f.draw("")
+}
+
+// SetLine replaces the status line at index i, adding empty lines if needed.
+func (f *Footer) SetLine(i int, line string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for len(f.lines) <= i {
+		f.lines = append(f.lines, "")
+	}
+	f.lines[i] = line
+	f.draw("")
+}
+
+// Println prints a line above the footer, like fmt.Println.
+func (f *Footer) Println(a ...any) {
+	f.Write([]byte(fmt.Sprintln(a...)))
+}
+
+// Printf prints above the footer, like fmt.Printf. A line without a trailing
+// newline is held back until it is completed.
+func (f *Footer) Printf(format string, a ...any) {
+	f.Write([]byte(fmt.Sprintf(format, a...)))
+}
+
+// Write prints p above the footer, so a Footer can be used as the output of
+// a logger. An unfinished line is held back until its newline arrives.
+func (f *Footer) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.partial = append(f.partial, p...)
+	end := bytes.LastIndexByte(f.partial, '\n')
+	if end < 0 {
+		return len(p), nil
+	}
+	text := string(f.partial[:end+1])
+	f.partial = append(f.partial[:0], f.partial[end+1:]...)
+	f.draw(text)
+	return len(p), nil
+}
+
+// Close removes the footer from the screen, printing any held back text.
+func (f *Footer) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	text := string(f.partial)
+	if text != "" {
+		text += "\n"
+	}
+	f.partial = nil
+	f.lines = nil
+	f.draw(text)
+	return nil
+}
+
+// draw erases the footer, prints text and draws the footer again below it.
+// The cursor is left at the end of the last status line.
+func (f *Footer) draw(text string) {
+	if f.plain != nil && *f.plain {
+		fmt.Fprint(f.out, text)
+		return
+	}
+	var sb strings.Builder
+	if f.drawn > 0 {
+		sb.WriteString("\r")
+		if f.drawn > 1 {
+			sb.WriteString(fmt.Sprintf("\033[%dA", f.drawn-1))
+		}
+		sb.WriteString("\033[J")
+	}
+	sb.WriteString(text)
+	width, _ := termSize(f.out)
+	for i, line := range f.lines {
+		if i > 0 {
+			sb.WriteString("\r\n")
+		}
+		// Wrapped lines would throw off the erasing above.
+		sb.WriteString(truncateWidth(line, width-1))
+	}
+	f.drawn = len(f.lines)
+	fmt.Fprint(f.out, sb.String())
+}
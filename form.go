@@ -0,0 +1,334 @@
+package ansi
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// --------------------
+// Form
+// --------------------
+
+// Kinds of FormField.
+const (
+	FormText     = "text"
+	FormPassword = "password"
+	FormSelect   = "select"
+	FormConfirm  = "confirm"
+)
+
+// FormField is one labeled field of a Form.
+type FormField struct {
+	Name        string             // Key of the value in the result.
+	Label       string             // Shown before the field.
+	Kind        string             // FormText (the default), FormPassword, FormSelect or FormConfirm.
+	Options     []string           // Choices of a FormSelect field.
+	Default     any                // Initial value: a string, or a bool for FormConfirm.
+	Placeholder string             // Hint shown in Faint while a text field is empty.
+	Required    bool               // Marks the field with "*" and refuses an empty value.
+	Validate    func(string) error // Checks the value of a text, password or select field.
+
+	// Autocomplete, if set, offers suggestions for the word before the cursor
+	// of a text field, in a dropdown below it. While the dropdown is open,
+	// Tab and the arrow keys move through it rather than between fields.
+	// Suggestions from a Source show once the form is next redrawn.
+	Autocomplete *Autocomplete
+}
+
+// Form shows labeled fields together and lets the user fill them in any
+// order before submitting.
+type Form struct {
+	Fields []FormField
+}
+
+// FormResult is the outcome of a Form.
+type FormResult struct {
+	Values    map[string]any // Values by field name: a string, or a bool for FormConfirm.
+	Submitted bool           // Whether the form was submitted rather than cancelled.
+}
+
+// formField is the state of a field of a running Form.
+type formField struct {
+	*FormField
+	st     *inputState // Text of text and password fields.
+	choice int         // Chosen option of a select field, or -1.
+	yes    bool        // Value of a confirm field.
+	err    string
+}
+
+// value returns the value of the field as text, for validation.
+func (f *formField) value() string {
+	switch f.Kind {
+	case FormSelect:
+		if f.choice >= 0 && f.choice < len(f.Options) {
+			return f.Options[f.choice]
+		}
+		return ""
+	case FormConfirm:
+		if f.yes {
+			return "yes"
+		}
+		return "no"
+	}
+	return string(f.st.text)
+}
+
+// autocomplete returns the Autocomplete of a text field, or nil.
+func (f *formField) autocomplete() *Autocomplete {
+	if f.Kind != FormText && f.Kind != "" {
+		return nil
+	}
+	return f.Autocomplete
+}
+
+// check validates the field, setting its error, and reports whether it is valid.
+func (f *formField) check() bool {
+	f.err = ""
+	value := f.value()
+	switch {
+	case f.Kind == FormConfirm:
+	case f.Required && value == "":
+		f.err = "Required"
+	case f.Validate != nil:
+		if err := f.Validate(value); err != nil {
+			f.err = err.Error()
+		}
+	}
+	return f.err == ""
+}
+
+// Run shows the form and returns the values once submitted with Enter. Tab
+// and Shift-Tab, or up and down, move between fields; text fields use the
+// usual editing keys, left and right change the option of a select field
+// and y, n, left, right or Space the answer of a confirm field. Required
+// fields that are empty and values refused by their validator are marked
+// with an error when submitting, and the first of them is focused. Escape
+// and Ctrl-C cancel, returning the values so far with Submitted unset. The
+// error is only set if reading the input failed. InputPromptStyle,
+// InputErrorStyle, InputWriter and InputKeyReader may be given in opts.
+func (f *Form) Run(opts ...InputOption) (FormResult, error) {
+	cfg := &inputConfig{out: os.Stdout, errorStyle: Red, promptStyle: Bold}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.keys == nil {
+		cfg.keys = defaultKeyReader()
+	}
+	if len(f.Fields) == 0 {
+		return FormResult{Values: map[string]any{}, Submitted: true}, nil
+	}
+	fields := make([]*formField, len(f.Fields))
+	for i := range f.Fields {
+		field := &formField{FormField: &f.Fields[i], choice: -1}
+		field.st = &inputState{cfg: &inputConfig{masked: field.Kind == FormPassword}, histPos: -1}
+		switch def := field.Default.(type) {
+		case string:
+			field.st.setText([]rune(def))
+			for j, option := range field.Options {
+				if option == def {
+					field.choice = j
+				}
+			}
+		case bool:
+			field.yes = def
+		}
+		fields[i] = field
+	}
+
+	fr := &formRun{cfg: cfg, fields: fields}
+	defer func() {
+		for _, field := range fields {
+			if field.Kind == FormPassword {
+				field.st.wipe()
+			}
+			if field.Autocomplete != nil {
+				field.Autocomplete.Close()
+			}
+		}
+	}()
+	for {
+		if ac := fields[fr.focus].autocomplete(); ac != nil {
+			ac.Update(string(fields[fr.focus].st.text), fields[fr.focus].st.cursor)
+		}
+		fr.draw(false)
+		read := cfg.keys.readKey
+		if kind := fields[fr.focus].Kind; kind == FormText || kind == FormPassword || kind == "" {
+			read = cfg.keys.readText
+		}
+		keyType, key, err := read()
+		if err != nil {
+			fr.draw(true)
+			return fr.result(false), err
+		}
+		field := fields[fr.focus]
+		if ac := field.autocomplete(); ac != nil && ac.HandleKey(keyType, key) {
+			if s, ok := ac.Accepted(); ok {
+				line, pos := ac.Insert(string(field.st.text), field.st.cursor, s)
+				field.st.setText([]rune(line))
+				field.st.cursor = pos
+			}
+			continue
+		}
+		switch {
+		case keyType == "Special" && (key == "escape" || key == "ctrl-c"):
+			fr.draw(true)
+			return fr.result(false), nil
+		case keyType == "Special" && key == "enter":
+			invalid := -1
+			for i, field := range fields {
+				if !field.check() && invalid < 0 {
+					invalid = i
+				}
+			}
+			if invalid < 0 {
+				fr.draw(true)
+				return fr.result(true), nil
+			}
+			fr.focus = invalid
+		case keyType == "Special" && key == "tab", keyType == "Arrow" && key == "down":
+			fr.focus = (fr.focus + 1) % len(fields)
+		case keyType == "Special" && key == "shift-tab", keyType == "Arrow" && key == "up":
+			fr.focus = (fr.focus + len(fields) - 1) % len(fields)
+		case field.Kind == FormSelect:
+			if len(field.Options) == 0 {
+				break
+			}
+			switch {
+			case keyType == "Arrow" && key == "left":
+				field.choice = (max(field.choice, 0) + len(field.Options) - 1) % len(field.Options)
+			case keyType == "Arrow" && key == "right", keyType == "Character" && key == " ":
+				field.choice = (field.choice + 1) % len(field.Options)
+			}
+			field.err = ""
+		case field.Kind == FormConfirm:
+			switch {
+			case keyType == "Character" && (key == "y" || key == "Y"):
+				field.yes = true
+			case keyType == "Character" && (key == "n" || key == "N"):
+				field.yes = false
+			case keyType == "Arrow" && (key == "left" || key == "right"), keyType == "Character" && key == " ":
+				field.yes = !field.yes
+			}
+		default:
+			field.st.handleEditKey(keyType, key)
+			field.err = ""
+		}
+	}
+}
+
+// formRun is the drawing state of a running Form.
+type formRun struct {
+	cfg       *inputConfig
+	fields    []*formField
+	focus     int // Index of the focused field.
+	cursorRow int // Row of the cursor below the first row.
+}
+
+// result returns the values of the fields.
+func (fr *formRun) result(submitted bool) FormResult {
+	values := make(map[string]any, len(fr.fields))
+	for _, field := range fr.fields {
+		if field.Kind == FormConfirm {
+			values[field.Name] = field.yes
+		} else {
+			values[field.Name] = field.value()
+		}
+	}
+	return FormResult{Values: values, Submitted: submitted}
+}
+
+// draw redraws the form with the cursor in the focused field. When done is
+// set the form is drawn without focus or help, leaving the cursor below it.
+func (fr *formRun) draw(done bool) {
+	cfg := fr.cfg
+	labelWidth := 0
+	for _, field := range fr.fields {
+		labelWidth = max(labelWidth, visibleWidth(field.Label))
+	}
+
+	var rows []string
+	cursorRow, cursorCol := 0, 0
+	for i, field := range fr.fields {
+		focused := i == fr.focus && !done
+		marker := "  "
+		if focused {
+			marker = Cyan + "> " + End
+		}
+		label := cfg.promptStyle + field.Label + End
+		required := " "
+		if field.Required {
+			required = Red + "*" + End
+		}
+		prefix := marker + label + required + strings.Repeat(" ", labelWidth-visibleWidth(field.Label)) + " "
+		value := ""
+		switch field.Kind {
+		case FormSelect:
+			option := Faint + "(none)" + End
+			if field.choice >= 0 && field.choice < len(field.Options) {
+				option = field.Options[field.choice]
+			}
+			if focused {
+				value = Faint + "‹ " + End + option + Faint + " ›" + End
+			} else {
+				value = option
+			}
+		case FormConfirm:
+			yes, no := "Yes", "No"
+			if field.yes {
+				yes = Cyan + Bold + yes + End
+			} else {
+				no = Cyan + Bold + no + End
+			}
+			value = yes + " / " + no
+		case FormPassword:
+			value = strings.Repeat("•", len(field.st.text))
+		default:
+			value = string(field.st.text)
+			if value == "" && field.Placeholder != "" && !done {
+				value = Faint + field.Placeholder + End
+			}
+		}
+		if focused {
+			cursorRow = len(rows)
+			cursorCol = visibleWidth(prefix)
+			switch field.Kind {
+			case FormPassword:
+				cursorCol += field.st.cursor
+			case FormText, "":
+				cursorCol += runesWidth(field.st.text[:field.st.cursor])
+			}
+		}
+		rows = append(rows, prefix+value)
+		if ac := field.autocomplete(); focused && ac != nil {
+			width, _ := termSize(cfg.out)
+			indent := strings.Repeat(" ", visibleWidth(prefix))
+			for _, line := range ac.Lines(width-len(indent), ac.Max) {
+				rows = append(rows, indent+line)
+			}
+		}
+		if field.err != "" {
+			rows = append(rows, strings.Repeat(" ", visibleWidth(prefix))+cfg.errorStyle+field.err+End)
+		}
+	}
+	if !done {
+		rows = append(rows, Faint+"tab next · shift-tab back · enter submit · esc cancel"+End)
+	}
+
+	var sb strings.Builder
+	if fr.cursorRow > 0 {
+		sb.WriteString(fmt.Sprintf("\033[%dA", fr.cursorRow))
+	}
+	sb.WriteString("\r\033[J" + strings.Join(rows, "\r\n"))
+	if done {
+		sb.WriteString("\r\n")
+		fr.cursorRow = 0
+	} else {
+		if up := len(rows) - 1 - cursorRow; up > 0 {
+			sb.WriteString(fmt.Sprintf("\033[%dA", up))
+		}
+		sb.WriteString(cursorColumn(cursorCol))
+		fr.cursorRow = cursorRow
+	}
+	fmt.Fprint(cfg.out, sb.String())
+}
@@ -0,0 +1,19 @@
+package ansi
+
+import "testing"
+
+func TestFormAutocomplete(t *testing.T) {
+	f := &Form{Fields: []FormField{
+		{Name: "lang", Label: "Language", Autocomplete: NewAutocomplete(wordCompleter([]string{"golang", "gopher", "rust"}, false))},
+		{Name: "name", Label: "Name"},
+	}}
+	// Tab twice selects the second suggestion, Enter accepts it; once the
+	// dropdown is closed, Tab moves to the next field.
+	r, err := f.Run(keys("go\t\t\r\tal\r")...)
+	if err != nil || !r.Submitted {
+		t.Fatalf("Run = %v, %v; want submitted", r, err)
+	}
+	if r.Values["lang"] != "gopher" || r.Values["name"] != "al" {
+		t.Errorf("Values = %v, want lang gopher and name al", r.Values)
+	}
+}
@@ -0,0 +1,124 @@
+package ansi
+
+import (
+	"sort"
+	"unicode"
+)
+
+// --------------------
+// Fuzzy Matching
+// --------------------
+
+// Scores used by fuzzyMatch. Every matched character is worth fuzzyMatchScore,
+// with bonuses for runs of consecutive characters and for characters that start
+// a word, and a penalty for each gap between matched characters.
+const (
+	fuzzyMatchScore  = 16
+	fuzzyConsecutive = 8
+	fuzzyWordStart   = 10
+	fuzzyGap         = 3
+)
+
+// fuzzyMatch reports whether pattern is a subsequence of candidate, ignoring
+// case, and scores the best way of matching it. It also returns the rune
+// positions of the matched characters in candidate, for highlighting.
+func fuzzyMatch(pattern, candidate string) (int, []int, bool) {
+	p := []rune(pattern)
+	c := []rune(candidate)
+	n, m := len(p), len(c)
+	if n == 0 {
+		return 0, nil, true
+	}
+	if n > m {
+		return 0, nil, false
+	}
+	for i := range p {
+		p[i] = unicode.ToLower(p[i])
+	}
+
+	// score[i][j] is the best score matching p[:i+1] with p[i] at c[j], and
+	// prev[i][j] the position p[i-1] was matched at for that score.
+	const none = -1 << 30
+	score := make([][]int, n)
+	prev := make([][]int, n)
+	for i := range p {
+		score[i] = make([]int, m)
+		prev[i] = make([]int, m)
+		best, bestAt := none, -1 // Best score[i-1][k] for k < j-1.
+		for j := 0; j < m; j++ {
+			if i > 0 && j >= 2 && score[i-1][j-2] > best {
+				best, bestAt = score[i-1][j-2], j-2
+			}
+			score[i][j] = none
+			if unicode.ToLower(c[j]) != p[i] {
+				continue
+			}
+			bonus := fuzzyMatchScore
+			if j == 0 || isWordBoundary(c[j-1], c[j]) {
+				bonus += fuzzyWordStart
+			}
+			if i == 0 {
+				score[i][j] = bonus - min(j, fuzzyGap)
+				prev[i][j] = -1
+				continue
+			}
+			if j > 0 && score[i-1][j-1] != none && score[i-1][j-1]+fuzzyConsecutive >= best-fuzzyGap {
+				score[i][j] = score[i-1][j-1] + fuzzyConsecutive + bonus
+				prev[i][j] = j - 1
+			} else if best != none {
+				score[i][j] = best - fuzzyGap + bonus
+				prev[i][j] = bestAt
+			}
+		}
+	}
+
+	end := -1
+	for j := 0; j < m; j++ {
+		if score[n-1][j] != none && (end == -1 || score[n-1][j] > score[n-1][end]) {
+			end = j
+		}
+	}
+	if end == -1 {
+		return 0, nil, false
+	}
+	positions := make([]int, n)
+	for i, j := n-1, end; i >= 0; i-- {
+		positions[i] = j
+		j = prev[i][j]
+	}
+	return score[n-1][end], positions, true
+}
+
+// isWordBoundary reports whether cur starts a word, given the rune before it.
+func isWordBoundary(before, cur rune) bool {
+	if unicode.IsLower(before) && unicode.IsUpper(cur) {
+		return true
+	}
+	return !unicode.IsLetter(before) && !unicode.IsDigit(before)
+}
+
+// fuzzyRank returns the candidates matching pattern, best first. Equal scores
+// are ordered by length and then by their order in candidates.
+func fuzzyRank(pattern string, candidates []string) []string {
+	type ranked struct {
+		text  string
+		score int
+	}
+	var matches []ranked
+	for _, cand := range candidates {
+		if score, _, ok := fuzzyMatch(pattern, cand); ok {
+			matches = append(matches, ranked{cand, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return len(matches[i].text) < len(matches[j].text)
+	})
+	result := make([]string, len(matches))
+	for i, match := range matches {
+		result[i] = match.text
+	}
+	return result
+}
@@ -0,0 +1,167 @@
+package ansi
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// --------------------
+// Fuzzy Select
+// --------------------
+
+// fuzzyOption is an option matching the query of a FuzzySelect.
+type fuzzyOption struct {
+	index     int   // Index in the options.
+	score     int   // Score of the match.
+	positions []int // Rune positions of the matched characters.
+}
+
+// FuzzySelect is Select for long lists: typing filters options to those
+// fuzzily matching the text, best matches first, with the matched characters
+// highlighted. The list scrolls to keep the highlighted option visible. Up
+// and down (or Ctrl-P and Ctrl-N), PageUp and PageDown move the highlight,
+// the usual editing keys change the text and Enter chooses. Escape and
+// Ctrl-C cancel with ErrInterrupted, returning the index of the highlighted
+// option as Select does, or -1 if no option matches. SelectPageSize sets how
+// many options are shown at once, by default 10 or fewer if the terminal is
+// short.
+func FuzzySelect(prompt string, options []string, opts ...InputOption) (int, error) {
+	cfg := &inputConfig{out: os.Stdout, prompt: prompt}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.keys == nil {
+		cfg.keys = defaultKeyReader()
+	}
+	page := cfg.pageSize
+	if page <= 0 {
+		_, height := termSize(cfg.out)
+		page = max(min(10, height-2), 1)
+	}
+
+	st := &inputState{cfg: cfg, histPos: -1}
+	index, top := 0, 0
+	query := ""
+	matches := fuzzyFilter(query, options)
+	for {
+		index = max(min(index, len(matches)-1), 0)
+		top = max(min(top, index), index-page+1)
+		drawFuzzySelect(st, options, matches, index, top, page)
+		highlighted := -1
+		if len(matches) > 0 {
+			highlighted = matches[index].index
+		}
+
+		keyType, key, err := cfg.keys.readKey()
+		if err != nil {
+			fmt.Fprintln(cfg.out)
+			return highlighted, err
+		}
+		switch {
+		case keyType == "Arrow" && key == "up", keyType == "Special" && key == "ctrl-p":
+			index--
+		case keyType == "Arrow" && key == "down", keyType == "Special" && key == "ctrl-n":
+			index++
+		case keyType == "Special" && key == "pageup":
+			index -= page
+		case keyType == "Special" && key == "pagedown":
+			index += page
+		case keyType == "Special" && key == "enter" && len(matches) > 0:
+			fmt.Fprintf(cfg.out, "\r\033[J%s %s\n", st.promptText(), Cyan+options[highlighted]+End)
+			return highlighted, nil
+		case keyType == "Special" && (key == "escape" || key == "ctrl-c"):
+			fmt.Fprint(cfg.out, "\r\033[J"+st.promptText()+"\n")
+			if key == "escape" && cfg.escapeBack {
+				return highlighted, errBack
+			}
+			return highlighted, ErrInterrupted
+		default:
+			st.handleEditKey(keyType, key)
+			if string(st.text) != query {
+				query = string(st.text)
+				matches = fuzzyFilter(query, options)
+				index, top = 0, 0
+			}
+		}
+	}
+}
+
+// fuzzyFilter returns the options matching query, best first. With an empty
+// query all options are returned in their order.
+func fuzzyFilter(query string, options []string) []fuzzyOption {
+	var matches []fuzzyOption
+	for i, option := range options {
+		if score, positions, ok := fuzzyMatch(query, option); ok {
+			matches = append(matches, fuzzyOption{i, score, positions})
+		}
+	}
+	if query != "" {
+		sort.SliceStable(matches, func(i, j int) bool {
+			if matches[i].score != matches[j].score {
+				return matches[i].score > matches[j].score
+			}
+			return len(options[matches[i].index]) < len(options[matches[j].index])
+		})
+	}
+	return matches
+}
+
+// promptText returns the prompt in its style.
+func (st *inputState) promptText() string {
+	if st.cfg.promptStyle != "" {
+		return st.cfg.promptStyle + st.cfg.prompt + End
+	}
+	return st.cfg.prompt
+}
+
+// drawFuzzySelect redraws the query line and up to page matches of the list
+// below it, from match top, highlighting match index.
+func drawFuzzySelect(st *inputState, options []string, matches []fuzzyOption, index, top, page int) {
+	cfg := st.cfg
+	var sb strings.Builder
+	sb.WriteString("\r\033[J" + st.promptText() + " " + string(st.text))
+	if len(st.text) == 0 && cfg.placeholder != "" {
+		sb.WriteString(Faint + cfg.placeholder + End)
+	}
+	width, _ := termSize(cfg.out)
+	end := min(top+page, len(matches))
+	var lines []string
+	for i := top; i < end; i++ {
+		m := matches[i]
+		text := highlightRunes(options[m.index], m.positions, Bold+Underline)
+		if i == index {
+			text = Cyan + "> " + End + text
+		} else {
+			text = "  " + text
+		}
+		lines = append(lines, truncateWidth(text, width))
+	}
+	for _, line := range cfg.listScrollbar(lines, len(matches), end-top, top) {
+		sb.WriteString("\r\n" + line)
+	}
+	sb.WriteString(fmt.Sprintf("\r\n%s%d/%d%s", Faint, len(matches), len(options), End))
+	sb.WriteString(fmt.Sprintf("\033[%dA", end-top+1))
+	sb.WriteString(cursorColumn(st.textColumn(st.cursor)))
+	fmt.Fprint(cfg.out, sb.String())
+}
+
+// highlightRunes returns s with the runes at positions, which are in
+// increasing order, shown in style.
+func highlightRunes(s string, positions []int, style string) string {
+	if len(positions) == 0 {
+		return s
+	}
+	var sb strings.Builder
+	p := 0
+	for i, r := range []rune(s) {
+		if p < len(positions) && positions[p] == i {
+			sb.WriteString(style + string(r) + End)
+			p++
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
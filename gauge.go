@@ -0,0 +1,127 @@
+package ansi
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// --------------------
+// Gauge
+// --------------------
+
+// GaugeThreshold colors a Gauge with Style from the percentage At upwards.
+type GaugeThreshold struct {
+	At    float64
+	Style string
+}
+
+// GaugeLevels are the default thresholds of a Gauge: green, then yellow from
+// 70% and red from 90%.
+var GaugeLevels = []GaugeThreshold{{0, Green}, {70, Yellow}, {90, Red}}
+
+// Gauge shows a percentage, like the CPU or memory use, as a bar of blocks
+// or as a half-circle arc, colored by thresholds.
+type Gauge struct {
+	Label      string
+	Percent    float64          // From 0 to 100.
+	Thresholds []GaugeThreshold // In increasing order. GaugeLevels if not set with NewGauge.
+	Arc        bool             // Whether the gauge is an arc rather than a bar.
+	Width      int              // Width of String; 0 uses 40 columns, or 20 for an arc.
+}
+
+// NewGauge creates a bar gauge of percent with the GaugeLevels thresholds.
+func NewGauge(label string, percent float64) *Gauge {
+	return &Gauge{Label: label, Percent: percent, Thresholds: GaugeLevels}
+}
+
+// Style returns the style of the threshold the percentage has reached.
+func (g *Gauge) Style() string {
+	style := ""
+	for _, t := range g.Thresholds {
+		if g.Percent >= t.At {
+			style = t.Style
+		}
+	}
+	return style
+}
+
+// Print prints the gauge to stdout.
+func (g *Gauge) Print() {
+	fmt.Print(g.String())
+}
+
+// String renders the gauge in Width, ending with a newline.
+func (g *Gauge) String() string {
+	width := g.Width
+	if width <= 0 {
+		width = 40
+		if g.Arc {
+			width = 20
+		}
+	}
+	height := 1
+	if g.Arc {
+		height = width/4 + 1
+	}
+	return strings.Join(g.Lines(width, height), "\n") + "\n"
+}
+
+// Lines returns the gauge fitted in width columns and height rows, for
+// drawing it in a Pane, GridCell or Tab. A bar takes one row; an arc is as
+// high as it can be in height less the row of its label, and twice as wide
+// as high in dots.
+func (g *Gauge) Lines(width, height int) []string {
+	if width <= 0 || height <= 0 {
+		return nil
+	}
+	fraction := min(max(g.Percent/100, 0), 1)
+	if math.IsNaN(fraction) {
+		// As from used/total with a total of 0.
+		fraction = 0
+	}
+	percent := fmt.Sprintf("%3.0f%%", fraction*100)
+	if !g.Arc || height < 2 || width < 4 {
+		label := ""
+		if g.Label != "" {
+			label = g.Label + " "
+		}
+		area := max(width-visibleWidth(label)-len(percent)-1, 1)
+		cells := fraction * float64(area)
+		bar := hbar(cells)
+		bar = g.Style() + bar + End + Faint + strings.Repeat("░", max(area-visibleWidth(bar), 0)) + End
+		return []string{truncateWidth(label+bar+" "+g.Style()+percent+End, width)}
+	}
+
+	rows := min(height-1, width/4)
+	radius := float64(rows * 4)
+	inner := radius * 0.6
+	canvas := newBrailleCanvas(rows*4, rows)
+	for pass, style := range []string{Faint, g.Style()} {
+		for y := range rows * 4 {
+			for x := range rows * 8 {
+				// Distance and angle of the dot from the middle of the bottom edge.
+				dx, dy := float64(x)-radius+0.5, radius-float64(y)-0.5
+				d := math.Hypot(dx, dy)
+				if d > radius || d < inner {
+					continue
+				}
+				filled := 1-math.Atan2(dy, dx)/math.Pi <= fraction
+				if pass == 0 || filled {
+					canvas.set(x, y, style)
+				}
+			}
+		}
+	}
+	lines := canvas.lines()
+	label := g.Style() + strings.TrimSpace(percent) + End
+	if g.Label != "" {
+		label += " " + g.Label
+	}
+	lines = append(lines, centerText(truncateWidth(label, rows*4), rows*4))
+	pad := strings.Repeat(" ", (width-rows*4)/2)
+	for i, line := range lines {
+		lines[i] = pad + line
+	}
+	return lines
+}
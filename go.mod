@@ -4,7 +4,6 @@ go 1.24.1
 
 require (
 	github.com/eiannone/keyboard v0.0.0-20220611211555-0d226195f203
+	golang.org/x/sys v0.32.0
 	golang.org/x/term v0.31.0
 )
-
-require golang.org/x/sys v0.32.0 // indirect
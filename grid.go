@@ -0,0 +1,103 @@
+package ansi
+
+import "strings"
+
+// --------------------
+// Grid
+// --------------------
+
+// GridCell is a region of a Grid covering one or more rows and columns.
+type GridCell struct {
+	Row, Col         int // First row and column, counted from 0.
+	RowSpan, ColSpan int // Rows and columns covered. 0 counts as 1.
+	Render           func(width, height int) []string
+
+	rect Rect
+}
+
+// Rect returns where the cell was placed by the last layout.
+func (c *GridCell) Rect() Rect {
+	return c.rect
+}
+
+// Grid divides a rectangle of the screen into rows and columns sized like
+// the panes of a Split, and places cells in them.
+type Grid struct {
+	Rows, Columns []PaneSize
+	Gutter        int // Blank columns between columns.
+	RowGutter     int // Blank rows between rows.
+	Cells         []*GridCell
+}
+
+// NewGrid creates a grid of rows and columns of equal flexible size, with
+// one blank column between columns.
+func NewGrid(rows, columns int) *Grid {
+	return &Grid{
+		Rows:    make([]PaneSize, rows),
+		Columns: make([]PaneSize, columns),
+		Gutter:  1,
+	}
+}
+
+// Add adds a cell at row and col covering rowSpan rows and colSpan columns.
+func (g *Grid) Add(row, col, rowSpan, colSpan int, render func(width, height int) []string) *GridCell {
+	cell := &GridCell{Row: row, Col: col, RowSpan: rowSpan, ColSpan: colSpan, Render: render}
+	g.Cells = append(g.Cells, cell)
+	return cell
+}
+
+// gridTracks returns the start and size of each track of sizes in length
+// cells, with gutter cells between tracks.
+func gridTracks(sizes []PaneSize, length, gutter int) (starts, lengths []int) {
+	n := len(sizes)
+	if n == 0 {
+		return nil, nil
+	}
+	lengths = trackSizes(sizes, max(length-(n-1)*gutter, 0))
+	starts = make([]int, n)
+	for i := 1; i < n; i++ {
+		starts[i] = starts[i-1] + lengths[i-1] + gutter
+	}
+	return starts, lengths
+}
+
+// gridSpan returns the offset and length of the span of count tracks from
+// first, cut to the tracks there are.
+func gridSpan(starts, lengths []int, first, count int) (int, int) {
+	if first < 0 || first >= len(starts) {
+		return 0, 0
+	}
+	last := min(first+max(count, 1), len(starts)) - 1
+	return starts[first], starts[last] + lengths[last] - starts[first]
+}
+
+// Layout places the cells in r. Cells outside the grid get an empty
+// rectangle, and spans past its edge are cut.
+func (g *Grid) Layout(r Rect) {
+	rowStarts, rowLengths := gridTracks(g.Rows, r.Height, g.RowGutter)
+	colStarts, colLengths := gridTracks(g.Columns, r.Width, g.Gutter)
+	for _, cell := range g.Cells {
+		row, height := gridSpan(rowStarts, rowLengths, cell.Row, cell.RowSpan)
+		col, width := gridSpan(colStarts, colLengths, cell.Col, cell.ColSpan)
+		cell.rect = Rect{r.Row + row, r.Col + col, width, height}
+	}
+}
+
+// Render returns the escape sequences drawing every cell.
+func (g *Grid) Render() string {
+	var sb strings.Builder
+	for _, cell := range g.Cells {
+		sb.WriteString(renderRect(cell.rect, cell.Render))
+	}
+	return sb.String()
+}
+
+// Run shows the grid filling the alternate screen until q, Escape or Ctrl-C
+// is pressed, laying it out again when the terminal is resized.
+// InputWriter and InputKeyReader may be given in opts.
+func (g *Grid) Run(opts ...InputOption) error {
+	return runScreen(opts, false, func(r Rect) string {
+		g.Layout(r)
+		return g.Render()
+	}, func(keyType, key string) {})
+}
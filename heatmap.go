@@ -0,0 +1,176 @@
+package ansi
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strings"
+)
+
+// --------------------
+// Heatmap
+// --------------------
+
+// HeatScale is a scale of colors from the lowest value to the highest,
+// blended between its stops.
+type HeatScale []Color
+
+// Scales for a Heatmap.
+var (
+	HeatGreen   = HeatScale{RGB(22, 27, 34), RGB(14, 68, 41), RGB(0, 109, 50), RGB(38, 166, 65), RGB(57, 211, 83)}
+	HeatFire    = HeatScale{RGB(20, 20, 20), RGB(160, 20, 10), RGB(240, 120, 0), RGB(255, 230, 80), RGB(255, 255, 230)}
+	HeatBlueRed = HeatScale{RGB(40, 80, 200), RGB(240, 240, 240), RGB(210, 40, 40)}
+)
+
+// At returns the color a fraction t of the way along the scale.
+func (s HeatScale) At(t float64) Color {
+	if len(s) == 0 {
+		return Color{}
+	}
+	t = min(max(t, 0), 1) * float64(len(s)-1)
+	i := min(int(t), len(s)-2)
+	if i < 0 {
+		return s[0]
+	}
+	return s[i].Blend(s[i+1], t-float64(i))
+}
+
+// Heatmap shows a grid of values as cells whose background color follows
+// a HeatScale, with labels for the rows and columns, like an activity
+// calendar or a latency matrix.
+type Heatmap struct {
+	Values     [][]float64 // Rows of values. NaN values are left blank.
+	RowLabels  []string
+	ColLabels  []string  // Shown above the columns where there is room.
+	Scale      HeatScale // HeatGreen if not set with NewHeatmap.
+	CellWidth  int       // Columns of each cell. The default is 2.
+	ShowValues bool      // Whether values are written in the cells.
+	Legend     bool      // Whether a line shows the scale from the lowest to the highest value.
+
+	min, max *float64
+}
+
+// NewHeatmap creates a heatmap of rows of values with the HeatGreen scale.
+func NewHeatmap(values [][]float64) *Heatmap {
+	return &Heatmap{Values: values, Scale: HeatGreen, CellWidth: 2}
+}
+
+// SetRange fixes the values at the ends of the scale instead of fitting it
+// to the values.
+func (h *Heatmap) SetRange(min, max float64) {
+	h.min, h.max = &min, &max
+}
+
+// bounds returns the values at the ends of the scale.
+func (h *Heatmap) bounds() (float64, float64) {
+	if h.min != nil {
+		return *h.min, *h.max
+	}
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, row := range h.Values {
+		for _, v := range row {
+			if !math.IsNaN(v) {
+				lo, hi = min(lo, v), max(hi, v)
+			}
+		}
+	}
+	if math.IsInf(lo, 1) {
+		return 0, 0
+	}
+	return lo, hi
+}
+
+// Print prints the heatmap to stdout.
+func (h *Heatmap) Print() {
+	fmt.Print(h.String())
+}
+
+// String renders the heatmap in the width of the terminal, ending with a
+// newline.
+func (h *Heatmap) String() string {
+	width, _ := termSize(os.Stdout)
+	return strings.Join(h.Lines(width, math.MaxInt), "\n") + "\n"
+}
+
+// Lines returns the heatmap cut to width columns and height rows, for
+// drawing it in a Pane, GridCell or Tab.
+func (h *Heatmap) Lines(width, height int) []string {
+	cellWidth := max(h.CellWidth, 1)
+	labelWidth := 0
+	for _, label := range h.RowLabels {
+		labelWidth = max(labelWidth, visibleWidth(label))
+	}
+	if labelWidth > 0 {
+		labelWidth++
+	}
+	cols := 0
+	for _, row := range h.Values {
+		cols = max(cols, len(row))
+	}
+	lo, hi := h.bounds()
+	color := func(v float64) Color {
+		if hi == lo {
+			return h.Scale.At(1)
+		}
+		return h.Scale.At((v - lo) / (hi - lo))
+	}
+
+	var lines []string
+	if len(h.ColLabels) > 0 {
+		// Each label starts above its column if it does not run into the last.
+		var sb strings.Builder
+		sb.WriteString(strings.Repeat(" ", labelWidth))
+		used := 0
+		for i, label := range h.ColLabels[:min(len(h.ColLabels), cols)] {
+			at := i * cellWidth
+			if label == "" || at < used {
+				continue
+			}
+			sb.WriteString(strings.Repeat(" ", at-used) + Faint + label + End)
+			used = at + visibleWidth(label) + 1
+			sb.WriteByte(' ')
+		}
+		lines = append(lines, sb.String())
+	}
+	for i, row := range h.Values {
+		var sb strings.Builder
+		label := ""
+		if i < len(h.RowLabels) {
+			label = h.RowLabels[i]
+		}
+		sb.WriteString(Faint + label + End + strings.Repeat(" ", labelWidth-visibleWidth(label)))
+		for _, v := range row {
+			if math.IsNaN(v) {
+				sb.WriteString(strings.Repeat(" ", cellWidth))
+				continue
+			}
+			c := color(v)
+			if !h.ShowValues {
+				sb.WriteString(c.Bg() + strings.Repeat(" ", cellWidth) + End)
+				continue
+			}
+			// Write the value in black or white, whichever stands out more.
+			fg := RGB(255, 255, 255)
+			if c.luminance() > 0.5 {
+				fg = RGB(0, 0, 0)
+			}
+			text := truncateWidth(FormatCount(v), cellWidth)
+			sb.WriteString(c.Bg() + fg.Fg() + strings.Repeat(" ", cellWidth-visibleWidth(text)) + text + End)
+		}
+		lines = append(lines, sb.String())
+	}
+	if h.Legend {
+		var sb strings.Builder
+		sb.WriteString(strings.Repeat(" ", labelWidth) + Faint + FormatCount(lo) + End + " ")
+		for i := range 5 {
+			sb.WriteString(h.Scale.At(float64(i)/4).Bg() + strings.Repeat(" ", cellWidth) + End)
+		}
+		sb.WriteString(" " + Faint + FormatCount(hi) + End)
+		lines = append(lines, sb.String())
+	}
+	lines = lines[:min(len(lines), height)]
+	for i, line := range lines {
+		lines[i] = truncateWidth(line, width)
+	}
+	return lines
+}
@@ -0,0 +1,308 @@
+package ansi
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// --------------------
+// Hex Viewer
+// --------------------
+
+// hexMark is a range of bytes shown in a style by a HexView.
+type hexMark struct {
+	offset, length int
+	style          string
+}
+
+// HexView shows binary data as rows of an offset, the bytes in hex and the
+// same bytes as ASCII, like hexdump -C, with a cursor on one byte. It is not
+// safe for use from several goroutines.
+type HexView struct {
+	BytesPerRow int    // Bytes shown in each row. If 0, as many multiples of 8 as fit.
+	CursorStyle string // Style of the byte under the cursor. The default is Negative.
+
+	data    []byte
+	marks   []hexMark
+	cursor  int    // Offset of the byte under the cursor.
+	top     int    // First row shown.
+	perRow  int    // Bytes per row of the last Lines.
+	page    int    // Rows shown by the last Lines.
+	typing  bool   // Whether an offset to go to is being typed.
+	offset  string // The offset typed.
+	message string // Shown in the status line until the next key.
+}
+
+// NewHexView creates a view of data.
+func NewHexView(data []byte) *HexView {
+	return &HexView{CursorStyle: Negative, data: data, perRow: 16}
+}
+
+// SetData replaces the data shown, keeping the cursor where possible.
+func (h *HexView) SetData(data []byte) {
+	h.data = data
+	h.SetCursor(h.cursor)
+}
+
+// Highlight shows length bytes from offset in style, for example to mark
+// the fields of a packet header. Later highlights win where they overlap.
+func (h *HexView) Highlight(offset, length int, style string) {
+	h.marks = append(h.marks, hexMark{offset, length, style})
+}
+
+// ClearHighlights removes every highlight.
+func (h *HexView) ClearHighlights() {
+	h.marks = nil
+}
+
+// Cursor returns the offset of the byte under the cursor.
+func (h *HexView) Cursor() int {
+	return h.cursor
+}
+
+// SetCursor moves the cursor to the byte at offset, or to the nearest byte
+// there is, scrolling to show it.
+func (h *HexView) SetCursor(offset int) {
+	h.cursor = max(min(offset, len(h.data)-1), 0)
+	h.scroll()
+}
+
+// scroll scrolls as little as needed for the row of the cursor to be shown.
+func (h *HexView) scroll() {
+	row, page := h.cursor/max(h.perRow, 1), max(h.page, 1)
+	if row < h.top {
+		h.top = row
+	}
+	if row >= h.top+page {
+		h.top = row - page + 1
+	}
+}
+
+// HandleKey moves the cursor with the arrow keys (or h, j, k and l),
+// PageUp and PageDown, Home and End (to the ends of the row), and g and G
+// (to the ends of the data). : or o starts typing an offset to go to, in
+// decimal or, after 0x, in hex, which Enter goes to and Escape cancels. It
+// reports whether the key was used.
+func (h *HexView) HandleKey(keyType, key string) bool {
+	h.message = ""
+	if h.typing {
+		switch {
+		case keyType == "Special" && key == "enter":
+			h.typing = false
+			h.gotoOffset(h.offset)
+		case keyType == "Special" && (key == "escape" || key == "ctrl-c"):
+			h.typing = false
+		case keyType == "Special" && key == "backspace":
+			if h.offset == "" {
+				h.typing = false
+			}
+			h.offset = h.offset[:max(len(h.offset)-1, 0)]
+		case keyType == "Character", keyType == "Paste":
+			h.offset += strings.TrimSpace(key)
+		default:
+			return false
+		}
+		return true
+	}
+
+	row := max(h.perRow, 1)
+	page := max(h.page-1, 1) * row
+	switch {
+	case keyType == "Arrow" && key == "left", keyType == "Character" && key == "h":
+		h.SetCursor(h.cursor - 1)
+	case keyType == "Arrow" && key == "right", keyType == "Character" && key == "l":
+		h.SetCursor(h.cursor + 1)
+	case keyType == "Arrow" && key == "up", keyType == "Character" && key == "k":
+		h.SetCursor(h.cursor - row)
+	case keyType == "Arrow" && key == "down", keyType == "Character" && key == "j":
+		h.SetCursor(h.cursor + row)
+	case keyType == "Special" && key == "pageup", keyType == "Character" && key == "b":
+		h.SetCursor(h.cursor - page)
+	case keyType == "Special" && key == "pagedown", keyType == "Character" && key == " ":
+		h.SetCursor(h.cursor + page)
+	case keyType == "Special" && key == "home":
+		h.SetCursor(h.cursor - h.cursor%row)
+	case keyType == "Special" && key == "end":
+		h.SetCursor(h.cursor - h.cursor%row + row - 1)
+	case keyType == "Character" && key == "g":
+		h.SetCursor(0)
+	case keyType == "Character" && key == "G":
+		h.SetCursor(len(h.data) - 1)
+	case keyType == "Character" && (key == ":" || key == "o"):
+		h.typing, h.offset = true, ""
+	default:
+		return false
+	}
+	return true
+}
+
+// gotoOffset moves the cursor to the offset s, or sets the message if it is
+// not a valid offset.
+func (h *HexView) gotoOffset(s string) {
+	if s == "" {
+		return
+	}
+	n, err := strconv.ParseInt(s, 0, 64)
+	switch {
+	case err != nil:
+		h.message = "Not an offset: " + s
+	case n < 0 || n >= int64(len(h.data)):
+		h.message = fmt.Sprintf("Offset out of range: %s", s)
+	default:
+		h.SetCursor(int(n))
+	}
+}
+
+// style returns the style of the byte at offset, besides the cursor.
+func (h *HexView) style(offset int) string {
+	for i := len(h.marks) - 1; i >= 0; i-- {
+		m := h.marks[i]
+		if offset >= m.offset && offset < m.offset+m.length {
+			return m.style
+		}
+	}
+	return ""
+}
+
+// digits returns the number of hex digits of the offsets, at least 8.
+func (h *HexView) digits() int {
+	return max(len(strconv.FormatInt(int64(len(h.data)), 16)), 8)
+}
+
+// rowWidth returns the width of a row of n bytes.
+func (h *HexView) rowWidth(n int) int {
+	return h.digits() + 2 + n*3 + (n-1)/8 + 1 + n + 2
+}
+
+// row returns the row of bytes starting at offset, n bytes long, with the
+// cursor shown if cursor is set.
+func (h *HexView) row(offset, n int, cursor bool) string {
+	var hex, text strings.Builder
+	for i := range n {
+		if i > 0 && i%8 == 0 {
+			hex.WriteString(" ")
+		}
+		at := offset + i
+		if at >= len(h.data) {
+			hex.WriteString("   ")
+			continue
+		}
+		b := h.data[at]
+		style := h.style(at)
+		if cursor && at == h.cursor {
+			style += h.CursorStyle
+		}
+		c := string(rune(b))
+		if b < 0x20 || b > 0x7e {
+			c = "."
+			if style == "" {
+				style = Faint
+			}
+		}
+		if style != "" {
+			hex.WriteString(style + fmt.Sprintf("%02x", b) + End + " ")
+			text.WriteString(style + c + End)
+		} else {
+			hex.WriteString(fmt.Sprintf("%02x ", b))
+			text.WriteString(c)
+		}
+	}
+	return fmt.Sprintf("%s%0*x%s  %s |%s|", Faint, h.digits(), offset, End, hex.String(), text.String())
+}
+
+// Lines returns the rows of the view in width columns and height rows, and
+// a status line with the offset and value of the byte under the cursor, or
+// the offset being typed.
+func (h *HexView) Lines(width, height int) []string {
+	if width <= 0 || height <= 0 {
+		return nil
+	}
+	h.perRow = h.BytesPerRow
+	if h.perRow <= 0 {
+		h.perRow = 8
+		for h.rowWidth(h.perRow+8) <= width {
+			h.perRow += 8
+		}
+	}
+	h.page = max(height-1, 1)
+	rows := (len(h.data) + h.perRow - 1) / h.perRow
+	h.scroll()
+	h.top = max(min(h.top, rows-h.page), 0)
+
+	var lines []string
+	for r := h.top; r < h.top+h.page && r < rows && len(lines) < height-1; r++ {
+		lines = append(lines, truncateWidth(h.row(r*h.perRow, h.perRow, true), width))
+	}
+	for len(lines) < height-1 {
+		lines = append(lines, "")
+	}
+
+	var status string
+	switch {
+	case h.typing:
+		status = "Go to offset: " + h.offset + "█"
+	case h.message != "":
+		status = h.message
+	case len(h.data) == 0:
+		status = "empty"
+	default:
+		b := h.data[h.cursor]
+		status = fmt.Sprintf("offset 0x%x (%d) · byte 0x%02x (%d) · %s", h.cursor, h.cursor, b, b, FormatBytes(float64(len(h.data))))
+	}
+	return append(lines, Negative+padWidth(truncateWidth(" "+status, width), width)+End)
+}
+
+// Run shows the view filling the alternate screen until q, Escape or Ctrl-C
+// is pressed outside of typing an offset. InputWriter and InputKeyReader may
+// be given in opts.
+func (h *HexView) Run(opts ...InputOption) error {
+	cfg := &inputConfig{out: os.Stdout}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.keys == nil {
+		cfg.keys = defaultKeyReader()
+	}
+	fmt.Fprint(cfg.out, "\033[?1049h\033[?25l\033[2J")
+	defer fmt.Fprint(cfg.out, "\033[?25h\033[?1049l")
+	for {
+		width, height := termSize(cfg.out)
+		fmt.Fprint(cfg.out, renderRect(Rect{1, 1, width, height}, h.Lines))
+		keyType, key, err := cfg.keys.readKey()
+		if err != nil {
+			return err
+		}
+		if !h.typing && (keyType == "Character" && key == "q" || keyType == "Special" && (key == "escape" || key == "ctrl-c")) {
+			return nil
+		}
+		h.HandleKey(keyType, key)
+	}
+}
+
+// HexDump returns data as rows of 16 bytes, each with its offset, the bytes
+// in hex and the same bytes as ASCII, like hexdump -C.
+func HexDump(data []byte) string {
+	h := NewHexView(data)
+	var sb strings.Builder
+	for offset := 0; offset < len(data); offset += 16 {
+		sb.WriteString(stripANSI(h.row(offset, 16, false)) + "\n")
+	}
+	return sb.String()
+}
+
+// ViewHex shows data in a HexView filling the alternate screen, as
+// HexView.Run does. If the output is not a terminal HexDump(data) is written
+// to it instead. InputWriter and InputKeyReader may be given in opts.
+func ViewHex(data []byte, opts ...InputOption) error {
+	cfg := &inputConfig{out: os.Stdout}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if !isTerminal(cfg.out) {
+		_, err := fmt.Fprint(cfg.out, HexDump(data))
+		return err
+	}
+	return NewHexView(data).Run(opts...)
+}
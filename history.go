@@ -0,0 +1,154 @@
+package ansi
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// --------------------
+// History
+// --------------------
+
+// History holds previously entered lines for the Input prompt, where up/down
+// cycles through them. If Path is set every added line is also written to that
+// file, so history survives between runs like readline's ~/.history.
+type History struct {
+	Path   string // File the history is saved to; empty keeps it in memory only.
+	Max    int    // Maximum number of entries kept; 0 means unlimited.
+	Dedupe bool   // Remove older copies of a line when it is added again.
+
+	entries []string
+	lines   int // Lines in the file at Path, including entries dropped since.
+	mu      sync.Mutex
+}
+
+// NewHistory creates a History with deduplication enabled, keeping at most max
+// entries. If path is not empty, existing entries are loaded from it; a
+// leading "~/" is expanded to the home directory. A missing file is not an error.
+func NewHistory(path string, max int) (*History, error) {
+	h := &History{Path: expandHome(path), Max: max, Dedupe: true}
+	if h.Path == "" {
+		return h, nil
+	}
+	f, err := os.Open(h.Path)
+	if os.IsNotExist(err) {
+		return h, nil
+	} else if err != nil {
+		return h, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		h.lines++
+		if line := scanner.Text(); line != "" {
+			h.add(line)
+		}
+	}
+	return h, scanner.Err()
+}
+
+// Add appends a line to the history and, if Path is set, to its file. Empty
+// lines and repeats of the most recent entry are ignored. Entries dropped by
+// Max and Dedupe stay in the file until it holds twice as many lines as the
+// history has entries, when it is rewritten without them.
+func (h *History) Add(line string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if strings.TrimSpace(line) == "" {
+		return nil
+	}
+	if n := len(h.entries); n > 0 && h.entries[n-1] == line {
+		return nil
+	}
+	h.add(line)
+	if h.Path == "" {
+		return nil
+	}
+	if h.lines+1 > 2*len(h.entries) {
+		return h.save()
+	}
+	return h.append(line)
+}
+
+// add appends line, applying Dedupe and Max.
+func (h *History) add(line string) {
+	if h.Dedupe {
+		for i, entry := range h.entries {
+			if entry == line {
+				h.entries = append(h.entries[:i], h.entries[i+1:]...)
+				break
+			}
+		}
+	}
+	h.entries = append(h.entries, line)
+	if h.Max > 0 && len(h.entries) > h.Max {
+		h.entries = h.entries[len(h.entries)-h.Max:]
+	}
+}
+
+// Entries returns a copy of the history, oldest first.
+func (h *History) Entries() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.entries...)
+}
+
+// Save writes the history to Path.
+func (h *History) Save() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.save()
+}
+
+// save writes the history to Path, if set.
+func (h *History) save() error {
+	if h.Path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(h.Path), 0o755); err != nil {
+		return err
+	}
+	data := strings.Join(h.entries, "\n")
+	if data != "" {
+		data += "\n"
+	}
+	if err := os.WriteFile(h.Path, []byte(data), 0o600); err != nil {
+		return err
+	}
+	h.lines = len(h.entries)
+	return nil
+}
+
+// append writes line at the end of the file at Path.
+func (h *History) append(line string) error {
+	if err := os.MkdirAll(filepath.Dir(h.Path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(h.Path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = f.WriteString(line + "\n")
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		h.lines++
+	}
+	return err
+}
+
+// expandHome replaces a leading "~" in path with the user's home directory.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
@@ -0,0 +1,44 @@
+package ansi
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestHistoryAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	h, err := NewHistory(path, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"a", "b", "c", "a", "d", "e"} {
+		if err := h.Add(line); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Lines are appended, leaving the dropped entries in the file.
+	if data, _ := os.ReadFile(path); string(data) != "a\nb\nc\na\nd\ne\n" {
+		t.Errorf("file = %q, want every added line", data)
+	}
+	want := []string{"a", "d", "e"}
+	if got := h.Entries(); !slices.Equal(got, want) {
+		t.Errorf("Entries() = %q, want %q", got, want)
+	}
+	reloaded, err := NewHistory(path, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := reloaded.Entries(); !slices.Equal(got, want) {
+		t.Errorf("reloaded Entries() = %q, want %q", got, want)
+	}
+
+	// The file is compacted once it holds twice as many lines as entries.
+	if err := h.Add("f"); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "d\ne\nf\n" {
+		t.Errorf("file = %q after compacting, want %q", data, "d\ne\nf\n")
+	}
+}
@@ -0,0 +1,582 @@
+package ansi
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// --------------------
+// Input with Autocompletion
+// --------------------
+
+// InputOption configures an Input prompt.
+type InputOption func(*inputConfig)
+
+// inputConfig holds the settings of an Input prompt.
+type inputConfig struct {
+	prompt      string
+	promptStyle string
+	completions []string
+	completer   Completer
+	fuzzy       bool
+	dropdown    int
+	validate    func(string) error
+	liveCheck   bool
+	errorStyle  string
+	masked      bool
+	mask        rune
+	maskToggle  bool
+	escapeBack  bool
+	colorizer   func(string) string
+	placeholder string
+	defaultText string
+	multiline   bool
+	terminator  string
+	number      string   // "int" or "float" for numeric prompts.
+	min, max    *float64 // Bounds of numeric prompts.
+	step        float64
+	durationMin time.Duration // Shortest duration PickDuration accepts.
+	durationMax time.Duration // Longest duration PickDuration accepts, or 0 for the default.
+	checked     string        // Checkbox of chosen MultiSelect options.
+	unchecked   string        // Checkbox of other MultiSelect options.
+	selectMin   int           // Fewest options MultiSelect accepts.
+	selectMax   int           // Most options MultiSelect accepts, or 0 for all.
+	pageSize    int           // Options shown at once by Select and the like.
+	backdrop    func() string // Redraws the screen below a dialog.
+	history     *History
+	historyErr  func(error) // Receives errors saving the history.
+	editMode    string
+	out         io.Writer
+	keys        *KeyReader
+}
+
+// inputState is the editing state of a running Input prompt.
+type inputState struct {
+	cfg       *inputConfig
+	text      []rune
+	cursor    int    // Position of the cursor in text.
+	killed    []rune // Text removed by the last kill command, inserted again by Ctrl-Y.
+	errMsg    string
+	done      bool
+	cancel    bool   // Whether the prompt was cancelled with Ctrl-C or Escape.
+	cancelKey string // The key that cancelled the prompt.
+	revealed  bool   // Whether masked text is shown, toggled with Ctrl-T.
+
+	histPos int    // Index into the history entries, or -1 when editing a new line.
+	draft   []rune // The new line, kept while browsing the history.
+
+	searching   bool   // Whether reverse-i-search (Ctrl-R) is active.
+	searchQuery []rune // The text being searched for.
+	searchPos   int    // Index of the current match, or -1 if nothing matches.
+	searchOrig  []rune // The line before the search started, restored by Ctrl-G.
+
+	complete *Autocomplete // Suggestions and dropdown, created on first use.
+
+	cycle      []Suggestion // Suggestions being cycled through with Tab, or nil.
+	cycleIndex int          // Index of the suggestion currently inserted.
+	cycleStart int          // Start of the word the suggestions replace.
+
+	cursorRow int // Screen row of the cursor below the first row, in multi-line mode.
+
+	viMode bool    // Whether vi key bindings are used.
+	vi     viState // The vi mode state, when viMode is set.
+}
+
+// InputPrompt sets the text shown before the input.
+func InputPrompt(prompt string) InputOption {
+	return func(c *inputConfig) {
+		c.prompt = prompt
+	}
+}
+
+// InputPromptStyle sets the style of the prompt text, e.g. Bold + Cyan.
+func InputPromptStyle(style string) InputOption {
+	return func(c *inputConfig) {
+		c.promptStyle = style
+	}
+}
+
+// InputColorizer sets a function that styles the typed line as it is drawn,
+// for live syntax highlighting of flags, quotes, numbers and so on. It must
+// return the same text with only escape sequences added. It replaces the
+// default coloring of words against the completions.
+func InputColorizer(colorize func(line string) string) InputOption {
+	return func(c *inputConfig) {
+		c.colorizer = colorize
+	}
+}
+
+// InputPlaceholder sets a hint shown in Faint while the input is empty.
+func InputPlaceholder(placeholder string) InputOption {
+	return func(c *inputConfig) {
+		c.placeholder = placeholder
+	}
+}
+
+// InputDefault pre-fills the input with text that can be edited or accepted
+// as it is.
+func InputDefault(text string) InputOption {
+	return func(c *inputConfig) {
+		c.defaultText = text
+	}
+}
+
+// InputCompletions sets the words offered as autocomplete suggestions. Typed
+// words are colored green when they match a completion and red otherwise.
+func InputCompletions(completions []string) InputOption {
+	return func(c *inputConfig) {
+		c.completions = completions
+	}
+}
+
+// InputFuzzy makes autocomplete match completions fuzzily instead of by
+// prefix, so "gci" can suggest "git commit". The best scoring completion is
+// shown as the suggestion.
+func InputFuzzy() InputOption {
+	return func(c *inputConfig) {
+		c.fuzzy = true
+	}
+}
+
+// InputValidator sets a function that checks the line when Enter is pressed.
+// If it returns an error the message is shown below the prompt and editing
+// continues.
+func InputValidator(validate func(string) error) InputOption {
+	return func(c *inputConfig) {
+		c.validate = validate
+	}
+}
+
+// InputLiveValidation runs the validator after every key press as well, so
+// the error is shown and cleared while typing.
+func InputLiveValidation() InputOption {
+	return func(c *inputConfig) {
+		c.liveCheck = true
+	}
+}
+
+// InputErrorStyle sets the style of validation errors. It defaults to Red.
+func InputErrorStyle(style string) InputOption {
+	return func(c *inputConfig) {
+		c.errorStyle = style
+	}
+}
+
+// InputHistory enables history: up/down cycles through previous entries and
+// every submitted line is added to h. If saving h fails, Input returns the
+// line along with the error, unless it is handled with InputHistoryErrors.
+func InputHistory(h *History) InputOption {
+	return func(c *inputConfig) {
+		c.history = h
+	}
+}
+
+// InputHistoryErrors sends the errors saving the history to fn, such as a
+// history file that cannot be written, instead of returning them from Input.
+func InputHistoryErrors(fn func(error)) InputOption {
+	return func(c *inputConfig) {
+		c.historyErr = fn
+	}
+}
+
+// InputWriter sets where the prompt is drawn. It defaults to os.Stdout.
+func InputWriter(w io.Writer) InputOption {
+	return func(c *inputConfig) {
+		c.out = w
+	}
+}
+
+// InputKeyReader sets where keys are read from. It defaults to the KeyReader
+// set with SetKeyReader.
+func InputKeyReader(kr *KeyReader) InputOption {
+	return func(c *inputConfig) {
+		c.keys = kr
+	}
+}
+
+// Input provides an interactive input prompt configured by opts and returns
+// the entered line once Enter is pressed. Ctrl-C and Escape cancel the prompt
+// and return ErrInterrupted (in vi mode Escape switches to normal mode
+// instead); any other error comes from reading the input or saving the
+// history.
+func Input(opts ...InputOption) (string, error) {
+	line := ""
+	err := input(opts, func(text []rune) {
+		line = string(text)
+	})
+	return line, err
+}
+
+// input runs the prompt of Input and passes the entered text to result,
+// unless the prompt was canceled, before zeroing it if it is masked.
+func input(opts []InputOption, result func(text []rune)) error {
+	cfg := &inputConfig{out: os.Stdout, errorStyle: Red}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.keys == nil {
+		cfg.keys = defaultKeyReader()
+	}
+	if cfg.masked || cfg.multiline {
+		cfg.history, cfg.completions, cfg.completer, cfg.dropdown = nil, nil, nil, 0
+	}
+
+	st := &inputState{cfg: cfg, histPos: -1, viMode: cfg.resolveEditMode() == ViMode}
+	st.setText([]rune(cfg.defaultText))
+	st.render()
+	for !st.done {
+		read := cfg.keys.readText
+		if st.viMode && st.vi.normal {
+			read = cfg.keys.readKey
+		}
+		keyType, key, err := read()
+		if err != nil {
+			fmt.Fprintln(cfg.out)
+			result(st.text)
+			if cfg.masked {
+				st.wipe()
+			}
+			return err
+		}
+		st.handleKey(keyType, key)
+		if cfg.liveCheck && cfg.validate != nil && !st.done && !st.searching {
+			if err := cfg.validate(string(st.text)); err != nil {
+				st.errMsg = err.Error()
+			}
+		}
+		st.render()
+	}
+	if st.viMode {
+		// Restore the terminal's default cursor shape.
+		fmt.Fprint(cfg.out, "\033[0 q")
+	}
+	fmt.Fprintln(cfg.out)
+	defer func() {
+		if cfg.masked {
+			st.wipe()
+		}
+	}()
+	if st.cancel {
+		if st.cancelKey == "escape" && cfg.escapeBack {
+			return errBack
+		}
+		return ErrInterrupted
+	}
+	result(st.text)
+	if cfg.history != nil {
+		if err := cfg.history.Add(string(st.text)); err != nil {
+			if cfg.historyErr == nil {
+				return fmt.Errorf("ansi: saving history: %w", err)
+			}
+			cfg.historyErr(err)
+		}
+	}
+	return nil
+}
+
+// handleKey applies a single key press to the prompt.
+func (st *inputState) handleKey(keyType, key string) {
+	st.errMsg = ""
+	if keyType == "Special" && key == "ctrl-c" {
+		st.interrupt(key)
+		return
+	}
+	if keyType == "Special" && key == "ctrl-t" && st.cfg.masked && st.cfg.maskToggle {
+		st.revealed = !st.revealed
+		return
+	}
+	if st.searching {
+		st.handleSearchKey(keyType, key)
+		return
+	}
+	if st.viMode && st.handleViKey(keyType, key) {
+		return
+	}
+	if st.cfg.multiline && st.handleMultilineKey(keyType, key) {
+		return
+	}
+	if st.handleMenuKey(keyType, key) || st.handleTabKey(keyType, key) {
+		return
+	}
+	switch {
+	case keyType == "Special" && key == "enter":
+		st.submit()
+	case keyType == "Special" && key == "escape":
+		st.interrupt(key)
+	case st.cfg.number != "" && keyType == "Arrow" && key == "up":
+		st.stepNumber(1)
+	case st.cfg.number != "" && keyType == "Arrow" && key == "down":
+		st.stepNumber(-1)
+	case keyType == "Special" && key == "ctrl-r":
+		st.startSearch()
+	case keyType == "Arrow" && key == "up":
+		st.historyPrev()
+	case keyType == "Arrow" && key == "down":
+		st.historyNext()
+	default:
+		st.handleEditKey(keyType, key)
+	}
+}
+
+// submit finishes the prompt unless the validator rejects the line.
+func (st *inputState) submit() {
+	if st.cfg.validate != nil {
+		if err := st.cfg.validate(string(st.text)); err != nil {
+			st.errMsg = err.Error()
+			return
+		}
+	}
+	st.done = true
+}
+
+// interrupt finishes the prompt without accepting the line.
+func (st *inputState) interrupt(key string) {
+	st.searching = false
+	st.cancel = true
+	st.cancelKey = key
+	st.done = true
+}
+
+// historyPrev replaces the line with the previous history entry.
+func (st *inputState) historyPrev() {
+	if st.cfg.history == nil {
+		return
+	}
+	entries := st.cfg.history.Entries()
+	if st.histPos == -1 {
+		st.draft = st.text
+		st.histPos = len(entries)
+	}
+	if st.histPos > 0 {
+		st.histPos--
+		st.setText([]rune(entries[st.histPos]))
+	}
+}
+
+// historyNext replaces the line with the next history entry, returning to the
+// new line after the last one.
+func (st *inputState) historyNext() {
+	if st.cfg.history == nil || st.histPos == -1 {
+		return
+	}
+	entries := st.cfg.history.Entries()
+	st.histPos++
+	if st.histPos >= len(entries) {
+		st.histPos = -1
+		st.setText(st.draft)
+		st.draft = nil
+		return
+	}
+	st.setText([]rune(entries[st.histPos]))
+}
+
+// startSearch enters reverse-i-search mode.
+func (st *inputState) startSearch() {
+	if st.cfg.history == nil {
+		return
+	}
+	st.searching = true
+	st.searchQuery = nil
+	st.searchPos = -1
+	st.searchOrig = st.text
+}
+
+// handleSearchKey applies a key press while reverse-i-search is active. Typing
+// narrows the search, Ctrl-R moves to the next older match, Enter submits the
+// match, Ctrl-G cancels and any other key keeps the match for editing before
+// being handled as usual.
+func (st *inputState) handleSearchKey(keyType, key string) {
+	switch {
+	case keyType == "Character" || keyType == "Paste":
+		st.searchQuery = append(st.searchQuery, []rune(key)...)
+		from := st.searchPos
+		if from == -1 {
+			from = len(st.cfg.history.Entries()) - 1
+		}
+		st.search(from)
+	case keyType == "Special" && key == "backspace":
+		if len(st.searchQuery) > 0 {
+			st.searchQuery = st.searchQuery[:len(st.searchQuery)-1]
+		}
+		st.search(len(st.cfg.history.Entries()) - 1)
+	case keyType == "Special" && key == "ctrl-r":
+		if st.searchPos > 0 {
+			st.search(st.searchPos - 1)
+		}
+	case keyType == "Special" && key == "ctrl-g":
+		st.searching = false
+		st.setText(st.searchOrig)
+	case keyType == "Special" && key == "enter":
+		st.endSearch()
+		st.submit()
+	case keyType == "Special" && key == "escape":
+		st.endSearch()
+	default:
+		st.endSearch()
+		st.handleKey(keyType, key)
+	}
+}
+
+// endSearch leaves reverse-i-search mode keeping the match as the line, with
+// history browsing continuing from the matched entry.
+func (st *inputState) endSearch() {
+	st.searching = false
+	if st.searchPos >= 0 {
+		if st.histPos == -1 {
+			st.draft = st.searchOrig
+		}
+		st.histPos = st.searchPos
+	}
+}
+
+// search finds the newest history entry at or before index from that contains
+// the query and makes it the current line.
+func (st *inputState) search(from int) {
+	entries := st.cfg.history.Entries()
+	query := string(st.searchQuery)
+	for i := from; i >= 0; i-- {
+		if strings.Contains(entries[i], query) {
+			st.searchPos = i
+			st.setText([]rune(entries[i]))
+			return
+		}
+	}
+	st.searchPos = -1
+}
+
+// renderSearch draws the reverse-i-search line.
+func (st *inputState) renderSearch() {
+	label := "(reverse-i-search)"
+	if st.searchPos == -1 && len(st.searchQuery) > 0 {
+		label = "(failed reverse-i-search)"
+	}
+	query := string(st.searchQuery)
+	match := string(st.text)
+	if i := strings.Index(match, query); query != "" && i >= 0 {
+		match = match[:i] + Underline + query + End + match[i+len(query):]
+	}
+	fmt.Fprintf(st.cfg.out, "\r\033[J%s`%s': %s", label, query, match)
+}
+
+// render redraws the prompt line with the current text.
+func (st *inputState) render() {
+	if st.searching {
+		st.renderSearch()
+		return
+	}
+	if st.cfg.multiline {
+		st.renderMultiline()
+		return
+	}
+	cfg := st.cfg
+	var sb strings.Builder
+	if st.viMode {
+		sb.WriteString(st.viCursorShape())
+	}
+	// Clear from the start of the line to the end of the screen, which also
+	// erases anything drawn below the prompt last time.
+	sb.WriteString("\r\033[J")
+	if cfg.promptStyle != "" {
+		sb.WriteString(cfg.promptStyle + cfg.prompt + End)
+	} else {
+		sb.WriteString(cfg.prompt)
+	}
+	sb.WriteString(" ")
+
+	switch {
+	case cfg.masked:
+		sb.WriteString(st.maskedText())
+	case cfg.colorizer != nil:
+		sb.WriteString(cfg.colorizer(string(st.text)))
+	default:
+		sb.WriteString(cfg.colorWords(string(st.text)))
+	}
+	// Autocomplete for the last word, when the cursor is at the end of the
+	// line. A masked prompt has none, so its text is never copied to it.
+	if !st.done && !cfg.masked {
+		st.suggestions()
+	}
+	if st.cursor == len(st.text) && !st.done && !cfg.masked {
+		if hint := st.autocomplete().hint(string(st.currentWord())); hint != "" {
+			sb.WriteString(Faint + hint + End)
+		}
+	}
+	if len(st.text) == 0 && cfg.placeholder != "" && !st.done {
+		sb.WriteString(Faint + cfg.placeholder + End)
+	}
+	var below []string
+	if st.errMsg != "" {
+		below = append(below, cfg.errorStyle+st.errMsg+End)
+	}
+	if !st.done {
+		below = append(below, st.dropdownLines()...)
+	}
+	for _, line := range below {
+		sb.WriteString("\r\n" + line)
+	}
+	if len(below) > 0 {
+		sb.WriteString(fmt.Sprintf("\033[%dA", len(below)))
+	}
+	sb.WriteString(cursorColumn(st.textColumn(st.cursor)))
+	fmt.Fprint(cfg.out, sb.String())
+}
+
+// textColumn returns the screen column of rune position pos of the line.
+func (st *inputState) textColumn(pos int) int {
+	if st.hidden() {
+		return visibleWidth(st.cfg.prompt) + 1 + pos*runeWidth(st.cfg.mask)
+	}
+	return visibleWidth(st.cfg.prompt) + 1 + runesWidth(st.text[:pos])
+}
+
+// cursorColumn returns the sequence moving the cursor to column col (counted
+// from 0) of the current line.
+func cursorColumn(col int) string {
+	if col <= 0 {
+		return "\r"
+	}
+	return fmt.Sprintf("\r\033[%dC", col)
+}
+
+// colorWords colors each word of line green if it matches a completion and
+// red otherwise. Without completions the line is left as it is.
+func (cfg *inputConfig) colorWords(line string) string {
+	if len(cfg.completions) == 0 {
+		return line
+	}
+	var sb strings.Builder
+	for i, word := range strings.Split(line, " ") {
+		if i > 0 {
+			sb.WriteString(" ")
+		}
+		if word == "" {
+			continue
+		}
+		if cfg.isCompletion(word) {
+			sb.WriteString(Green + word + End)
+		} else {
+			sb.WriteString(Red + word + End)
+		}
+	}
+	return sb.String()
+}
+
+// isCompletion reports whether word is one of the configured completions.
+func (cfg *inputConfig) isCompletion(word string) bool {
+	for _, comp := range cfg.completions {
+		if word == comp {
+			return true
+		}
+	}
+	return false
+}
+
+// DInput provides an interactive input prompt with autocomplete based on a list of completions.
+// It is a shorthand for Input with InputPrompt and InputCompletions.
+func DInput(completions []string, prompt string) string {
+	text, _ := Input(InputPrompt(prompt), InputCompletions(completions))
+	return text
+}
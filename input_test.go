@@ -0,0 +1,67 @@
+package ansi
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// keys returns the options reading input as keys and discarding the output.
+func keys(input string) []InputOption {
+	return []InputOption{InputKeyReader(NewKeyReader(strings.NewReader(input))), InputWriter(io.Discard)}
+}
+
+func TestInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  []InputOption
+		want  string
+		err   error
+	}{
+		{"pasted", "hello world\r", nil, "hello world", nil},
+		{"edited", "helo\x1b[Dl\r", nil, "hello", nil},
+		{"backspace", "abc\x7f\x7fd\r", nil, "ad", nil},
+		{"default", "!\r", []InputOption{InputDefault("hi")}, "hi!", nil},
+		{"interrupted", "ab\x03", nil, "", ErrInterrupted},
+		{"end of input", "ab", nil, "ab", io.EOF},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Input(append(keys(tt.input), tt.opts...)...)
+			if got != tt.want || !errors.Is(err, tt.err) {
+				t.Errorf("Input(%q) = %q, %v; want %q, %v", tt.input, got, err, tt.want, tt.err)
+			}
+		})
+	}
+}
+
+func TestPasswordBytes(t *testing.T) {
+	got, err := PasswordBytes("Password:", keys("s3cret\r")...)
+	if string(got) != "s3cret" || err != nil {
+		t.Errorf("PasswordBytes = %q, %v; want \"s3cret\", nil", got, err)
+	}
+}
+
+func TestInputHistoryError(t *testing.T) {
+	// The history file is below a regular file, so it cannot be written.
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	h := &History{Path: filepath.Join(file, "history")}
+
+	got, err := Input(append(keys("ls\r"), InputHistory(h))...)
+	if got != "ls" || err == nil {
+		t.Errorf("Input = %q, %v; want \"ls\" and an error", got, err)
+	}
+
+	var handled error
+	got, err = Input(append(keys("pwd\r"), InputHistory(h), InputHistoryErrors(func(err error) { handled = err }))...)
+	if got != "pwd" || err != nil || handled == nil {
+		t.Errorf("Input = %q, %v with %v handled; want \"pwd\", nil with an error handled", got, err, handled)
+	}
+}
@@ -0,0 +1,232 @@
+package ansi
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// --------------------
+// Key Map
+// --------------------
+
+// KeyBinding binds keys to an action of a view.
+type KeyBinding struct {
+	Name     string   // Name of the action, used to match and rebind it.
+	Keys     []string // Key values as read by a KeyReader, such as "up", "k" or "ctrl-c". See Match.
+	Help     string   // What the action does, like "select".
+	Label    string   // How the keys are shown. The default is made from Keys.
+	Group    string   // Heading of the action in the full help.
+	Extra    bool     // Whether the action is left out of the short help line.
+	Disabled bool     // Whether the keys do nothing and are not shown.
+}
+
+// keyLabels are how some keys are shown in help.
+var keyLabels = map[string]string{
+	"up": "↑", "down": "↓", "left": "←", "right": "→",
+	"escape": "esc", " ": "space", "pageup": "pgup", "pagedown": "pgdn",
+}
+
+// label returns how the keys of the binding are shown.
+func (kb *KeyBinding) label() string {
+	if kb.Label != "" {
+		return kb.Label
+	}
+	labels := make([]string, len(kb.Keys))
+	for i, key := range kb.Keys {
+		labels[i] = key
+		if label, ok := keyLabels[key]; ok {
+			labels[i] = label
+		}
+	}
+	return strings.Join(labels, "/")
+}
+
+// KeyMap is the set of key bindings of a view. Matching keys through it and
+// drawing help from it keeps the help right when keys are rebound.
+type KeyMap struct {
+	Bindings []*KeyBinding
+}
+
+// NewKeyMap creates a key map of bindings.
+func NewKeyMap(bindings ...KeyBinding) *KeyMap {
+	km := &KeyMap{}
+	for _, kb := range bindings {
+		km.Bindings = append(km.Bindings, &kb)
+	}
+	return km
+}
+
+// Binding returns the binding of the action name, or nil.
+func (km *KeyMap) Binding(name string) *KeyBinding {
+	for _, kb := range km.Bindings {
+		if kb.Name == name {
+			return kb
+		}
+	}
+	return nil
+}
+
+// Bind sets the keys of the action name, dropping its custom label.
+func (km *KeyMap) Bind(name string, keys ...string) {
+	if kb := km.Binding(name); kb != nil {
+		kb.Keys, kb.Label = keys, ""
+	}
+}
+
+// Match returns the name of the action bound to the key read as keyType
+// and key, or "" if there is none. A single character in Keys matches a
+// Character key, the arrows (with modifiers, like "shift-up") match Arrow
+// keys and the others match Special keys, so pasted text matches nothing.
+func (km *KeyMap) Match(keyType, key string) string {
+	if keyType != bindingType(key) {
+		return ""
+	}
+	for _, kb := range km.Bindings {
+		if kb.Disabled {
+			continue
+		}
+		for _, k := range kb.Keys {
+			if k == key {
+				return kb.Name
+			}
+		}
+	}
+	return ""
+}
+
+// bindingType returns the type of key as read by a KeyReader.
+func bindingType(key string) string {
+	if utf8.RuneCountInString(key) == 1 {
+		return "Character"
+	}
+	switch key[strings.LastIndex(key, "-")+1:] {
+	case "up", "down", "left", "right":
+		return "Arrow"
+	}
+	return "Special"
+}
+
+// ShortHelp returns the help line of the actions that are not Extra, like
+// "↑/↓ navigate · enter select · q quit", cut at a whole action to fit in
+// width columns.
+func (km *KeyMap) ShortHelp(width int) string {
+	var parts []string
+	used := 0
+	for _, kb := range km.Bindings {
+		if kb.Disabled || kb.Extra || len(kb.Keys) == 0 {
+			continue
+		}
+		part := kb.label() + " " + Faint + kb.Help + End
+		w := visibleWidth(part)
+		if len(parts) > 0 {
+			w += 3
+		}
+		if used+w > width {
+			break
+		}
+		parts = append(parts, part)
+		used += w
+	}
+	return strings.Join(parts, Faint+" · "+End)
+}
+
+// FullHelp returns the lines of the help of all actions, by group, with
+// the keys in a column.
+func (km *KeyMap) FullHelp() []string {
+	labelWidth := 0
+	var groups []string
+	byGroup := map[string][]*KeyBinding{}
+	for _, kb := range km.Bindings {
+		if kb.Disabled || len(kb.Keys) == 0 {
+			continue
+		}
+		if _, ok := byGroup[kb.Group]; !ok {
+			groups = append(groups, kb.Group)
+		}
+		byGroup[kb.Group] = append(byGroup[kb.Group], kb)
+		labelWidth = max(labelWidth, visibleWidth(kb.label()))
+	}
+	var lines []string
+	for i, group := range groups {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		if group != "" {
+			lines = append(lines, Bold+group+End)
+		}
+		for _, kb := range byGroup[group] {
+			label := kb.label()
+			lines = append(lines, fmt.Sprintf("%s%s  %s", Cyan+label+End, strings.Repeat(" ", labelWidth-visibleWidth(label)), kb.Help))
+		}
+	}
+	return lines
+}
+
+// Help shows the short help line of a KeyMap and, toggled with ?, the full
+// help in a box over the screen.
+type Help struct {
+	Keys   *KeyMap
+	Border Border // BorderRounded if not set with NewHelp.
+	Style  string // Style of the border. The default is Cyan.
+
+	open bool
+}
+
+// NewHelp creates the help of keys.
+func NewHelp(keys *KeyMap) *Help {
+	return &Help{Keys: keys, Border: BorderRounded, Style: Cyan}
+}
+
+// Open reports whether the full help is shown.
+func (h *Help) Open() bool {
+	return h.open
+}
+
+// HandleKey toggles the full help with ?, and closes it with Escape or q,
+// and reports whether the key was used. While the full help is open it uses
+// every key, so the view below does not react to them.
+func (h *Help) HandleKey(keyType, key string) bool {
+	switch {
+	case keyType == "Character" && key == "?":
+		h.open = !h.open
+		return true
+	case !h.open:
+		return false
+	case keyType == "Special" && key == "escape", keyType == "Character" && key == "q":
+		h.open = false
+	}
+	return true
+}
+
+// Line returns the short help line for width columns, ending with "? help".
+func (h *Help) Line(width int) string {
+	more := "? " + Faint + "help" + End
+	line := h.Keys.ShortHelp(width - 7)
+	if line == "" {
+		return more
+	}
+	return line + Faint + " · " + End + more
+}
+
+// Render returns the escape sequences drawing the full help in the middle
+// of screen if it is open, or "" if not.
+func (h *Help) Render(screen Rect) string {
+	if !h.open {
+		return ""
+	}
+	lines := append(h.Keys.FullHelp(), "", Faint+"? or esc to close"+End)
+	width := 0
+	for _, line := range lines {
+		width = max(width, visibleWidth(line))
+	}
+	width = min(width+4, screen.Width)
+	height := min(len(lines)+2, screen.Height)
+	r := Rect{
+		Row:    screen.Row + max((screen.Height-height)/2, 0),
+		Col:    screen.Col + max((screen.Width-width)/2, 0),
+		Width:  width,
+		Height: height,
+	}
+	return renderBox(r, h.Border, h.Style, "Keys", lines)
+}
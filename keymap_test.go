@@ -0,0 +1,48 @@
+package ansi
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKeyMapMatch(t *testing.T) {
+	km := NewKeyMap(
+		KeyBinding{Name: "up", Keys: []string{"up", "k"}},
+		KeyBinding{Name: "quit", Keys: []string{"q", "ctrl-c"}},
+	)
+	tests := []struct {
+		keyType, key, want string
+	}{
+		{"Arrow", "up", "up"},
+		{"Character", "k", "up"},
+		{"Character", "q", "quit"},
+		{"Special", "ctrl-c", "quit"},
+		{"Paste", "q", ""},
+		{"Special", "up", ""},
+		{"Arrow", "shift-up", ""},
+	}
+	for _, tt := range tests {
+		if got := km.Match(tt.keyType, tt.key); got != tt.want {
+			t.Errorf("Match(%q, %q) = %q, want %q", tt.keyType, tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestCalendarKeys(t *testing.T) {
+	day := time.Date(2024, time.March, 15, 0, 0, 0, 0, time.UTC)
+	c := &Calendar{Selected: day}
+	if !c.HandleKey("Arrow", "right") || !c.Selected.Equal(day.AddDate(0, 0, 1)) {
+		t.Errorf("right: Selected = %v, want the next day", c.Selected)
+	}
+	c.Keys.Bind("next-day", "n")
+	if c.HandleKey("Arrow", "right") {
+		t.Error("right still moves after rebinding next-day to n")
+	}
+	c.HandleKey("Character", "n")
+	if !c.Selected.Equal(day.AddDate(0, 0, 2)) {
+		t.Errorf("n: Selected = %v, want two days on", c.Selected)
+	}
+	if c.HandleKey("Paste", "n") {
+		t.Error("pasted n was used as a key")
+	}
+}
@@ -0,0 +1,359 @@
+package ansi
+
+import (
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// --------------------
+// KeyReader
+// --------------------
+
+// KeyReader reads key presses from an input stream. It can wrap any io.Reader,
+// so the package works on a PTY, an SSH channel or a test pipe as well as on
+// stdin. If the stream is a terminal it is put into raw mode while reading.
+type KeyReader struct {
+	in  io.Reader
+	fd  int
+	tty bool
+	buf []byte
+	mu  sync.Mutex
+
+	paste int // Paste detection set with DetectPaste: 1 on, -1 off, 0 only in text prompts.
+}
+
+// NewKeyReader creates a KeyReader reading from r. If r has an Fd method (like
+// *os.File) and refers to a terminal, raw mode is used during reads.
+func NewKeyReader(r io.Reader) *KeyReader {
+	kr := &KeyReader{in: r, fd: -1}
+	if f, ok := r.(interface{ Fd() uintptr }); ok {
+		kr.fd = int(f.Fd())
+		kr.tty = term.IsTerminal(kr.fd)
+	}
+	return kr
+}
+
+// NewKeyReaderFd creates a KeyReader reading from an open file descriptor,
+// such as the master side of a PTY. The descriptor stays owned by the
+// caller, who closes it once done reading.
+func NewKeyReaderFd(fd int) *KeyReader {
+	return NewKeyReader(fdReader(fd))
+}
+
+// fdReader reads from a file descriptor without taking ownership of it, as an
+// *os.File would by closing it once garbage collected.
+type fdReader int
+
+func (fd fdReader) Read(b []byte) (int, error) {
+	n, err := readFd(int(fd), b)
+	if n == 0 && err == nil && len(b) > 0 {
+		return 0, io.EOF
+	}
+	return n, err
+}
+
+func (fd fdReader) Fd() uintptr {
+	return uintptr(fd)
+}
+
+// stdinKeys is the KeyReader used by CaptureKey and the package's prompts.
+var (
+	stdinKeys   = NewKeyReader(os.Stdin)
+	stdinKeysMu sync.Mutex
+)
+
+// SetKeyReader replaces the KeyReader used by CaptureKey, DInput and Menu.
+// Passing nil restores reading from os.Stdin.
+func SetKeyReader(kr *KeyReader) {
+	if kr == nil {
+		kr = NewKeyReader(os.Stdin)
+	}
+	stdinKeysMu.Lock()
+	stdinKeys = kr
+	stdinKeysMu.Unlock()
+}
+
+// defaultKeyReader returns the KeyReader currently set with SetKeyReader.
+func defaultKeyReader() *KeyReader {
+	stdinKeysMu.Lock()
+	defer stdinKeysMu.Unlock()
+	return stdinKeys
+}
+
+// DetectPaste sets whether several printable characters arriving in a single
+// read are taken as pasted rather than typed and returned as one "Paste" key.
+// By default only the prompts that edit text, such as Input, Edit, Editor and
+// the text fields of a Form, detect pastes; keys read by CaptureKey and the
+// list prompts are always single characters, so that keys repeated quickly,
+// as over SSH, count one by one. DetectPaste(true) detects pastes for every
+// read, DetectPaste(false) for none.
+func (kr *KeyReader) DetectPaste(on bool) {
+	kr.mu.Lock()
+	kr.paste = -1
+	if on {
+		kr.paste = 1
+	}
+	kr.mu.Unlock()
+}
+
+// CaptureKey reads a single key press and returns a key type and value, using
+// the same values as the package-level CaptureKey.
+func (kr *KeyReader) CaptureKey() (string, string) {
+	keyType, key, err := kr.readKey()
+	if err != nil {
+		return "error", err.Error()
+	}
+	return keyType, key
+}
+
+// readKey is CaptureKey with the read error returned as an error value.
+func (kr *KeyReader) readKey() (string, string, error) {
+	return kr.read(false)
+}
+
+// readText is readKey for prompts that edit text, which also detects pastes
+// unless DetectPaste turned that off.
+func (kr *KeyReader) readText() (string, string, error) {
+	return kr.read(true)
+}
+
+// read reads the next key, detecting pastes if text is set or DetectPaste
+// turned that on.
+func (kr *KeyReader) read(text bool) (string, string, error) {
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+	paste := kr.paste > 0 || text && kr.paste == 0
+	for {
+		if len(kr.buf) > 0 {
+			if paste {
+				if n := printableRun(kr.buf); n > 0 {
+					text := string(kr.buf[:n])
+					kr.consume(n)
+					return "Paste", text, nil
+				}
+			}
+			keyType, key, n := parseKey(kr.buf)
+			if n > 0 {
+				kr.consume(n)
+				return keyType, key, nil
+			}
+		}
+		if err := kr.fill(); err != nil {
+			if len(kr.buf) > 0 {
+				// The stream ended in the middle of a sequence; hand back what is left.
+				key := string(kr.buf)
+				kr.buf = nil
+				return "Character", key, nil
+			}
+			return "", "", err
+		}
+	}
+}
+
+// keyPressed reports whether a key is waiting to be read, without blocking.
+// It is always false for readers without a file descriptor.
+func (kr *KeyReader) keyPressed() bool {
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+	if len(kr.buf) > 0 {
+		return true
+	}
+	if kr.fd < 0 || !inputReady(kr.fd) {
+		return false
+	}
+	return kr.fill() == nil
+}
+
+// consume drops the first n bytes of the buffer, zeroing them so typed
+// passwords do not linger in memory.
+func (kr *KeyReader) consume(n int) {
+	clear(kr.buf[:n])
+	kr.buf = kr.buf[n:]
+}
+
+// fill reads the next chunk of input into the buffer.
+func (kr *KeyReader) fill() error {
+	if kr.tty {
+		oldState, err := term.MakeRaw(kr.fd)
+		if err != nil {
+			return err
+		}
+		defer term.Restore(kr.fd, oldState)
+	}
+	b := make([]byte, 256)
+	n, err := kr.in.Read(b)
+	kr.buf = append(kr.buf, b[:n]...)
+	clear(b)
+	if n == 0 {
+		if err == nil {
+			err = io.ErrNoProgress
+		}
+		return err
+	}
+	return nil
+}
+
+// printableRun returns the length in bytes of the run of printable characters
+// at the start of b, or 0 if the run is shorter than two characters. A person
+// typing produces one character per read, so a longer run means a paste.
+func printableRun(b []byte) int {
+	n, count := 0, 0
+	for n < len(b) {
+		if runtime.GOOS == "windows" && (b[n] == 0 || b[n] == 224) {
+			break
+		}
+		if !utf8.FullRune(b[n:]) {
+			break
+		}
+		r, size := utf8.DecodeRune(b[n:])
+		if r == utf8.RuneError || !unicode.IsPrint(r) {
+			break
+		}
+		n += size
+		count++
+	}
+	if count < 2 {
+		return 0
+	}
+	return n
+}
+
+// parseKey decodes the first key in b. It returns the key type, the key value
+// and the number of bytes consumed, or 0 bytes if b holds an incomplete sequence.
+func parseKey(b []byte) (string, string, int) {
+	if runtime.GOOS == "windows" && (b[0] == 0 || b[0] == 224) {
+		if len(b) < 2 {
+			return "", "", 0
+		}
+		switch b[1] {
+		case 'H':
+			return "Arrow", "up", 2
+		case 'P':
+			return "Arrow", "down", 2
+		case 'K':
+			return "Arrow", "left", 2
+		case 'M':
+			return "Arrow", "right", 2
+		}
+		return "Character", string(b[:2]), 2
+	}
+
+	switch b[0] {
+	case 27:
+		return parseEscape(b)
+	case 8, 127:
+		return "Special", "backspace", 1
+	case '\r', '\n':
+		return "Special", "enter", 1
+	case '\t':
+		return "Special", "tab", 1
+	}
+	if b[0] >= 1 && b[0] <= 26 {
+		return "Special", "ctrl-" + string(rune('a'+b[0]-1)), 1
+	}
+
+	if !utf8.FullRune(b) {
+		return "", "", 0
+	}
+	_, size := utf8.DecodeRune(b)
+	return "Character", string(b[:size]), size
+}
+
+// parseEscape decodes an escape sequence at the start of b.
+func parseEscape(b []byte) (string, string, int) {
+	if len(b) == 1 {
+		return "Special", "escape", 1
+	}
+	if b[1] != '[' && b[1] != 'O' {
+		// Alt+key arrives as escape followed by the key.
+		if b[1] == '\r' {
+			return "Special", "alt-enter", 2
+		}
+		if b[1] >= 32 && b[1] < 127 {
+			return "Special", "alt-" + string(b[1]), 2
+		}
+		return "Special", "escape", 1
+	}
+	// Find the final byte of the CSI (or SS3) sequence.
+	end := -1
+	for i := 2; i < len(b); i++ {
+		if b[i] >= 0x40 && b[i] <= 0x7e {
+			end = i
+			break
+		}
+		if b[1] == 'O' {
+			break
+		}
+	}
+	if end < 0 {
+		if len(b) > 16 {
+			return "Special", "escape", 1
+		}
+		return "", "", 0
+	}
+	seq := string(b[:end+1])
+	if b[2] == '<' && (b[end] == 'M' || b[end] == 'm') {
+		// SGR mouse reports, e.g. "\x1b[<0;12;5M"; see ParseMouse.
+		return "Mouse", string(b[3 : end+1]), end + 1
+	}
+	params := strings.Split(string(b[2:end]), ";")
+
+	// A second parameter carries the modifier keys, e.g. "\x1b[1;5D" for Ctrl+Left.
+	modifier := ""
+	if len(params) > 1 {
+		switch params[1] {
+		case "2":
+			modifier = "shift-"
+		case "3":
+			modifier = "alt-"
+		case "5":
+			modifier = "ctrl-"
+		}
+	}
+	switch b[end] {
+	case 'A':
+		return "Arrow", modifier + "up", end + 1
+	case 'B':
+		return "Arrow", modifier + "down", end + 1
+	case 'C':
+		return "Arrow", modifier + "right", end + 1
+	case 'D':
+		return "Arrow", modifier + "left", end + 1
+	case 'Z':
+		return "Special", "shift-tab", end + 1
+	case 'H':
+		return "Special", "home", end + 1
+	case 'F':
+		return "Special", "end", end + 1
+	case 'u':
+		// Keys reported by the kitty keyboard protocol, e.g. "\x1b[13;2u".
+		if params[0] == "13" {
+			return "Special", modifier + "enter", end + 1
+		}
+	case '~':
+		// xterm's modifyOtherKeys reports e.g. Shift+Enter as "\x1b[27;2;13~".
+		if params[0] == "27" && len(params) == 3 && params[2] == "13" {
+			return "Special", modifier + "enter", end + 1
+		}
+		switch params[0] {
+		case "1", "7":
+			return "Special", "home", end + 1
+		case "4", "8":
+			return "Special", "end", end + 1
+		case "3":
+			return "Special", "delete", end + 1
+		case "5":
+			return "Special", "pageup", end + 1
+		case "6":
+			return "Special", "pagedown", end + 1
+		}
+	}
+	return "Character", seq, end + 1
+}
@@ -0,0 +1,146 @@
+package ansi
+
+import (
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+type key struct{ keyType, key string }
+
+// readAll reads the keys of input until it runs out, detecting pastes if
+// text is set, as the prompts that edit text do.
+func readAll(t *testing.T, input string, text bool) []key {
+	t.Helper()
+	kr := NewKeyReader(strings.NewReader(input))
+	var keys []key
+	for {
+		keyType, k, err := kr.read(text)
+		if err == io.EOF {
+			return keys
+		}
+		if err != nil {
+			t.Fatalf("%q: %v", input, err)
+		}
+		keys = append(keys, key{keyType, k})
+	}
+}
+
+func TestKeyReaderParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []key
+	}{
+		{"characters", "ab", []key{{"Character", "a"}, {"Character", "b"}}},
+		{"utf-8", "é世", []key{{"Character", "é"}, {"Character", "世"}}},
+		{"enter", "\r\n", []key{{"Special", "enter"}, {"Special", "enter"}}},
+		{"control", "\x03\x7f\t", []key{{"Special", "ctrl-c"}, {"Special", "backspace"}, {"Special", "tab"}}},
+		{"escape", "\x1b", []key{{"Special", "escape"}}},
+		{"alt", "\x1bb", []key{{"Special", "alt-b"}}},
+		{"csi arrows", "\x1b[A\x1b[B\x1b[C\x1b[D", []key{{"Arrow", "up"}, {"Arrow", "down"}, {"Arrow", "right"}, {"Arrow", "left"}}},
+		{"csi modifier", "\x1b[1;5D\x1b[1;2A", []key{{"Arrow", "ctrl-left"}, {"Arrow", "shift-up"}}},
+		{"csi tilde", "\x1b[3~\x1b[5~\x1b[6~\x1b[1~\x1b[4~", []key{{"Special", "delete"}, {"Special", "pageup"}, {"Special", "pagedown"}, {"Special", "home"}, {"Special", "end"}}},
+		{"shift-tab", "\x1b[Z", []key{{"Special", "shift-tab"}}},
+		{"ss3", "\x1bOA\x1bOH\x1bOF", []key{{"Arrow", "up"}, {"Special", "home"}, {"Special", "end"}}},
+		{"shift-enter", "\x1b[13;2u\x1b[27;2;13~", []key{{"Special", "shift-enter"}, {"Special", "shift-enter"}}},
+		{"sgr mouse", "\x1b[<0;12;5M\x1b[<0;12;5m", []key{{"Mouse", "0;12;5M"}, {"Mouse", "0;12;5m"}}},
+		{"unfinished sequence", "\x1b[1;", []key{{"Character", "\x1b[1;"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := readAll(t, tt.input, false)
+			if len(got) != len(tt.want) {
+				t.Fatalf("%q: got %q, want %q", tt.input, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("%q: key %d is %q, want %q", tt.input, i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseMouse(t *testing.T) {
+	keys := readAll(t, "\x1b[<0;12;5M\x1b[<32;3;4M\x1b[<0;12;5m", false)
+	want := []MouseEvent{
+		{Button: MouseLeft, Col: 12, Row: 5},
+		{Button: MouseLeft, Col: 3, Row: 4, Motion: true},
+		{Button: MouseLeft, Col: 12, Row: 5, Release: true},
+	}
+	for i, k := range keys {
+		ev, ok := ParseMouse(k.key)
+		if !ok || ev != want[i] {
+			t.Errorf("ParseMouse(%q) = %+v, %v; want %+v", k.key, ev, ok, want[i])
+		}
+	}
+}
+
+func TestKeyReaderPaste(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		text  bool
+		want  []key
+	}{
+		{"text prompt", "hello\r", true, []key{{"Paste", "hello"}, {"Special", "enter"}}},
+		{"single character", "h\r", true, []key{{"Character", "h"}, {"Special", "enter"}}},
+		{"list prompt", "jj\r", false, []key{{"Character", "j"}, {"Character", "j"}, {"Special", "enter"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := readAll(t, tt.input, tt.text)
+			if len(got) != len(tt.want) {
+				t.Fatalf("%q: got %q, want %q", tt.input, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("%q: key %d is %q, want %q", tt.input, i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+
+	kr := NewKeyReader(strings.NewReader("ab"))
+	kr.DetectPaste(false)
+	if keyType, k, _ := kr.readText(); keyType != "Character" || k != "a" {
+		t.Errorf("DetectPaste(false): got %s %q, want Character \"a\"", keyType, k)
+	}
+	kr = NewKeyReader(strings.NewReader("ab"))
+	kr.DetectPaste(true)
+	if keyType, k := kr.CaptureKey(); keyType != "Paste" || k != "ab" {
+		t.Errorf("DetectPaste(true): got %s %q, want Paste \"ab\"", keyType, k)
+	}
+}
+
+// The reader must not close the descriptor it was given once it is garbage
+// collected, as it would by wrapping it in an *os.File.
+func TestNewKeyReaderFdKeepsFd(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	if _, err := w.WriteString("ab"); err != nil {
+		t.Fatal(err)
+	}
+	func() {
+		kr := NewKeyReaderFd(int(r.Fd()))
+		if keyType, k := kr.CaptureKey(); keyType != "Character" || k != "a" {
+			t.Errorf("got %s %q, want Character \"a\"", keyType, k)
+		}
+	}()
+	runtime.GC()
+	runtime.GC()
+	if _, err := w.WriteString("c"); err != nil {
+		t.Fatal(err)
+	}
+	b := make([]byte, 8)
+	if _, err := r.Read(b); err != nil {
+		t.Fatalf("reading the descriptor after the KeyReader was collected: %v", err)
+	}
+}
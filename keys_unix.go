@@ -0,0 +1,23 @@
+//go:build !windows
+
+package ansi
+
+import "golang.org/x/sys/unix"
+
+// inputReady reports whether fd has input waiting to be read, without
+// blocking.
+func inputReady(fd int) bool {
+	fds := []unix.PollFd{{Fd: int32(fd), Events: unix.POLLIN}}
+	n, err := unix.Poll(fds, 0)
+	return err == nil && n > 0 && fds[0].Revents&unix.POLLIN != 0
+}
+
+// readFd reads from fd into b, retrying reads interrupted by a signal.
+func readFd(fd int, b []byte) (int, error) {
+	for {
+		n, err := unix.Read(fd, b)
+		if err != unix.EINTR {
+			return max(n, 0), err
+		}
+	}
+}
@@ -0,0 +1,16 @@
+//go:build windows
+
+package ansi
+
+import "syscall"
+
+// inputReady always reports false on Windows, where console input cannot be
+// polled the same way; keys are only seen by a blocking read.
+func inputReady(fd int) bool {
+	return false
+}
+
+// readFd reads from the handle fd into b.
+func readFd(fd int, b []byte) (int, error) {
+	return syscall.Read(syscall.Handle(fd), b)
+}
@@ -0,0 +1,411 @@
+package ansi
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strings"
+)
+
+// --------------------
+// Line Chart
+// --------------------
+
+// brailleCanvas is a grid of cells of 2×4 braille dots, each cell drawn in
+// the style of the last dot set in it, with text written over them.
+type brailleCanvas struct {
+	width, height int // Size in cells.
+	dots          [][]rune
+	chars         [][]rune // Text, drawn instead of the dots of a cell.
+	styles        [][]string
+}
+
+// brailleBits are the bits of the dots of a braille cell by row and column.
+var brailleBits = [4][2]rune{{0x01, 0x08}, {0x02, 0x10}, {0x04, 0x20}, {0x40, 0x80}}
+
+// newBrailleCanvas creates an empty canvas of width by height cells, which
+// is 2*width by 4*height dots.
+func newBrailleCanvas(width, height int) *brailleCanvas {
+	c := &brailleCanvas{width: width, height: height}
+	c.dots = make([][]rune, height)
+	c.chars = make([][]rune, height)
+	c.styles = make([][]string, height)
+	for i := range height {
+		c.dots[i] = make([]rune, width)
+		c.chars[i] = make([]rune, width)
+		c.styles[i] = make([]string, width)
+	}
+	return c
+}
+
+// set sets the dot at x, y, counted from the top left, in style.
+func (c *brailleCanvas) set(x, y int, style string) {
+	if x < 0 || y < 0 || x >= c.width*2 || y >= c.height*4 {
+		return
+	}
+	c.dots[y/4][x/2] |= brailleBits[y%4][x%2]
+	c.styles[y/4][x/2] = style
+}
+
+// point sets the dot nearest to x, y, if it is on the canvas.
+func (c *brailleCanvas) point(x, y float64, style string) {
+	if x < -0.5 || y < -0.5 || x >= float64(c.width*2)-0.5 || y >= float64(c.height*4)-0.5 {
+		return
+	}
+	c.set(int(math.Round(x)), int(math.Round(y)), style)
+}
+
+// line sets the dots of a line from x0, y0 to x1, y1, clipped to the canvas
+// so that points far outside it cost no more than those on it.
+func (c *brailleCanvas) line(x0, y0, x1, y1 float64, style string) {
+	// Liang–Barsky: keep the part of the line, from t0 to t1, on the canvas.
+	t0, t1 := 0.0, 1.0
+	dx, dy := x1-x0, y1-y0
+	edges := [4][2]float64{
+		{-dx, x0 + 0.5}, {dx, float64(c.width*2) - 0.5 - x0},
+		{-dy, y0 + 0.5}, {dy, float64(c.height*4) - 0.5 - y0},
+	}
+	for _, e := range edges {
+		p, q := e[0], e[1]
+		if p == 0 {
+			if q < 0 {
+				return
+			}
+			continue
+		}
+		t := q / p
+		if p < 0 {
+			t0 = max(t0, t)
+		} else {
+			t1 = min(t1, t)
+		}
+	}
+	if t0 > t1 {
+		return
+	}
+	x1, y1 = x0+t1*dx, y0+t1*dy
+	x0, y0 = x0+t0*dx, y0+t0*dy
+	c.bresenham(int(math.Round(x0)), int(math.Round(y0)), int(math.Round(x1)), int(math.Round(y1)), style)
+}
+
+// bresenham sets the dots of a line from x0, y0 to x1, y1.
+func (c *brailleCanvas) bresenham(x0, y0, x1, y1 int, style string) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	e := dx + dy
+	for {
+		c.set(x0, y0, style)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+// text writes s in style from the cell at col, row, once the dots are set,
+// and reports whether it fit without touching text written before.
+func (c *brailleCanvas) text(col, row int, s, style string) bool {
+	rs := []rune(s)
+	if row < 0 || row >= c.height || col < 0 || col+len(rs) > c.width {
+		return false
+	}
+	for i := max(col-1, 0); i < min(col+len(rs)+1, c.width); i++ {
+		if c.chars[row][i] != 0 {
+			return false
+		}
+	}
+	for i, r := range rs {
+		c.chars[row][col+i] = r
+		c.styles[row][col+i] = style
+	}
+	return true
+}
+
+// lines returns the rows of the canvas.
+func (c *brailleCanvas) lines() []string {
+	lines := make([]string, c.height)
+	for row := range c.height {
+		var sb strings.Builder
+		style := ""
+		for col := range c.width {
+			r := c.chars[row][col]
+			if r == 0 && c.dots[row][col] != 0 {
+				r = 0x2800 + c.dots[row][col]
+			}
+			if r != 0 && c.styles[row][col] != style {
+				if style != "" {
+					sb.WriteString(End)
+				}
+				style = c.styles[row][col]
+				sb.WriteString(style)
+			}
+			if r == 0 {
+				sb.WriteByte(' ')
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+		if style != "" {
+			sb.WriteString(End)
+		}
+		lines[row] = sb.String()
+	}
+	return lines
+}
+
+// abs returns the absolute value of n.
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// Point is a point of a LineChart series.
+type Point struct {
+	X, Y float64
+}
+
+// LineSeries is a series of points of a LineChart.
+type LineSeries struct {
+	Name   string
+	Points []Point
+	Style  string // The default is a color of its own.
+}
+
+// LineChart plots series of points as lines, or dots in scatter mode, with
+// braille characters of 2×4 dots per cell, and a legend of the series when
+// there are several. Series can be appended to as new values arrive, for
+// streaming metrics.
+type LineChart struct {
+	Series       []*LineSeries
+	Scatter      bool      // Whether points are drawn as dots rather than joined by lines.
+	Axes         bool      // Whether the axes are drawn, with the range of values.
+	XAxis, YAxis ChartAxis // Ticks, unit and title of each axis. The values of points are formatted as for YAxis.
+	ShowValues   bool      // Whether the value of each point is shown above it, where there is room.
+	Window       int       // Points kept by Append in each series; 0 keeps all.
+	Width        int       // Width of String; 0 uses the width of the terminal.
+	Height       int       // Height of String; 0 uses 10 rows.
+
+	min, max *float64 // Fixed range of Y.
+}
+
+// NewLineChart creates a line chart with axes.
+func NewLineChart() *LineChart {
+	return &LineChart{Axes: true}
+}
+
+// Add adds a series of values at X 0, 1, 2 and so on, with style, or a
+// color of its own if style is "".
+func (c *LineChart) Add(name string, values []float64, style string) *LineSeries {
+	s := &LineSeries{Name: name, Style: style}
+	c.Series = append(c.Series, s)
+	c.Append(len(c.Series)-1, values...)
+	return s
+}
+
+// AddPoints adds a series of points with style, or a color of its own if
+// style is "".
+func (c *LineChart) AddPoints(name string, points []Point, style string) *LineSeries {
+	s := &LineSeries{Name: name, Points: points, Style: style}
+	c.Series = append(c.Series, s)
+	return s
+}
+
+// Append adds values to series i, each at the X after the last point, and
+// drops the oldest points beyond Window.
+func (c *LineChart) Append(i int, values ...float64) {
+	s := c.Series[i]
+	x := 0.0
+	if len(s.Points) > 0 {
+		x = s.Points[len(s.Points)-1].X + 1
+	}
+	for _, v := range values {
+		s.Points = append(s.Points, Point{x, v})
+		x++
+	}
+	if c.Window > 0 && len(s.Points) > c.Window {
+		s.Points = append(s.Points[:0], s.Points[len(s.Points)-c.Window:]...)
+	}
+}
+
+// SetRange fixes the range of Y drawn instead of fitting it to the points.
+func (c *LineChart) SetRange(min, max float64) {
+	c.min, c.max = &min, &max
+}
+
+// Print prints the chart to stdout.
+func (c *LineChart) Print() {
+	fmt.Print(c.String())
+}
+
+// String renders the chart in Width and Height, ending with a newline.
+func (c *LineChart) String() string {
+	width := c.Width
+	if width <= 0 {
+		width, _ = termSize(os.Stdout)
+	}
+	height := c.Height
+	if height <= 0 {
+		height = 10
+	}
+	return strings.Join(c.Lines(width, height), "\n") + "\n"
+}
+
+// style returns the style of series i.
+func (c *LineChart) style(i int) string {
+	if c.Series[i].Style != "" {
+		return c.Series[i].Style
+	}
+	return chartPalette[i%len(chartPalette)]
+}
+
+// bounds returns the range of the points, or of Y as set with SetRange,
+// leaving out NaN and infinite values.
+func (c *LineChart) bounds() (xmin, xmax, ymin, ymax float64) {
+	xmin, ymin = math.Inf(1), math.Inf(1)
+	xmax, ymax = math.Inf(-1), math.Inf(-1)
+	for _, s := range c.Series {
+		for _, p := range s.Points {
+			if !finite(p.X) || !finite(p.Y) {
+				continue
+			}
+			xmin, xmax = min(xmin, p.X), max(xmax, p.X)
+			ymin, ymax = min(ymin, p.Y), max(ymax, p.Y)
+		}
+	}
+	if c.min != nil {
+		ymin, ymax = *c.min, *c.max
+	}
+	return xmin, xmax, ymin, ymax
+}
+
+// Lines returns the chart fitted in width columns and height rows, for
+// drawing it in a Pane, GridCell or Tab.
+func (c *LineChart) Lines(width, height int) []string {
+	xmin, xmax, ymin, ymax := c.bounds()
+	if math.IsInf(xmin, 1) {
+		xmin, xmax, ymin, ymax = 0, 1, 0, 1
+	}
+	names, styles := make([]string, len(c.Series)), make([]string, len(c.Series))
+	for i, s := range c.Series {
+		names[i], styles[i] = s.Name, c.style(i)
+	}
+	legend := chartLegend(names, styles, width)
+
+	rows := height - len(legend)
+	var xTicks, yTicks []float64
+	labelWidth := 0
+	if c.Axes {
+		rows -= 2
+		if c.XAxis.Title != "" {
+			rows--
+		}
+		if c.YAxis.Title != "" {
+			rows--
+		}
+		xTicks, yTicks = c.XAxis.ticks(xmin, xmax), c.YAxis.ticks(ymin, ymax)
+		for _, v := range yTicks {
+			labelWidth = max(labelWidth, visibleWidth(c.YAxis.label(v)))
+		}
+	}
+	cols := width - labelWidth
+	if c.Axes {
+		cols--
+	}
+	if rows < 1 || cols < 1 {
+		return nil
+	}
+
+	canvas := newBrailleCanvas(cols, rows)
+	dotX := func(x float64) float64 {
+		if xmax == xmin {
+			return float64(cols)
+		}
+		return (x - xmin) / (xmax - xmin) * float64(cols*2-1)
+	}
+	dotY := func(y float64) float64 {
+		if ymax == ymin {
+			return float64(rows * 2)
+		}
+		return (ymax - y) / (ymax - ymin) * float64(rows*4-1)
+	}
+	for i, s := range c.Series {
+		style := c.style(i)
+		prev := -1
+		for j, p := range s.Points {
+			if !finite(p.X) || !finite(p.Y) {
+				prev = -1
+				continue
+			}
+			x, y := dotX(p.X), dotY(p.Y)
+			if c.Scatter || prev < 0 {
+				canvas.point(x, y, style)
+			} else {
+				canvas.line(dotX(s.Points[prev].X), dotY(s.Points[prev].Y), x, y, style)
+			}
+			prev = j
+		}
+	}
+	if c.ShowValues {
+		for i, s := range c.Series {
+			for _, p := range s.Points {
+				y := math.Round(dotY(p.Y))
+				if !finite(p.X) || !finite(p.Y) || y < 0 || y >= float64(rows*4) {
+					continue
+				}
+				label := c.YAxis.label(p.Y)
+				n := len([]rune(label))
+				col := max(min(int(math.Round(dotX(p.X)))/2-n/2, cols-n), 0)
+				row := int(y) / 4
+				if !canvas.text(col, row-1, label, c.style(i)) {
+					canvas.text(col, row+1, label, c.style(i))
+				}
+			}
+		}
+	}
+	lines := canvas.lines()
+
+	if c.Axes {
+		marks := make(map[int]string)
+		for _, v := range yTicks {
+			marks[int(math.Round(dotY(v)))/4] = c.YAxis.label(v)
+		}
+		for row := range lines {
+			label, ok := marks[row]
+			axis := "│"
+			if ok {
+				axis = "┤"
+			}
+			lines[row] = Faint + strings.Repeat(" ", labelWidth-visibleWidth(label)) + label + axis + End + lines[row]
+		}
+		xCols, xLabels := make([]int, len(xTicks)), make([]string, len(xTicks))
+		for i, v := range xTicks {
+			xCols[i], xLabels[i] = int(math.Round(dotX(v)))/2, c.XAxis.label(v)
+		}
+		margin := strings.Repeat(" ", labelWidth+1)
+		lines = append(lines, Faint+strings.Repeat(" ", labelWidth)+axisLine(c.XAxis.marks(xCols), cols)+End)
+		lines = append(lines, Faint+margin+axisLabels(xCols, xLabels, cols)+End)
+		if title := c.XAxis.Title; title != "" {
+			lines = append(lines, Faint+margin+truncateWidth(centerText(title, cols), cols)+End)
+		}
+		if title := c.YAxis.Title; title != "" {
+			lines = append([]string{Faint + truncateWidth(title, width) + End}, lines...)
+		}
+	}
+	return append(lines, legend...)
+}
@@ -0,0 +1,23 @@
+package ansi
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestLineChartOutOfRange(t *testing.T) {
+	c := NewLineChart()
+	c.SetRange(0, 10)
+	c.Add("cpu", []float64{5, 1e15, -1e15, 5, math.Inf(1), 5, math.NaN(), math.Inf(-1)}, "")
+	c.AddPoints("odd", []Point{{0, 5}, {math.Inf(1), 5}, {7, 5}}, "")
+
+	lines := c.Lines(40, 12)
+	if len(lines) != 12 {
+		t.Fatalf("got %d lines, want 12", len(lines))
+	}
+	// The line from 5 to 1e15 is cut where it leaves through the top.
+	if !strings.ContainsFunc(lines[0], func(r rune) bool { return r > 0x2800 && r <= 0x28ff }) {
+		t.Errorf("top row %q has no dots of the clipped line", lines[0])
+	}
+}
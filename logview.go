@@ -0,0 +1,392 @@
+package ansi
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// --------------------
+// Log Viewer
+// --------------------
+
+// LogLevel is the severity of a log line, as detected by a LogViewer.
+type LogLevel int
+
+// Log levels, from least to most severe.
+const (
+	LogUnknown LogLevel = iota
+	LogDebug
+	LogInfo
+	LogWarn
+	LogError
+)
+
+// String returns the name of the level.
+func (l LogLevel) String() string {
+	switch l {
+	case LogDebug:
+		return "debug"
+	case LogInfo:
+		return "info"
+	case LogWarn:
+		return "warn"
+	case LogError:
+		return "error"
+	}
+	return "all"
+}
+
+// logLevelWords maps the words marking a level to the level.
+var logLevelWords = map[string]LogLevel{
+	"trace": LogDebug, "debug": LogDebug, "dbg": LogDebug,
+	"info": LogInfo, "inf": LogInfo, "notice": LogInfo,
+	"warn": LogWarn, "warning": LogWarn, "wrn": LogWarn,
+	"error": LogError, "err": LogError, "fatal": LogError, "panic": LogError, "crit": LogError, "critical": LogError,
+}
+
+// detectLogLevel returns the level named by one of the first words of line,
+// like "ERROR", "[warn]" or "level=info".
+func detectLogLevel(line string) LogLevel {
+	words := strings.FieldsFunc(stripANSI(line), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for i, word := range words {
+		if i == 8 {
+			break
+		}
+		if level, ok := logLevelWords[strings.ToLower(word)]; ok {
+			return level
+		}
+	}
+	return LogUnknown
+}
+
+// logLevelStyles are the colors of log lines by level.
+var logLevelStyles = map[LogLevel]string{
+	LogDebug: Faint,
+	LogWarn:  Yellow,
+	LogError: Red,
+}
+
+// logLine is a line shown by a LogViewer.
+type logLine struct {
+	text  string
+	level LogLevel
+}
+
+// logViewer is the state of a running ViewLogs or ViewLogLines.
+type logViewer struct {
+	cfg  *inputConfig
+	mu   sync.Mutex
+	view *Viewport
+
+	lines    []logLine
+	filter   string   // Only lines containing this text are shown.
+	level    LogLevel // Only lines of this level or above are shown.
+	follow   bool     // Whether new lines scroll the view.
+	ended    bool     // Whether the input has ended.
+	readErr  *error   // Where ViewLogs leaves the error that ended the input.
+	err      error    // The error that ended the input, once it has ended.
+	dirty    bool     // Whether the screen needs drawing.
+	typing   bool     // Whether the status line is being edited.
+	received int      // Lines received since the view stopped following.
+}
+
+// ViewLogs shows the lines read from r in the alternate screen as they
+// arrive, colored by the level each line names: debug lines are faint,
+// warnings yellow and errors red. The view follows new lines until it is
+// scrolled with the arrow keys, PageUp or PageDown, or paused with Space; G
+// or End goes back to following. / filters the lines by text and l cycles
+// through the lowest level shown. q, Escape and Ctrl-C quit, leaving r to
+// the caller, and the error reading r, if any, is shown on the status line
+// and returned. InputWriter and InputKeyReader may be given in opts. Only
+// the last 100000 lines are kept.
+func ViewLogs(r io.Reader, opts ...InputOption) error {
+	lines := make(chan string)
+	done := make(chan struct{})
+	defer close(done)
+	var readErr error
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(nil, 1<<20)
+		for scanner.Scan() {
+			select {
+			case lines <- scanner.Text():
+			case <-done:
+				return
+			}
+		}
+		readErr = scanner.Err()
+	}()
+	return viewLogs(lines, &readErr, opts)
+}
+
+// ViewLogLines is ViewLogs for lines sent on a channel. The viewer stays
+// open after the channel is closed, until it is quit.
+func ViewLogLines(lines <-chan string, opts ...InputOption) error {
+	return viewLogs(lines, nil, opts)
+}
+
+// maxLogLines is the most lines a log viewer keeps. Older lines are dropped
+// a tenth at a time as new ones arrive.
+const maxLogLines = 100000
+
+// viewLogs runs the viewer of ViewLogs and ViewLogLines. If readErr is set,
+// the error it points to once lines is closed is the one that ended them.
+func viewLogs(lines <-chan string, readErr *error, opts []InputOption) error {
+	cfg := &inputConfig{out: os.Stdout}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.keys == nil {
+		cfg.keys = defaultKeyReader()
+	}
+	lv := &logViewer{cfg: cfg, view: NewViewport(1, 1, 0, 0), follow: true, dirty: true, readErr: readErr}
+
+	fmt.Fprint(cfg.out, "\033[?1049h\033[?25l")
+	defer fmt.Fprint(cfg.out, "\033[?25h\033[?1049l")
+	quit := make(chan struct{})
+	defer close(quit)
+	go lv.receive(lines, quit)
+	go lv.render(quit)
+
+	for {
+		keyType, key, err := cfg.keys.readKey()
+		if err != nil {
+			return err
+		}
+		if keyType == "Character" && key == "/" {
+			if err := lv.readFilter(); err != nil {
+				return err
+			}
+			continue
+		}
+		lv.mu.Lock()
+		v := lv.view
+		switch {
+		case keyType == "Arrow" && key == "up", keyType == "Character" && key == "k":
+			lv.pause()
+			v.ScrollUp(1)
+		case keyType == "Arrow" && key == "down", keyType == "Character" && key == "j":
+			lv.pause()
+			v.ScrollDown(1)
+		case keyType == "Special" && key == "pageup":
+			lv.pause()
+			v.PageUp()
+		case keyType == "Special" && key == "pagedown":
+			lv.pause()
+			v.PageDown()
+		case keyType == "Special" && key == "home", keyType == "Character" && key == "g":
+			lv.pause()
+			v.GotoTop()
+		case keyType == "Special" && key == "end", keyType == "Character" && key == "G":
+			lv.resume()
+		case keyType == "Character" && key == " ":
+			if lv.follow {
+				lv.pause()
+			} else {
+				lv.resume()
+			}
+		case keyType == "Character" && key == "l":
+			lv.level = (lv.level + 1) % (LogError + 1)
+			lv.refilter()
+		case keyType == "Character" && key == "q", keyType == "Special" && (key == "escape" || key == "ctrl-c"):
+			err := lv.err
+			lv.mu.Unlock()
+			return err
+		}
+		lv.dirty = true
+		lv.draw()
+		lv.mu.Unlock()
+	}
+}
+
+// pause stops following new lines.
+func (lv *logViewer) pause() {
+	if lv.follow {
+		lv.follow = false
+		lv.received = 0
+	}
+}
+
+// resume follows new lines again.
+func (lv *logViewer) resume() {
+	lv.follow = true
+	lv.view.GotoBottom()
+}
+
+// receive adds the lines to the viewer until the channel is closed or the
+// viewer quits.
+func (lv *logViewer) receive(lines <-chan string, quit chan struct{}) {
+	for {
+		select {
+		case <-quit:
+			return
+		case text, ok := <-lines:
+			lv.mu.Lock()
+			if !ok {
+				lv.ended = true
+				if lv.readErr != nil {
+					lv.err = *lv.readErr
+				}
+				lv.dirty = true
+				lv.mu.Unlock()
+				return
+			}
+			line := logLine{text, detectLogLevel(text)}
+			lv.lines = append(lv.lines, line)
+			if lv.shows(line) {
+				top := lv.view.top
+				lv.view.AppendLine(lv.styled(line))
+				if lv.follow {
+					lv.view.GotoBottom()
+				} else {
+					lv.view.ScrollTo(top)
+					lv.received++
+				}
+				lv.dirty = true
+			}
+			lv.trim()
+			lv.mu.Unlock()
+		}
+	}
+}
+
+// render draws the screen when it changed, at most 20 times a second, until
+// the viewer quits.
+func (lv *logViewer) render(quit chan struct{}) {
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-quit:
+			return
+		case <-ticker.C:
+			lv.mu.Lock()
+			lv.draw()
+			lv.mu.Unlock()
+		}
+	}
+}
+
+// readFilter reads the filter text on the status line and applies it.
+func (lv *logViewer) readFilter() error {
+	lv.mu.Lock()
+	lv.typing = true
+	row := lv.view.Height + 1
+	lv.mu.Unlock()
+	filter, err := readStatusLine(lv.cfg, row, "filter: ")
+
+	lv.mu.Lock()
+	defer lv.mu.Unlock()
+	lv.typing = false
+	if err != nil {
+		return err
+	}
+	lv.filter = filter
+	lv.refilter()
+	lv.dirty = true
+	lv.draw()
+	return nil
+}
+
+// shows reports whether line passes the filter and level.
+func (lv *logViewer) shows(line logLine) bool {
+	if lv.level > LogUnknown && line.level < lv.level {
+		return false
+	}
+	return lv.filter == "" || strings.Contains(strings.ToLower(stripANSI(line.text)), strings.ToLower(lv.filter))
+}
+
+// styled returns the line colored by its level.
+func (lv *logViewer) styled(line logLine) string {
+	if style := logLevelStyles[line.level]; style != "" {
+		return style + stripANSI(line.text) + End
+	}
+	return line.text
+}
+
+// shown returns the lines that pass the filter and level, styled.
+func (lv *logViewer) shown() []string {
+	var lines []string
+	for _, line := range lv.lines {
+		if lv.shows(line) {
+			lines = append(lines, lv.styled(line))
+		}
+	}
+	return lines
+}
+
+// refilter fills the view again with the lines that pass the filter and
+// level, and follows them.
+func (lv *logViewer) refilter() {
+	lv.view.Highlight = lv.filter
+	lv.view.SetLines(lv.shown())
+	lv.resume()
+}
+
+// trim drops the oldest lines once there are a tenth more than maxLogLines,
+// keeping the view on the lines it shows.
+func (lv *logViewer) trim() {
+	if len(lv.lines) < maxLogLines+maxLogLines/10 {
+		return
+	}
+	v := lv.view
+	rows, top := len(v.wrapped()), v.top
+	lv.lines = append(lv.lines[:0], lv.lines[len(lv.lines)-maxLogLines:]...)
+	v.SetLines(lv.shown())
+	if lv.follow {
+		v.GotoBottom()
+	} else {
+		v.ScrollTo(top - (rows - len(v.wrapped())))
+	}
+}
+
+// draw redraws the screen if it changed.
+func (lv *logViewer) draw() {
+	width, height := termSize(lv.cfg.out)
+	v := lv.view
+	if width != v.Width || height-1 != v.Height {
+		v.Width, v.Height = width, max(height-1, 1)
+		if lv.follow {
+			v.GotoBottom()
+		}
+		lv.dirty = true
+	}
+	if !lv.dirty || lv.typing {
+		return
+	}
+	lv.dirty = false
+
+	var status []string
+	if lv.follow {
+		status = append(status, "FOLLOW")
+	} else {
+		paused := "PAUSED"
+		if lv.received > 0 {
+			paused += fmt.Sprintf(" (%d new)", lv.received)
+		}
+		status = append(status, paused)
+	}
+	status = append(status, fmt.Sprintf("%d lines", len(lv.lines)))
+	if lv.level > LogUnknown {
+		status = append(status, "level ≥ "+lv.level.String())
+	}
+	if lv.filter != "" {
+		status = append(status, fmt.Sprintf("filter %q", lv.filter))
+	}
+	if lv.err != nil {
+		status = append(status, "error: "+lv.err.Error())
+	} else if lv.ended {
+		status = append(status, "(EOF)")
+	}
+	line := truncateWidth(" "+strings.Join(status, " · ")+" ", width)
+	fmt.Fprint(lv.cfg.out, v.Render()+fmt.Sprintf("\033[%d;1H\033[2K", v.Height+1)+Negative+line+End)
+}
@@ -0,0 +1,71 @@
+package ansi
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+// syncBuffer is a bytes.Buffer safe for writing and reading at once.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// endlessLog is a log that never ends.
+type endlessLog struct{}
+
+func (endlessLog) Read(b []byte) (int, error) {
+	return copy(b, "info: more\n"), nil
+}
+
+func TestViewLogsQuit(t *testing.T) {
+	before := runtime.NumGoroutine()
+	err := ViewLogs(endlessLog{}, InputKeyReader(NewKeyReader(strings.NewReader("q"))), InputWriter(io.Discard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Once quit, the goroutine reading the lines stops.
+	for deadline := time.Now().Add(time.Second); runtime.NumGoroutine() > before; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d goroutines left running, had %d", runtime.NumGoroutine(), before)
+		}
+	}
+}
+
+func TestViewLogsReadError(t *testing.T) {
+	errRead := errors.New("connection reset")
+	r := io.MultiReader(strings.NewReader("info: started\n"), iotest.ErrReader(errRead))
+	keys, typed := io.Pipe()
+	out := &syncBuffer{}
+	go func() {
+		for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+			if strings.Contains(out.String(), "error: connection reset") {
+				break
+			}
+		}
+		typed.Write([]byte("q"))
+	}()
+	err := ViewLogs(r, InputKeyReader(NewKeyReader(keys)), InputWriter(out))
+	if !errors.Is(err, errRead) {
+		t.Errorf("ViewLogs = %v, want %v", err, errRead)
+	}
+}
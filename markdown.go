@@ -0,0 +1,379 @@
+package ansi
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// --------------------
+// Markdown
+// --------------------
+
+// Markdown renders Markdown for the terminal, word-wrapped to its width:
+// headings, paragraphs with bold, italic, struck-through and code spans and
+// links, lists (nested, numbered and task lists), block quotes, tables,
+// fenced code blocks and rules. Other HTML-like syntax is kept as written.
+// The result ends with a newline.
+func Markdown(src string) string {
+	width, _ := termSize(os.Stdout)
+	return strings.Join(renderMarkdown(src, width), "\n") + "\n"
+}
+
+var (
+	mdHeading   = regexp.MustCompile(`^ {0,3}(#{1,6})(?:\s+(.*?))?(?:\s+#+)?\s*$`)
+	mdRule      = regexp.MustCompile(`^ {0,3}(?:(?:-\s*){3,}|(?:\*\s*){3,}|(?:_\s*){3,})$`)
+	mdListItem  = regexp.MustCompile(`^(\s*)([-*+]|\d{1,9}[.)])(?:\s+(.*))?$`)
+	mdTableRule = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+)
+
+// mdHeadingStyles are the styles of headings by level.
+var mdHeadingStyles = []string{Purple + Bold + Underline, Cyan + Bold, Bold, Bold + Italic, Bold + Italic, Bold + Italic}
+
+// renderMarkdown returns the lines of src rendered in width columns.
+func renderMarkdown(src string, width int) []string {
+	width = max(width, 10)
+	lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+	var out []string
+	block := func(b []string) {
+		if len(out) > 0 {
+			out = append(out, "")
+		}
+		out = append(out, b...)
+	}
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			i++
+		case mdFence(trimmed) != "":
+			fence := mdFence(trimmed)
+			lang := strings.TrimSpace(strings.TrimLeft(trimmed, fence[:1]))
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), fence); i++ {
+				code = append(code, lines[i])
+			}
+			i++
+			block(mdCode(code, lang, width))
+		case mdHeading.MatchString(line):
+			m := mdHeading.FindStringSubmatch(line)
+			style := mdHeadingStyles[len(m[1])-1]
+			block(wrapText(style+mdInline(m[2], style)+End, width))
+			i++
+		case mdRule.MatchString(line):
+			block([]string{Faint + strings.Repeat("─", width) + End})
+			i++
+		case strings.HasPrefix(trimmed, ">"):
+			var quote []string
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">"); i++ {
+				q := strings.TrimPrefix(strings.TrimSpace(lines[i]), ">")
+				quote = append(quote, strings.TrimPrefix(q, " "))
+			}
+			inner := renderMarkdown(strings.Join(quote, "\n"), width-2)
+			for j, l := range inner {
+				inner[j] = Faint + "│" + End + " " + l
+			}
+			block(inner)
+		case mdListItem.MatchString(line) && !mdRule.MatchString(line):
+			start := i
+			for i++; i < len(lines); i++ {
+				next := lines[i]
+				if strings.TrimSpace(next) == "" {
+					// A blank line ends the list unless it goes on after it.
+					if i+1 < len(lines) && (mdListItem.MatchString(lines[i+1]) || strings.HasPrefix(lines[i+1], "  ")) {
+						continue
+					}
+					break
+				}
+				if !mdListItem.MatchString(next) && !strings.HasPrefix(next, " ") && mdStartsBlock(next) {
+					break
+				}
+			}
+			block(mdList(lines[start:i], width))
+		case strings.HasPrefix(trimmed, "|") && i+1 < len(lines) && mdTableRule.MatchString(lines[i+1]):
+			start := i
+			for i += 2; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), "|"); i++ {
+			}
+			block(mdTable(lines[start:i], width))
+		default:
+			var text strings.Builder
+			for ; i < len(lines) && strings.TrimSpace(lines[i]) != ""; i++ {
+				l := lines[i]
+				if text.Len() > 0 {
+					if mdStartsBlock(l) {
+						break
+					}
+					text.WriteString(" ")
+				}
+				// Two trailing spaces or a backslash break the line.
+				hard := strings.HasSuffix(l, "  ") || strings.HasSuffix(l, "\\")
+				text.WriteString(strings.TrimSuffix(strings.TrimSpace(l), "\\"))
+				if hard {
+					text.WriteString("\n")
+				}
+			}
+			block(wrapText(mdInline(strings.TrimSpace(text.String()), ""), width))
+		}
+	}
+	return out
+}
+
+// mdFence returns the fence a line of a fenced code block starts with, or ""
+// if it does not start one.
+func mdFence(line string) string {
+	for _, c := range []string{"`", "~"} {
+		n := len(line) - len(strings.TrimLeft(line, c))
+		if n >= 3 {
+			return strings.Repeat(c, n)
+		}
+	}
+	return ""
+}
+
+// mdStartsBlock reports whether line starts a block other than a paragraph,
+// ending the paragraph before it.
+func mdStartsBlock(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return mdFence(trimmed) != "" || mdHeading.MatchString(line) || mdRule.MatchString(line) ||
+		strings.HasPrefix(trimmed, ">") || mdListItem.MatchString(line) && strings.TrimSpace(mdListItem.FindStringSubmatch(line)[3]) != ""
+}
+
+// mdCode returns the lines of a code block, cut to width with a bar on the
+// left and the language above them.
+func mdCode(code []string, lang string, width int) []string {
+	var lines []string
+	if lang != "" {
+		lines = append(lines, Faint+lang+End)
+	}
+	for _, l := range code {
+		l = strings.ReplaceAll(l, "\t", "    ")
+		lines = append(lines, Faint+"│"+End+" "+Yellow+truncateWidth(l, width-2)+End)
+	}
+	return lines
+}
+
+// mdList returns the lines of a list, each item wrapped with a hanging
+// indent and nested items indented below their parent.
+func mdList(lines []string, width int) []string {
+	type item struct {
+		depth        int
+		marker, text string
+	}
+	var items []item
+	var indents []int
+	for _, l := range lines {
+		l = strings.ReplaceAll(l, "\t", "    ")
+		m := mdListItem.FindStringSubmatch(l)
+		if m == nil {
+			if t := strings.TrimSpace(l); t != "" && len(items) > 0 {
+				items[len(items)-1].text += " " + t
+			}
+			continue
+		}
+		indent := len(m[1])
+		for len(indents) > 0 && indent < indents[len(indents)-1] {
+			indents = indents[:len(indents)-1]
+		}
+		if len(indents) == 0 || indent > indents[len(indents)-1] {
+			indents = append(indents, indent)
+		}
+		items = append(items, item{len(indents) - 1, m[2], m[3]})
+	}
+
+	var out []string
+	for _, it := range items {
+		marker := Cyan + it.marker + End
+		if !strings.ContainsAny(it.marker, ".)") {
+			marker = Cyan + []string{"•", "◦", "▪"}[it.depth%3] + End
+		}
+		text := it.text
+		switch {
+		case strings.HasPrefix(text, "[ ] "):
+			marker += " ☐"
+			text = text[4:]
+		case strings.HasPrefix(text, "[x] "), strings.HasPrefix(text, "[X] "):
+			marker += " " + Green + "☑" + End
+			text = text[4:]
+		}
+		indent := strings.Repeat("  ", it.depth)
+		hang := visibleWidth(indent+marker) + 1
+		for j, l := range wrapText(mdInline(text, ""), width-hang) {
+			if j == 0 {
+				out = append(out, indent+marker+" "+l)
+			} else {
+				out = append(out, strings.Repeat(" ", hang)+l)
+			}
+		}
+	}
+	return out
+}
+
+// mdTable returns the lines of a table: its header row, the row of dashes
+// setting the alignment of the columns and the other rows.
+func mdTable(lines []string, width int) []string {
+	cells := func(row string) []string {
+		row = strings.TrimSpace(row)
+		row = strings.TrimSuffix(strings.TrimPrefix(row, "|"), "|")
+		var cells []string
+		var cell strings.Builder
+		for i := 0; i < len(row); i++ {
+			switch {
+			case row[i] == '\\' && i+1 < len(row) && row[i+1] == '|':
+				cell.WriteByte('|')
+				i++
+			case row[i] == '|':
+				cells = append(cells, strings.TrimSpace(cell.String()))
+				cell.Reset()
+			default:
+				cell.WriteByte(row[i])
+			}
+		}
+		return append(cells, strings.TrimSpace(cell.String()))
+	}
+	inline := func(row []string) []string {
+		for i, c := range row {
+			row[i] = mdInline(c, "")
+		}
+		return row
+	}
+
+	t := NewTable(inline(cells(lines[0]))...)
+	t.Width = width
+	for i, rule := range cells(lines[1]) {
+		switch {
+		case strings.HasPrefix(rule, ":") && strings.HasSuffix(rule, ":"):
+			t.SetAlign(i, AlignCenter)
+		case strings.HasSuffix(rule, ":"):
+			t.SetAlign(i, AlignRight)
+		}
+	}
+	for _, row := range lines[2:] {
+		t.AddRow(inline(cells(row))...)
+	}
+	for i := range len(t.Header) {
+		t.SetMaxWidth(i, 0, OverflowWrap)
+	}
+	return strings.Split(strings.TrimSuffix(t.String(), "\n"), "\n")
+}
+
+// mdInline renders the spans of a line of Markdown. active is the style the
+// text is in, set again after each span ends.
+func mdInline(s, active string) string {
+	var sb strings.Builder
+	// span finds the text between delim at i and the next delim, if any.
+	span := func(i int, delim string) (string, bool) {
+		rest := s[i+len(delim):]
+		end := strings.Index(rest, delim)
+		if end <= 0 || rest[0] == ' ' || rest[end-1] == ' ' {
+			return "", false
+		}
+		return rest[:end], true
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && strings.IndexByte("\\`*_{}[]()<>#+-.!|~", s[i+1]) >= 0:
+			sb.WriteByte(s[i+1])
+			i++
+			continue
+		case c == '`':
+			n := len(s[i:]) - len(strings.TrimLeft(s[i:], "`"))
+			delim := s[i : i+n]
+			if end := strings.Index(s[i+n:], delim); end >= 0 {
+				code := s[i+n : i+n+end]
+				if t := strings.TrimSpace(code); t != "" {
+					code = t
+				}
+				sb.WriteString(Yellow + code + End + active)
+				i += 2*n + end - 1
+				continue
+			}
+		case strings.HasPrefix(s[i:], "**") || strings.HasPrefix(s[i:], "__") && mdWordStart(s, i):
+			if inner, ok := span(i, s[i:i+2]); ok {
+				sb.WriteString(Bold + mdInline(inner, active+Bold) + End + active)
+				i += len(inner) + 3
+				continue
+			}
+		case strings.HasPrefix(s[i:], "~~"):
+			if inner, ok := span(i, "~~"); ok {
+				sb.WriteString(Crossed + mdInline(inner, active+Crossed) + End + active)
+				i += len(inner) + 3
+				continue
+			}
+		case c == '*' || c == '_' && mdWordStart(s, i):
+			if inner, ok := span(i, s[i:i+1]); ok {
+				sb.WriteString(Italic + mdInline(inner, active+Italic) + End + active)
+				i += len(inner) + 1
+				continue
+			}
+		case c == '!' && strings.HasPrefix(s[i:], "!["):
+			if text, _, n := mdLink(s[i+1:]); n > 0 {
+				sb.WriteString(Faint + "[" + text + "]" + End + active)
+				i += n
+				continue
+			}
+		case c == '[':
+			if text, url, n := mdLink(s[i:]); n > 0 {
+				style := Blue + Underline
+				sb.WriteString(Link(url, style+mdInline(text, active+style)+End+active))
+				i += n - 1
+				continue
+			}
+		case c == '<':
+			if end := strings.IndexByte(s[i:], '>'); end > 0 {
+				url := s[i+1 : i+end]
+				if strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") || strings.HasPrefix(url, "mailto:") {
+					sb.WriteString(Link(url, Blue+Underline+url+End+active))
+					i += end
+					continue
+				}
+			}
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
+
+// mdWordStart reports whether s[i] is not in the middle of a word, so an
+// underscore there can start emphasis rather than being part of a name.
+func mdWordStart(s string, i int) bool {
+	if i == 0 {
+		return true
+	}
+	r := rune(s[i-1])
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// mdLink parses a link like "[text](url)" at the start of s, returning its
+// text, URL and length, or a length of 0 if s does not start with one.
+func mdLink(s string) (text, url string, n int) {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '[':
+			depth++
+		case ']':
+			if depth--; depth > 0 {
+				continue
+			}
+			if i+1 >= len(s) || s[i+1] != '(' {
+				return "", "", 0
+			}
+			end := strings.IndexByte(s[i+2:], ')')
+			if end < 0 {
+				return "", "", 0
+			}
+			url, _, _ = strings.Cut(strings.TrimSpace(s[i+2:i+2+end]), " ")
+			return s[1:i], url, i + 3 + end
+		}
+	}
+	return "", "", 0
+}
+
+// PrintMarkdown prints Markdown rendered by Markdown to stdout.
+func PrintMarkdown(src string) {
+	fmt.Print(Markdown(src))
+}
@@ -0,0 +1,185 @@
+package ansi
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// --------------------
+// Marquee
+// --------------------
+
+// Marquee scrolls text too long for its width sideways, like a now-playing
+// line or a long status message. Text that fits is shown still. Which part
+// is shown depends only on the time since the marquee was created or its
+// text set, so it can be drawn from any render loop; Start animates it on
+// the current line instead. It is safe for use from several goroutines.
+type Marquee struct {
+	Speed  time.Duration // Time to move one column. The default is 150ms.
+	Pause  time.Duration // Time held before scrolling on. The default is 1s.
+	Bounce bool          // Whether the text scrolls back and forth rather than around.
+	Gap    int           // Columns between the end and the start of the text scrolling around. The default is 4.
+	Style  string
+	Width  int // Width drawn by Start; 0 uses the width of the terminal.
+
+	mu      sync.Mutex
+	text    string
+	start   time.Time
+	out     io.Writer
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// NewMarquee creates a marquee of text, which should not contain escape
+// sequences; color it with Style.
+func NewMarquee(text string) *Marquee {
+	return &Marquee{Speed: 150 * time.Millisecond, Pause: time.Second, Gap: 4, text: text, start: time.Now(), out: os.Stdout}
+}
+
+// SetText replaces the text and starts scrolling it from the beginning.
+func (m *Marquee) SetText(text string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.text = text
+	m.start = time.Now()
+}
+
+// Text returns the text.
+func (m *Marquee) Text() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.text
+}
+
+// Frame returns the part of the text shown in width columns at time t.
+func (m *Marquee) Frame(width int, t time.Time) string {
+	m.mu.Lock()
+	text, start := m.text, m.start
+	m.mu.Unlock()
+	if width <= 0 {
+		return ""
+	}
+	textWidth := visibleWidth(text)
+	if textWidth <= width {
+		return m.styled(text)
+	}
+	speed := max(m.Speed, time.Millisecond)
+	pause := max(m.Pause, 0)
+	elapsed := max(t.Sub(start), 0)
+
+	var offset int
+	if m.Bounce {
+		// Pause at the start, scroll to the end, pause there and scroll back.
+		span := textWidth - width
+		leg := pause + time.Duration(span)*speed
+		pos := elapsed % (2 * leg)
+		back := pos >= leg
+		if back {
+			pos -= leg
+		}
+		offset = min(int(max(pos-pause, 0)/speed), span)
+		if back {
+			offset = span - offset
+		}
+		return m.styled(cutWidth(skipWidth(text, offset), width))
+	}
+
+	// Pause at the start and scroll around until the start comes back.
+	gap := max(m.Gap, 1)
+	cycle := textWidth + gap
+	pos := elapsed % (pause + time.Duration(cycle)*speed)
+	offset = int(max(pos-pause, 0) / speed)
+	loop := text + strings.Repeat(" ", gap) + text
+	return m.styled(cutWidth(skipWidth(loop, offset), width))
+}
+
+// styled returns text in the style of the marquee.
+func (m *Marquee) styled(text string) string {
+	if m.Style == "" || text == "" {
+		return text
+	}
+	return m.Style + text + End
+}
+
+// Lines returns the frame of the current time in width columns, for drawing
+// the marquee in a Pane, GridCell or Tab, which should be redrawn at least
+// every Speed while it scrolls.
+func (m *Marquee) Lines(width, height int) []string {
+	if height <= 0 {
+		return nil
+	}
+	return []string{m.Frame(width, time.Now())}
+}
+
+// String returns the frame of the current time in Width, or in the width
+// of the terminal.
+func (m *Marquee) String() string {
+	return m.Frame(m.width(os.Stdout), time.Now())
+}
+
+// width returns Width, or the width of the terminal w writes to.
+func (m *Marquee) width(w io.Writer) int {
+	if m.Width > 0 {
+		return m.Width
+	}
+	width, _ := termSize(w)
+	return width
+}
+
+// SetWriter sets where Start draws. The default is os.Stdout.
+func (m *Marquee) SetWriter(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.out = w
+}
+
+// Start animates the marquee on the current line in the background.
+// Starting a running marquee does nothing.
+func (m *Marquee) Start() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stop != nil {
+		return
+	}
+	m.stop = make(chan struct{})
+	m.stopped = make(chan struct{})
+	fmt.Fprint(m.out, "\033[?25l")
+	go m.run(m.out, m.stop, m.stopped)
+}
+
+// Stop stops the animation and clears its line. Stopping a marquee that is
+// not running does nothing.
+func (m *Marquee) Stop() {
+	m.mu.Lock()
+	if m.stop == nil {
+		m.mu.Unlock()
+		return
+	}
+	close(m.stop)
+	stopped := m.stopped
+	m.stop, m.stopped = nil, nil
+	m.mu.Unlock()
+	<-stopped
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	fmt.Fprint(m.out, "\r\033[2K\033[?25h")
+}
+
+// run redraws the marquee on out every Speed until stop is closed.
+func (m *Marquee) run(out io.Writer, stop, stopped chan struct{}) {
+	defer close(stopped)
+	ticker := time.NewTicker(max(m.Speed, time.Millisecond))
+	defer ticker.Stop()
+	for {
+		fmt.Fprint(out, "\r\033[2K"+m.Frame(m.width(out), time.Now()))
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
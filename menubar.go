@@ -0,0 +1,291 @@
+package ansi
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// --------------------
+// Menu Bar
+// --------------------
+
+// MenuItem is an entry of a DropdownMenu.
+type MenuItem struct {
+	Label    string
+	Shortcut string // Shown at the right, like "Ctrl-S". The menu does not bind it.
+	Disabled bool   // Shown faint and cannot be chosen.
+	Action   func() // Called when the item is chosen, after the menu closes.
+
+	separator bool
+}
+
+// MenuSeparator is a line between groups of items of a DropdownMenu.
+var MenuSeparator = MenuItem{separator: true}
+
+// DropdownMenu is a title of a MenuBar and the items of its dropdown.
+type DropdownMenu struct {
+	Title string
+	Items []MenuItem
+}
+
+// MenuBar shows the titles of menus in a row at the top of the screen, like
+// "File Edit Help", and the items of the open menu in a dropdown below its
+// title. Alt and the first letter of a title opens its menu, as does a click
+// on the title. It can be used inside a full-screen view by sending it every
+// key and mouse event and drawing it last; the view is redrawn when a menu
+// closes, to remove the dropdown.
+type MenuBar struct {
+	Menus       []*DropdownMenu
+	Style       string // Style of the bar. The default is Negative.
+	ActiveStyle string // Style of the open title and the selected item. The default is Cyan and Negative.
+	Border      Border // Border of the dropdowns. BorderRounded if not set with NewMenuBar.
+
+	open     int  // One more than the open menu, or 0 if none is.
+	selected int  // Selected item of the open menu.
+	bar      Rect // Where the bar was last drawn.
+	dropdown Rect // Where the open dropdown was last drawn.
+}
+
+// NewMenuBar creates a menu bar of menus, all closed.
+func NewMenuBar(menus ...*DropdownMenu) *MenuBar {
+	return &MenuBar{Menus: menus, Style: Negative, ActiveStyle: Cyan + Negative, Border: BorderRounded}
+}
+
+// Open reports whether a menu is open.
+func (m *MenuBar) Open() bool {
+	return m.current() >= 0
+}
+
+// OpenMenu opens menu i with its first enabled item selected, or closes the
+// open menu if i is out of range.
+func (m *MenuBar) OpenMenu(i int) {
+	if i < 0 || i >= len(m.Menus) {
+		m.open = 0
+		return
+	}
+	m.open, m.selected = i+1, -1
+	m.move(1)
+}
+
+// current returns the open menu, or -1 if none is.
+func (m *MenuBar) current() int {
+	if m.open > len(m.Menus) {
+		return -1
+	}
+	return m.open - 1
+}
+
+// Close closes the open menu.
+func (m *MenuBar) Close() {
+	m.open = 0
+}
+
+// selectable reports whether item i of the open menu can be chosen.
+func (m *MenuBar) selectable(i int) bool {
+	item := m.Menus[m.current()].Items[i]
+	return !item.separator && !item.Disabled
+}
+
+// move selects the next item of the open menu that can be chosen in
+// direction dir, wrapping around.
+func (m *MenuBar) move(dir int) {
+	items := m.Menus[m.current()].Items
+	for n := 1; n <= len(items); n++ {
+		i := ((m.selected+dir*n)%len(items) + len(items)) % len(items)
+		if m.selectable(i) {
+			m.selected = i
+			return
+		}
+	}
+}
+
+// choose closes the menu and calls the action of item i of the open menu.
+func (m *MenuBar) choose(i int) {
+	item := m.Menus[m.current()].Items[i]
+	m.open = 0
+	if item.Action != nil {
+		item.Action()
+	}
+}
+
+// hotkey returns the menu whose title starts with the letter key, or -1.
+func (m *MenuBar) hotkey(key string) int {
+	r, _ := utf8.DecodeRuneInString(key)
+	for i, menu := range m.Menus {
+		first, _ := utf8.DecodeRuneInString(menu.Title)
+		if unicode.ToLower(first) == unicode.ToLower(r) {
+			return i
+		}
+	}
+	return -1
+}
+
+// HandleKey opens a menu with Alt and the first letter of its title and
+// reports whether the key was used. While a menu is open it uses every key:
+// left and right switch menus, up and down select an item, Enter, Space or
+// the first letter of an item chooses it, and Escape closes the menu.
+func (m *MenuBar) HandleKey(keyType, key string) bool {
+	if keyType == "Special" && strings.HasPrefix(key, "alt-") && len(key) > 4 {
+		if i := m.hotkey(key[4:]); i >= 0 {
+			if i == m.current() {
+				m.Close()
+			} else {
+				m.OpenMenu(i)
+			}
+			return true
+		}
+	}
+	if m.current() < 0 {
+		return false
+	}
+	n := len(m.Menus)
+	switch {
+	case keyType == "Special" && (key == "escape" || key == "ctrl-c"):
+		m.Close()
+	case keyType == "Arrow" && key == "left":
+		m.OpenMenu((m.current() + n - 1) % n)
+	case keyType == "Arrow" && key == "right", keyType == "Special" && key == "tab":
+		m.OpenMenu((m.current() + 1) % n)
+	case keyType == "Arrow" && key == "up":
+		m.move(-1)
+	case keyType == "Arrow" && key == "down":
+		m.move(1)
+	case keyType == "Special" && key == "enter", keyType == "Character" && key == " ":
+		if m.selected >= 0 && m.selectable(m.selected) {
+			m.choose(m.selected)
+		}
+	case keyType == "Character":
+		r, _ := utf8.DecodeRuneInString(key)
+		for i, item := range m.Menus[m.current()].Items {
+			first, _ := utf8.DecodeRuneInString(item.Label)
+			if m.selectable(i) && unicode.ToLower(first) == unicode.ToLower(r) {
+				m.choose(i)
+				break
+			}
+		}
+	}
+	return true
+}
+
+// titles returns the column of each title in the bar, counted from 0, and
+// the column after the last.
+func (m *MenuBar) titles() []int {
+	cols := make([]int, 0, len(m.Menus)+1)
+	col := 0
+	for _, menu := range m.Menus {
+		cols = append(cols, col)
+		col += visibleWidth(menu.Title) + 2
+	}
+	return append(cols, col)
+}
+
+// HandleMouse opens and closes menus with a click on their titles and
+// chooses an item with a click on it, and reports whether the event was
+// used. While a menu is open it uses every click, and a click outside the
+// dropdown closes it.
+func (m *MenuBar) HandleMouse(ev MouseEvent) bool {
+	if ev.Button != MouseLeft || ev.Release || ev.Motion {
+		return m.current() >= 0
+	}
+	if ev.Row == m.bar.Row && ev.Col >= m.bar.Col {
+		cols := m.titles()
+		col := ev.Col - m.bar.Col
+		for i := range m.Menus {
+			if col >= cols[i] && col < cols[i+1] {
+				if i == m.current() {
+					m.Close()
+				} else {
+					m.OpenMenu(i)
+				}
+				return true
+			}
+		}
+	}
+	if m.current() < 0 {
+		return false
+	}
+	r := m.dropdown
+	if ev.Row > r.Row && ev.Row < r.Row+r.Height-1 && ev.Col >= r.Col && ev.Col < r.Col+r.Width {
+		if i := ev.Row - r.Row - 1; i < len(m.Menus[m.current()].Items) && m.selectable(i) {
+			m.choose(i)
+		}
+		return true
+	}
+	m.Close()
+	return true
+}
+
+// Line returns the bar in width columns, with the first letter of each
+// title underlined and the open title in ActiveStyle.
+func (m *MenuBar) Line(width int) string {
+	var sb strings.Builder
+	for i, menu := range m.Menus {
+		style := m.Style
+		if i == m.current() {
+			style = m.ActiveStyle
+		}
+		_, size := utf8.DecodeRuneInString(menu.Title)
+		sb.WriteString(style + " " + Underline + menu.Title[:size] + End + style + menu.Title[size:] + " " + End)
+	}
+	line := truncateWidth(sb.String(), width)
+	return line + m.Style + strings.Repeat(" ", max(width-visibleWidth(line), 0)) + End
+}
+
+// Lines returns the bar on a line of width columns, for drawing it in a
+// Pane, GridCell or Tab. The dropdown is only drawn by Render.
+func (m *MenuBar) Lines(width, height int) []string {
+	if height <= 0 {
+		return nil
+	}
+	return []string{m.Line(width)}
+}
+
+// dropdownLines returns the rows of the open dropdown, inner columns wide.
+func (m *MenuBar) dropdownLines() ([]string, int) {
+	items := m.Menus[m.current()].Items
+	labels, shortcuts := 0, 0
+	for _, item := range items {
+		labels = max(labels, visibleWidth(item.Label))
+		shortcuts = max(shortcuts, visibleWidth(item.Shortcut))
+	}
+	inner := labels
+	if shortcuts > 0 {
+		inner += 3 + shortcuts
+	}
+	lines := make([]string, len(items))
+	for i, item := range items {
+		switch {
+		case item.separator:
+			lines[i] = Faint + strings.Repeat(m.Border.Horizontal, inner) + End
+			continue
+		case i == m.selected:
+			lines[i] = m.ActiveStyle
+		case item.Disabled:
+			lines[i] = Faint
+		}
+		text := padWidth(item.Label, labels)
+		if shortcuts > 0 {
+			text += "   " + strings.Repeat(" ", shortcuts-visibleWidth(item.Shortcut)) + item.Shortcut
+		}
+		lines[i] += text + End
+	}
+	return lines, inner
+}
+
+// Render returns the escape sequences drawing the bar in the top row of
+// screen and, if a menu is open, its dropdown below its title.
+func (m *MenuBar) Render(screen Rect) string {
+	m.bar = Rect{screen.Row, screen.Col, screen.Width, 1}
+	s := fmt.Sprintf("\033[%d;%dH%s", screen.Row, screen.Col, m.Line(screen.Width))
+	if m.current() < 0 {
+		return s
+	}
+	lines, inner := m.dropdownLines()
+	width := min(inner+4, screen.Width)
+	height := min(len(lines)+2, screen.Height-1)
+	col := screen.Col + min(m.titles()[m.current()], max(screen.Width-width, 0))
+	m.dropdown = Rect{screen.Row + 1, col, width, height}
+	return s + renderBox(m.dropdown, m.Border, Faint, "", lines)
+}
@@ -0,0 +1,24 @@
+package ansi
+
+import "testing"
+
+func TestMenuBarZero(t *testing.T) {
+	var m MenuBar
+	if m.Open() || m.HandleKey("Arrow", "down") || m.HandleMouse(MouseEvent{Button: MouseLeft, Row: 1, Col: 1}) {
+		t.Error("empty MenuBar literal has a menu open")
+	}
+	_ = m.Render(Rect{1, 1, 80, 24})
+
+	chosen := ""
+	m.Menus = []*DropdownMenu{{Title: "File", Items: []MenuItem{{Label: "Quit", Action: func() { chosen = "Quit" }}}}}
+	if m.Line(20) == "" || m.Open() {
+		t.Error("MenuBar literal with menus starts with a menu open")
+	}
+	if !m.HandleKey("Special", "alt-f") || !m.Open() {
+		t.Fatal("alt-f did not open File")
+	}
+	m.HandleKey("Special", "enter")
+	if chosen != "Quit" || m.Open() {
+		t.Errorf("enter chose %q, open = %v; want Quit, closed", chosen, m.Open())
+	}
+}
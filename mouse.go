@@ -0,0 +1,68 @@
+package ansi
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// --------------------
+// Mouse
+// --------------------
+
+// Mouse buttons reported in a MouseEvent.
+const (
+	MouseLeft      = 0
+	MouseMiddle    = 1
+	MouseRight     = 2
+	MouseNone      = 3 // Movement with no button held.
+	MouseWheelUp   = 64
+	MouseWheelDown = 65
+)
+
+// MouseEvent is a mouse action reported by the terminal while mouse
+// reporting is on.
+type MouseEvent struct {
+	Button   int  // MouseLeft, MouseMiddle, MouseRight, MouseNone or a wheel.
+	Row, Col int  // Screen position, counted from 1.
+	Release  bool // Whether the button was released rather than pressed.
+	Motion   bool // Whether the mouse moved, with Button held.
+}
+
+// EnableMouse turns on mouse reporting on w: clicks, drags and the wheel
+// are then read as keys of type "Mouse", decoded by ParseMouse.
+func EnableMouse(w io.Writer) {
+	fmt.Fprint(w, "\033[?1002h\033[?1006h")
+}
+
+// DisableMouse turns off mouse reporting on w.
+func DisableMouse(w io.Writer) {
+	fmt.Fprint(w, "\033[?1006l\033[?1002l")
+}
+
+// ParseMouse decodes the value of a key of type "Mouse".
+func ParseMouse(key string) (MouseEvent, bool) {
+	if len(key) < 2 {
+		return MouseEvent{}, false
+	}
+	params := strings.Split(key[:len(key)-1], ";")
+	if len(params) != 3 {
+		return MouseEvent{}, false
+	}
+	var n [3]int
+	for i, p := range params {
+		v, err := strconv.Atoi(p)
+		if err != nil {
+			return MouseEvent{}, false
+		}
+		n[i] = v
+	}
+	return MouseEvent{
+		Button:  n[0] &^ (32 | 4 | 8 | 16),
+		Col:     n[1],
+		Row:     n[2],
+		Release: key[len(key)-1] == 'm',
+		Motion:  n[0]&32 != 0,
+	}, true
+}
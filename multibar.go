@@ -0,0 +1,623 @@
+package ansi
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// --------------------
+// MultiProgressBar
+// --------------------
+
+// MultiProgressBar manages several progress bars concurrently.
+type MultiProgressBar struct {
+	Bars map[string]*ProgressBar
+	Lock sync.Mutex
+	out  io.Writer
+
+	interval time.Duration // Minimum time between redraws; 0 draws on every update.
+	dirty    bool          // Whether the bars changed since the last draw.
+	lines    []string      // The lines of the last draw.
+	cols     int           // Terminal width at the last draw.
+	partial  []byte        // Text written without a trailing newline yet.
+	policy   FinishPolicy  // Default finish policy of new bars.
+	plain    *bool         // Whether to print plain lines; nil until resolved.
+	overall  *ProgressBar  // Bar summing all the others, drawn below them.
+	paused   bool          // Whether drawing is paused with Pause.
+	logOut   io.Writer     // Secondary writer for plain state changes of all bars.
+	stop     chan struct{} // Closed to stop the background renderer; nil when it is not running.
+	running  atomic.Bool   // Whether stop is set, readable without the lock.
+
+	// Template is the layout of bars added from now on, with the placeholders
+	// of BarTemplate. It defaults to DefaultMultiTemplate.
+	Template string
+}
+
+// MultiBarOption configures a MultiProgressBar.
+type MultiBarOption func(*MultiProgressBar)
+
+// MultiBarWriter sets where the bars are drawn, e.g. os.Stderr when stdout is
+// reserved for data. It defaults to os.Stdout.
+func MultiBarWriter(w io.Writer) MultiBarOption {
+	return func(mpb *MultiProgressBar) {
+		mpb.out = w
+	}
+}
+
+// MultiBarRefresh sets how many times per second the bars are redrawn at
+// most. Updates in between are drawn together by a background renderer. With
+// fps of 0 or less every update is drawn right away. The default is 15.
+func MultiBarRefresh(fps int) MultiBarOption {
+	return func(mpb *MultiProgressBar) {
+		mpb.interval = 0
+		if fps > 0 {
+			mpb.interval = time.Second / time.Duration(fps)
+		}
+	}
+}
+
+// MultiBarOnFinish sets what becomes of bars once they are finished, unless
+// set per bar with BarOnFinish. The default is FinishKeep.
+func MultiBarOnFinish(policy FinishPolicy) MultiBarOption {
+	return func(mpb *MultiProgressBar) {
+		mpb.policy = policy
+	}
+}
+
+// MultiBarPlain forces plain-text output on or off. Plain output prints a
+// line such as "name 50% (500/1000)" for a bar every 10% or 10 seconds,
+// without escape sequences. By default it is used when the output is not a
+// terminal, as in CI logs and pipes.
+func MultiBarPlain(on bool) MultiBarOption {
+	return func(mpb *MultiProgressBar) {
+		mpb.plain = &on
+	}
+}
+
+// MultiBarOverall adds a bar named name below the others whose progress and
+// total are the sums of theirs, e.g. the overall progress of a download
+// manager. It finishes once they all have. opts style the bar as in AddBar.
+func MultiBarOverall(name string, opts ...BarOption) MultiBarOption {
+	return func(mpb *MultiProgressBar) {
+		bar := newBar(0)
+		bar.name = name
+		bar.width = 50
+		bar.template = DefaultMultiTemplate
+		for _, opt := range opts {
+			opt(bar)
+		}
+		bar.out = nil
+		mpb.overall = bar
+	}
+}
+
+// MultiBarLog writes the state changes of every bar to w as timestamped
+// plain lines, as BarLog does for a single bar.
+func MultiBarLog(w io.Writer) MultiBarOption {
+	return func(mpb *MultiProgressBar) {
+		mpb.logOut = w
+	}
+}
+
+// NewMultiProgressBar creates and returns a new MultiProgressBar.
+func NewMultiProgressBar(opts ...MultiBarOption) *MultiProgressBar {
+	mpb := &MultiProgressBar{
+		Bars:     make(map[string]*ProgressBar),
+		Template: DefaultMultiTemplate,
+		out:      os.Stdout,
+		interval: time.Second / 15,
+	}
+	for _, opt := range opts {
+		opt(mpb)
+	}
+	mpb.isPlain()
+	return mpb
+}
+
+// isPlain reports whether plain lines are printed instead of drawing bars.
+func (mpb *MultiProgressBar) isPlain() bool {
+	if mpb.out == nil {
+		// Created without NewMultiProgressBar.
+		mpb.out = os.Stdout
+	}
+	if mpb.plain == nil {
+		plain := !isTerminal(mpb.out)
+		mpb.plain = &plain
+	}
+	return *mpb.plain
+}
+
+// AddBar adds a new progress bar with the given name and total. Options such
+// as BarColor, BarWidth, BarRunes or BarTemplate style the bar; BarWriter is
+// ignored as the bar is drawn by mpb.
+func (mpb *MultiProgressBar) AddBar(name string, total int, opts ...BarOption) {
+	mpb.Lock.Lock()
+	defer mpb.Lock.Unlock()
+	bar := newBar(total)
+	bar.name = name
+	bar.width = 50
+	bar.Line = len(mpb.Bars)
+	bar.policy = mpb.policy
+	bar.logOut = mpb.logOut
+	bar.template = DefaultMultiTemplate
+	if mpb.Template != "" {
+		bar.template = mpb.Template
+	}
+	for _, opt := range opts {
+		opt(bar)
+	}
+	bar.out = nil
+	bar.owner = mpb
+	bar.logEvent("started")
+	bar.watch(func() {
+		mpb.Lock.Lock()
+		defer mpb.Lock.Unlock()
+		if !bar.finished {
+			bar.abort()
+			mpb.changed()
+		}
+	})
+	if _, ok := mpb.Bars[name]; ok {
+		// Replace the bar of the same name.
+		delete(mpb.Bars, name)
+		mpb.recalculateLines()
+		bar.Line = len(mpb.Bars)
+	}
+	if parent, ok := mpb.Bars[bar.parent]; ok && bar.parent != name {
+		// Place the bar below the parent's last descendant.
+		bar.depth = parent.depth + 1
+		bar.Line = parent.Line + 1
+		for _, other := range mpb.Bars {
+			if mpb.descendant(other, bar.parent) {
+				bar.Line = max(bar.Line, other.Line+1)
+			}
+		}
+		mpb.insertLine(bar.Line)
+	} else {
+		bar.parent = ""
+		if bar.position >= 0 && bar.position < len(mpb.Bars) {
+			bar.Line = bar.position
+			// Keep nested bars together with their parent.
+			for mpb.lineDepth(bar.Line) > 0 {
+				bar.Line++
+			}
+			mpb.insertLine(bar.Line)
+		}
+	}
+	mpb.Bars[name] = bar
+}
+
+// Bar returns the named bar, or nil if there is none. Its methods, such as
+// Incr, Add or ProxyReader, update it safely while mpb draws it.
+func (mpb *MultiProgressBar) Bar(name string) *ProgressBar {
+	mpb.Lock.Lock()
+	defer mpb.Lock.Unlock()
+	return mpb.Bars[name]
+}
+
+// UpdateBar updates the progress of a named bar.
+func (mpb *MultiProgressBar) UpdateBar(name string, progress int) {
+	mpb.Lock.Lock()
+	defer mpb.Lock.Unlock()
+	if bar, ok := mpb.Bars[name]; ok && !bar.finished {
+		bar.pending.Store(0)
+		bar.set(progress)
+		mpb.changed()
+	}
+}
+
+// draw renders all the progress bars. Only lines that differ from the last
+// draw are rewritten; the cursor is left on the line below the bars.
+func (mpb *MultiProgressBar) draw() {
+	if mpb.paused {
+		mpb.dirty = true
+		return
+	}
+	plain := mpb.isPlain()
+	// Sort the bars by their line number.
+	type barEntry struct {
+		Name string
+		Bar  *ProgressBar
+	}
+	var entries []barEntry
+	for name, bar := range mpb.Bars {
+		entries = append(entries, barEntry{Name: name, Bar: bar})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Bar.Line < entries[j].Bar.Line
+	})
+	for _, entry := range entries {
+		entry.Bar.flush()
+	}
+	mpb.aggregate()
+	if mpb.overall != nil && len(entries) > 0 {
+		mpb.sumOverall()
+		entries = append(entries, barEntry{Bar: mpb.overall})
+	}
+	mpb.dirty = false
+	if plain {
+		var sb strings.Builder
+		for _, entry := range entries {
+			if entry.Bar.plainDue() {
+				sb.WriteString(strings.Repeat("  ", entry.Bar.depth) + entry.Bar.plainLine() + "\n")
+			}
+		}
+		fmt.Fprint(mpb.out, sb.String())
+		return
+	}
+	cols, _ := termSize(mpb.out)
+	lines := make([]string, len(entries))
+	for i, entry := range entries {
+		indent := strings.Repeat("  ", entry.Bar.depth)
+		lines[i] = indent + entry.Bar.renderFit(cols-len(indent))
+	}
+
+	var sb strings.Builder
+	if len(mpb.lines) > 0 && cols != mpb.cols {
+		// The terminal was resized and may have rewrapped the old lines, so
+		// clear every row they could now take up and draw from scratch.
+		rows := 0
+		for _, line := range mpb.lines {
+			rows += max((visibleWidth(line)+cols-1)/cols, 1)
+		}
+		sb.WriteString(fmt.Sprintf("\033[%dF\033[J", rows))
+		mpb.lines = nil
+	}
+	mpb.cols = cols
+	if len(mpb.lines) > 0 {
+		sb.WriteString(fmt.Sprintf("\033[%dF", len(mpb.lines))) // Up to the first bar.
+	}
+	skip := 0 // Unchanged lines to move over before the next write.
+	for i, line := range lines {
+		if i < len(mpb.lines) && mpb.lines[i] == line {
+			skip++
+			continue
+		}
+		if skip > 0 {
+			sb.WriteString(fmt.Sprintf("\033[%dE", skip))
+			skip = 0
+		}
+		sb.WriteString("\033[2K" + line + "\n")
+	}
+	if len(lines) < len(mpb.lines) {
+		// Bars were removed, so clear the lines they used.
+		if skip > 0 {
+			sb.WriteString(fmt.Sprintf("\033[%dE", skip))
+		}
+		sb.WriteString("\033[J")
+	} else if skip > 0 {
+		sb.WriteString(fmt.Sprintf("\033[%dE", skip))
+	}
+	// Reset any attributes.
+	sb.WriteString("\033[0m")
+	fmt.Fprint(mpb.out, sb.String())
+	mpb.lines = lines
+}
+
+// changed schedules a redraw, starting the background renderer if needed.
+func (mpb *MultiProgressBar) changed() {
+	if mpb.interval <= 0 {
+		mpb.draw()
+		return
+	}
+	mpb.dirty = true
+	if mpb.stop == nil {
+		mpb.stop = make(chan struct{})
+		mpb.running.Store(true)
+		go mpb.render(mpb.stop)
+	}
+}
+
+// render redraws the bars on every tick while they change, and when the
+// terminal is resized. It stops when stop is closed or every bar is finished.
+func (mpb *MultiProgressBar) render(stop chan struct{}) {
+	ticker := time.NewTicker(mpb.interval)
+	defer ticker.Stop()
+	resized := make(chan os.Signal, 1)
+	notifyResize(resized)
+	defer stopResize(resized)
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		case <-resized:
+			mpb.Lock.Lock()
+			mpb.dirty = true
+			mpb.Lock.Unlock()
+		}
+		mpb.Lock.Lock()
+		if mpb.stop != stop {
+			// Stopped while waiting for the lock.
+			mpb.Lock.Unlock()
+			return
+		}
+		active := false
+		for _, bar := range mpb.Bars {
+			if bar.flush() {
+				mpb.dirty = true
+			}
+			if !bar.finished {
+				active = true
+				// Indeterminate bars are animated even without updates.
+				mpb.dirty = mpb.dirty || bar.indeterminate()
+			}
+		}
+		if mpb.dirty {
+			mpb.draw()
+		}
+		if !active {
+			mpb.stop = nil
+			mpb.running.Store(false)
+			mpb.Lock.Unlock()
+			return
+		}
+		mpb.Lock.Unlock()
+	}
+}
+
+// Pause stops drawing the bars, leaving the cursor below them, so that the
+// program can run a command or prompt the user. Updates still count and are
+// shown by Resume.
+func (mpb *MultiProgressBar) Pause() {
+	mpb.Lock.Lock()
+	defer mpb.Lock.Unlock()
+	if mpb.paused {
+		return
+	}
+	if mpb.dirty {
+		mpb.draw()
+	}
+	mpb.paused = true
+}
+
+// Resume draws the bars again after Pause, below anything printed meanwhile.
+func (mpb *MultiProgressBar) Resume() {
+	mpb.Lock.Lock()
+	defer mpb.Lock.Unlock()
+	if !mpb.paused {
+		return
+	}
+	mpb.paused = false
+	mpb.lines = nil
+	mpb.draw()
+}
+
+// wake makes sure increments made with ProgressBar.Incr get drawn, taking
+// the lock only when the background renderer is not running.
+func (mpb *MultiProgressBar) wake() {
+	if mpb.running.Load() {
+		return
+	}
+	mpb.Lock.Lock()
+	defer mpb.Lock.Unlock()
+	mpb.changed()
+}
+
+// Stop stops the background renderer and draws any pending updates. It is
+// only needed when bars are left unfinished; once every bar is finished the
+// renderer stops on its own.
+func (mpb *MultiProgressBar) Stop() {
+	mpb.Lock.Lock()
+	defer mpb.Lock.Unlock()
+	if mpb.stop != nil {
+		close(mpb.stop)
+		mpb.stop = nil
+		mpb.running.Store(false)
+	}
+	if mpb.dirty {
+		mpb.draw()
+	}
+}
+
+// SetTotal changes the total of a named bar, e.g. as more work is discovered.
+func (mpb *MultiProgressBar) SetTotal(name string, total int) {
+	mpb.Lock.Lock()
+	defer mpb.Lock.Unlock()
+	if bar, ok := mpb.Bars[name]; ok && !bar.finished {
+		bar.setTotal(total)
+		mpb.changed()
+	}
+}
+
+// StartPhase moves a named bar created with BarPhases on to the named phase,
+// with a fresh progress counting up to total.
+func (mpb *MultiProgressBar) StartPhase(name, phase string, total int) {
+	mpb.Lock.Lock()
+	defer mpb.Lock.Unlock()
+	if bar, ok := mpb.Bars[name]; ok && !bar.finished {
+		bar.startPhase(phase, total)
+		mpb.changed()
+	}
+}
+
+// Println prints a line above the bars, like fmt.Println, and draws the bars
+// again below it.
+func (mpb *MultiProgressBar) Println(a ...any) {
+	mpb.Write([]byte(fmt.Sprintln(a...)))
+}
+
+// Printf prints above the bars, like fmt.Printf. A line without a trailing
+// newline is held back until it is completed.
+func (mpb *MultiProgressBar) Printf(format string, a ...any) {
+	mpb.Write([]byte(fmt.Sprintf(format, a...)))
+}
+
+// Write prints p above the bars and draws the bars again below it, so a
+// MultiProgressBar can be used as the output of a logger. Text is printed
+// line by line; an unfinished line is held back until its newline arrives.
+func (mpb *MultiProgressBar) Write(p []byte) (int, error) {
+	mpb.Lock.Lock()
+	defer mpb.Lock.Unlock()
+	mpb.partial = append(mpb.partial, p...)
+	end := bytes.LastIndexByte(mpb.partial, '\n')
+	if end < 0 {
+		return len(p), nil
+	}
+	text := string(mpb.partial[:end+1])
+	mpb.partial = append(mpb.partial[:0], mpb.partial[end+1:]...)
+
+	if mpb.isPlain() || mpb.paused {
+		fmt.Fprint(mpb.out, text)
+		return len(p), nil
+	}
+	if len(mpb.lines) > 0 {
+		// Replace the bars with the text, then draw them again below it.
+		fmt.Fprintf(mpb.out, "\033[%dF\033[J", len(mpb.lines))
+		mpb.lines = nil
+	}
+	fmt.Fprint(mpb.out, text)
+	mpb.draw()
+	return len(p), nil
+}
+
+// FinishBar sets a progress bar to complete. Depending on the bar's
+// FinishPolicy it then stays, collapses into a summary or is removed.
+func (mpb *MultiProgressBar) FinishBar(name string) {
+	mpb.Lock.Lock()
+	defer mpb.Lock.Unlock()
+	if bar, ok := mpb.Bars[name]; ok && !bar.finished {
+		mpb.finish(bar)
+	}
+}
+
+// finish completes bar, one of the bars of mpb, and applies its
+// FinishPolicy.
+func (mpb *MultiProgressBar) finish(bar *ProgressBar) {
+	bar.complete()
+	if bar.policy == FinishRemove && mpb.Bars[bar.name] == bar {
+		delete(mpb.Bars, bar.name)
+		mpb.recalculateLines()
+	}
+	mpb.draw()
+}
+
+// RemoveBar removes a progress bar along with any bars nested under it.
+func (mpb *MultiProgressBar) RemoveBar(name string) {
+	mpb.Lock.Lock()
+	defer mpb.Lock.Unlock()
+	for other, bar := range mpb.Bars {
+		if mpb.descendant(bar, name) {
+			delete(mpb.Bars, other)
+		}
+	}
+	delete(mpb.Bars, name)
+	mpb.recalculateLines()
+	mpb.draw()
+}
+
+// descendant reports whether bar is nested, at any depth, under the bar
+// named parent.
+func (mpb *MultiProgressBar) descendant(bar *ProgressBar, parent string) bool {
+	for bar.parent != "" {
+		if bar.parent == parent {
+			return true
+		}
+		if bar = mpb.Bars[bar.parent]; bar == nil {
+			return false
+		}
+	}
+	return false
+}
+
+// aggregate sets the progress and total of every parent bar to the sums of
+// its children's, and finishes it once they have all finished.
+func (mpb *MultiProgressBar) aggregate() {
+	bars := make([]*ProgressBar, 0, len(mpb.Bars))
+	for _, bar := range mpb.Bars {
+		bars = append(bars, bar)
+	}
+	// Children before their parents, so sums roll up level by level.
+	sort.Slice(bars, func(i, j int) bool {
+		return bars[i].depth > bars[j].depth
+	})
+	type sum struct {
+		progress, total int
+		done, aborted   bool
+	}
+	sums := make(map[*ProgressBar]*sum)
+	for _, bar := range bars {
+		if s, ok := sums[bar]; ok && !bar.finished {
+			bar.Progress, bar.Total = s.progress, s.total
+			if s.done && s.aborted {
+				bar.abort()
+			} else if s.done {
+				bar.complete()
+			}
+		}
+		parent, ok := mpb.Bars[bar.parent]
+		if !ok {
+			continue
+		}
+		s, ok := sums[parent]
+		if !ok {
+			s = &sum{done: true}
+			sums[parent] = s
+		}
+		s.progress += bar.Progress
+		s.total += bar.Total
+		s.done = s.done && bar.finished
+		s.aborted = s.aborted || bar.aborted
+	}
+}
+
+// sumOverall sets the overall bar to the sums of the top-level bars, which
+// already include the bars nested under them.
+func (mpb *MultiProgressBar) sumOverall() {
+	bar := mpb.overall
+	if bar.finished {
+		return
+	}
+	progress, total, done := 0, 0, true
+	for _, other := range mpb.Bars {
+		if other.depth == 0 {
+			progress += other.Progress
+			total += other.Total
+			done = done && other.finished
+		}
+	}
+	bar.Progress, bar.Total = progress, total
+	if done {
+		bar.complete()
+	}
+}
+
+// recalculateLines renumbers the bars from 0, keeping their order.
+func (mpb *MultiProgressBar) recalculateLines() {
+	bars := make([]*ProgressBar, 0, len(mpb.Bars))
+	for _, bar := range mpb.Bars {
+		bars = append(bars, bar)
+	}
+	sort.Slice(bars, func(i, j int) bool {
+		return bars[i].Line < bars[j].Line
+	})
+	for lineNum, bar := range bars {
+		bar.Line = lineNum
+	}
+}
+
+// insertLine moves the bars from line down by one to make room for a bar.
+func (mpb *MultiProgressBar) insertLine(line int) {
+	for _, bar := range mpb.Bars {
+		if bar.Line >= line {
+			bar.Line++
+		}
+	}
+}
+
+// lineDepth returns the depth of the bar on line, or 0 if there is none.
+func (mpb *MultiProgressBar) lineDepth(line int) int {
+	for _, bar := range mpb.Bars {
+		if bar.Line == line {
+			return bar.depth
+		}
+	}
+	return 0
+}
@@ -0,0 +1,67 @@
+package ansi
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Run with -race: the bars are updated through their handles while the
+// background renderer draws them.
+func TestMultiProgressBarHandle(t *testing.T) {
+	var out bytes.Buffer
+	mpb := NewMultiProgressBar(MultiBarWriter(&out), MultiBarRefresh(100))
+	mpb.AddBar("read", 4096)
+	mpb.AddBar("temp", 10, BarOnFinish(FinishRemove))
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		r := mpb.Bar("read").ProxyReader(strings.NewReader(strings.Repeat("x", 4096)))
+		if _, err := io.CopyBuffer(io.Discard, r, make([]byte, 64)); err != nil {
+			t.Error(err)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		bar := mpb.Bar("temp")
+		for range 10 {
+			bar.Add(1)
+		}
+		bar.SetTotal(20)
+		bar.Set(20)
+		bar.Finish()
+	}()
+	wg.Wait()
+
+	if got := mpb.Bar("read").Percent(); got != 100 {
+		t.Errorf("read: Percent() = %v, want 100", got)
+	}
+	if mpb.Bar("temp") != nil {
+		t.Error("temp: finished bar with FinishRemove is still there")
+	}
+	mpb.Bar("read").Finish()
+	mpb.Stop()
+}
+
+func TestMultiProgressBarFinished(t *testing.T) {
+	mpb := NewMultiProgressBar(MultiBarWriter(io.Discard), MultiBarRefresh(0))
+	mpb.AddBar("group", 0)
+	mpb.AddBar("file", 10, BarParent("group"))
+	mpb.UpdateBar("file", 4)
+	mpb.FinishBar("file")
+
+	mpb.UpdateBar("file", 2)
+	mpb.SetTotal("file", 20)
+	if bar := mpb.Bar("file"); bar.Progress != 10 || bar.Total != 10 {
+		t.Errorf("file: finished bar changed to %d/%d, want 10/10", bar.Progress, bar.Total)
+	}
+	mpb.UpdateBar("group", 2)
+	if bar := mpb.Bar("group"); !bar.finished || bar.Progress != 10 {
+		t.Errorf("group: finished = %v, progress = %d, want true, 10", bar.finished, bar.Progress)
+	}
+	mpb.Stop()
+}
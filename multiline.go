@@ -0,0 +1,169 @@
+package ansi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// --------------------
+// Multi-line Input
+// --------------------
+
+// InputMultiline lets the prompt accept several lines, e.g. for a commit
+// message. Enter starts a new line and the input is finished with Ctrl-D,
+// Alt+Enter or, on terminals that report it, Shift+Enter. The arrow keys move
+// between lines and long lines are wrapped to the terminal width.
+// Autocomplete and history are not available in this mode.
+func InputMultiline() InputOption {
+	return func(c *inputConfig) {
+		c.multiline = true
+	}
+}
+
+// InputTerminator sets a line that finishes multi-line input when entered on
+// its own, like "." for mail. The terminator line is not part of the result.
+func InputTerminator(line string) InputOption {
+	return func(c *inputConfig) {
+		c.multiline = true
+		c.terminator = line
+	}
+}
+
+// lineBounds returns the start and end of the line containing pos.
+func (st *inputState) lineBounds(pos int) (int, int) {
+	start, end := pos, pos
+	for start > 0 && st.text[start-1] != '\n' {
+		start--
+	}
+	for end < len(st.text) && st.text[end] != '\n' {
+		end++
+	}
+	return start, end
+}
+
+// handleMultilineKey handles the keys that behave differently in multi-line
+// mode and reports whether key was one of them.
+func (st *inputState) handleMultilineKey(keyType, key string) bool {
+	start, end := st.lineBounds(st.cursor)
+	switch {
+	case keyType == "Special" && (key == "ctrl-d" || key == "alt-enter" || key == "shift-enter"):
+		st.submit()
+	case keyType == "Special" && key == "enter":
+		if st.cfg.terminator != "" && string(st.text[start:end]) == st.cfg.terminator {
+			removed := st.deleteRange(max(start-1, 0), end)
+			st.submit()
+			if !st.done {
+				st.insert(removed)
+			}
+			return true
+		}
+		st.insert([]rune{'\n'})
+	case keyType == "Special" && (key == "home" || key == "ctrl-a"):
+		st.cursor = start
+	case keyType == "Special" && (key == "end" || key == "ctrl-e"):
+		st.cursor = end
+	case keyType == "Special" && key == "ctrl-k":
+		if st.cursor == end && end < len(st.text) {
+			end++
+		}
+		st.kill(st.cursor, end)
+	case keyType == "Special" && key == "ctrl-u":
+		st.kill(start, st.cursor)
+	case keyType == "Arrow" && key == "up":
+		if start > 0 {
+			prevStart, _ := st.lineBounds(start - 1)
+			st.cursor = min(prevStart+st.cursor-start, start-1)
+		}
+	case keyType == "Arrow" && key == "down":
+		if end < len(st.text) {
+			_, nextEnd := st.lineBounds(end + 1)
+			st.cursor = min(end+1+st.cursor-start, nextEnd)
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// renderMultiline redraws a multi-line prompt. Lines after the first are
+// indented to line up with the prompt, and lines longer than the terminal are
+// wrapped onto extra rows.
+func (st *inputState) renderMultiline() {
+	cfg := st.cfg
+	width, _ := termSize(cfg.out)
+	indent := visibleWidth(cfg.prompt) + 1
+	avail := max(width-indent, 1)
+
+	// Split the text into screen rows, finding the row and column of the cursor.
+	rows := [][]rune{nil}
+	col, curRow, curCol := 0, 0, 0
+	for i := 0; i <= len(st.text); i++ {
+		if i == st.cursor {
+			curRow, curCol = len(rows)-1, col
+		}
+		if i == len(st.text) {
+			break
+		}
+		r := st.text[i]
+		if r == '\n' {
+			rows = append(rows, nil)
+			col = 0
+			continue
+		}
+		if w := runeWidth(r); col+w > avail {
+			rows = append(rows, nil)
+			col = 0
+			if i == st.cursor {
+				curRow, curCol = len(rows)-1, 0
+			}
+		}
+		rows[len(rows)-1] = append(rows[len(rows)-1], r)
+		col += runeWidth(r)
+	}
+	if curCol >= avail {
+		// The cursor sits after a full row, so show it at the start of the next.
+		rows = append(rows, nil)
+		curRow, curCol = len(rows)-1, 0
+	}
+	if st.done {
+		// Leave the cursor below the text when finishing.
+		curRow, curCol = len(rows)-1, runesWidth(rows[len(rows)-1])
+	}
+
+	var sb strings.Builder
+	if st.cursorRow > 0 {
+		sb.WriteString(fmt.Sprintf("\033[%dA", st.cursorRow))
+	}
+	sb.WriteString("\r\033[J")
+	for i, row := range rows {
+		if i == 0 {
+			if cfg.promptStyle != "" {
+				sb.WriteString(cfg.promptStyle + cfg.prompt + End + " ")
+			} else {
+				sb.WriteString(cfg.prompt + " ")
+			}
+		} else {
+			sb.WriteString("\r\n" + strings.Repeat(" ", indent))
+		}
+		if cfg.colorizer != nil {
+			// Wrapped lines are colored one row at a time.
+			sb.WriteString(cfg.colorizer(string(row)))
+		} else {
+			sb.WriteString(string(row))
+		}
+	}
+	if len(st.text) == 0 && cfg.placeholder != "" && !st.done {
+		sb.WriteString(Faint + cfg.placeholder + End)
+	}
+	lastRow := len(rows) - 1
+	if st.errMsg != "" {
+		sb.WriteString("\r\n" + cfg.errorStyle + st.errMsg + End)
+		lastRow++
+	}
+	if up := lastRow - curRow; up > 0 {
+		sb.WriteString(fmt.Sprintf("\033[%dA", up))
+	}
+	sb.WriteString(cursorColumn(indent + curCol))
+	st.cursorRow = curRow
+	fmt.Fprint(cfg.out, sb.String())
+}
@@ -0,0 +1,165 @@
+package ansi
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// --------------------
+// Multi-select
+// --------------------
+
+// InputCheckboxes sets the checkboxes MultiSelect draws before chosen and
+// other options. They may contain colors; the default is a green "◉" and a
+// faint "◯".
+func InputCheckboxes(checked, unchecked string) InputOption {
+	return func(c *inputConfig) {
+		c.checked = checked
+		c.unchecked = unchecked
+	}
+}
+
+// SelectMin sets the fewest options MultiSelect accepts.
+func SelectMin(n int) InputOption {
+	return func(c *inputConfig) {
+		c.selectMin = n
+	}
+}
+
+// SelectMax sets the most options MultiSelect accepts. 0, the default, means
+// all of them.
+func SelectMax(n int) InputOption {
+	return func(c *inputConfig) {
+		c.selectMax = n
+	}
+}
+
+// MultiSelect shows options as a checklist below prompt and returns the
+// indexes of the options chosen, in order. Up and down, or k and j, move the
+// highlight, Space checks or unchecks an option, a checks all options or, if
+// all are checked, none, and Enter confirms. SelectMin and SelectMax set how
+// many options must be chosen; Enter with too few shows an error instead of
+// confirming. Long lists scroll like those of Select. Escape and Ctrl-C
+// cancel with ErrInterrupted. InputPromptStyle, InputErrorStyle,
+// InputWriter and InputKeyReader may be given in opts.
+func MultiSelect(prompt string, options []string, opts ...InputOption) ([]int, error) {
+	cfg := &inputConfig{out: os.Stdout, errorStyle: Red, checked: Green + "◉" + End, unchecked: Faint + "◯" + End}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.keys == nil {
+		cfg.keys = defaultKeyReader()
+	}
+	if cfg.promptStyle != "" {
+		prompt = cfg.promptStyle + prompt + End
+	}
+	least, most := cfg.selectMin, len(options)
+	if cfg.selectMax > 0 {
+		most = min(cfg.selectMax, most)
+	}
+
+	chosen := make([]bool, len(options))
+	count, index, top := 0, 0, 0
+	page := cfg.listPage()
+	errMsg := ""
+	fmt.Fprint(cfg.out, "\033[?25l")
+	defer fmt.Fprint(cfg.out, "\033[?25h")
+	for {
+		top = scrollList(index, top, page)
+		var sb strings.Builder
+		sb.WriteString("\r\033[J" + prompt)
+		end := min(top+page, len(options))
+		var lines []string
+		for i := top; i < end; i++ {
+			option := options[i]
+			box := cfg.unchecked
+			if chosen[i] {
+				box = cfg.checked
+			}
+			if i == index {
+				lines = append(lines, Cyan+">"+End+" "+box+" "+Cyan+option+End)
+			} else {
+				lines = append(lines, "  "+box+" "+option)
+			}
+		}
+		for _, line := range cfg.listScrollbar(lines, len(options), end-top, top) {
+			sb.WriteString("\r\n" + line)
+		}
+		up := end - top
+		if len(options) > page {
+			sb.WriteString("\r\n" + listPosition(index, len(options)))
+			up++
+		}
+		if errMsg != "" {
+			sb.WriteString("\r\n" + cfg.errorStyle + errMsg + End)
+			up++
+		}
+		if up > 0 {
+			sb.WriteString(fmt.Sprintf("\033[%dA", up))
+		}
+		fmt.Fprint(cfg.out, sb.String())
+
+		keyType, key, err := cfg.keys.readKey()
+		if err != nil {
+			fmt.Fprint(cfg.out, "\r\033[J"+prompt+"\n")
+			return nil, err
+		}
+		errMsg = ""
+		switch {
+		case (keyType == "Arrow" && key == "up" || keyType == "Character" && key == "k") && index > 0:
+			index--
+		case (keyType == "Arrow" && key == "down" || keyType == "Character" && key == "j") && index < len(options)-1:
+			index++
+		case keyType == "Special" && key == "pageup":
+			index = max(index-page, 0)
+		case keyType == "Special" && key == "pagedown":
+			index = max(min(index+page, len(options)-1), 0)
+		case keyType == "Character" && key == " " && len(options) > 0:
+			switch {
+			case chosen[index]:
+				chosen[index] = false
+				count--
+			case count < most:
+				chosen[index] = true
+				count++
+			default:
+				errMsg = fmt.Sprintf("Choose at most %d.", most)
+			}
+		case keyType == "Character" && key == "a":
+			all := count < len(options)
+			if all && len(options) > most {
+				errMsg = fmt.Sprintf("Choose at most %d.", most)
+				break
+			}
+			for i := range chosen {
+				chosen[i] = all
+			}
+			count = 0
+			if all {
+				count = len(options)
+			}
+		case keyType == "Special" && key == "enter":
+			if count < least {
+				errMsg = fmt.Sprintf("Choose at least %d.", least)
+				break
+			}
+			var indexes []int
+			var names []string
+			for i, ok := range chosen {
+				if ok {
+					indexes = append(indexes, i)
+					names = append(names, options[i])
+				}
+			}
+			fmt.Fprintf(cfg.out, "\r\033[J%s %s\n", prompt, Cyan+strings.Join(names, ", ")+End)
+			return indexes, nil
+		case keyType == "Special" && (key == "escape" || key == "ctrl-c"):
+			fmt.Fprint(cfg.out, "\r\033[J"+prompt+"\n")
+			if key == "escape" && cfg.escapeBack {
+				return nil, errBack
+			}
+			return nil, ErrInterrupted
+		}
+	}
+}
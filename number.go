@@ -0,0 +1,151 @@
+package ansi
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// --------------------
+// Numeric Input
+// --------------------
+
+// InputMin sets the smallest value accepted by InputInt and InputFloat.
+func InputMin(min float64) InputOption {
+	return func(c *inputConfig) {
+		c.min = &min
+	}
+}
+
+// InputMax sets the largest value accepted by InputInt and InputFloat.
+func InputMax(max float64) InputOption {
+	return func(c *inputConfig) {
+		c.max = &max
+	}
+}
+
+// InputStep sets how much the up and down arrows change the value in
+// InputInt and InputFloat. It defaults to 1.
+func InputStep(step float64) InputOption {
+	return func(c *inputConfig) {
+		c.step = step
+	}
+}
+
+// InputInt reads an integer. The value is checked while typing, the up and
+// down arrows increment and decrement it, and InputMin and InputMax set
+// bounds. Numbers are parsed the same way regardless of locale.
+func InputInt(prompt string, opts ...InputOption) (int, error) {
+	text, err := Input(append(append([]InputOption{InputPrompt(prompt)}, opts...), inputNumber("int"))...)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(text))
+	return n, err
+}
+
+// InputFloat reads a floating point number, like InputInt. A "." is always
+// used as the decimal separator.
+func InputFloat(prompt string, opts ...InputOption) (float64, error) {
+	text, err := Input(append(append([]InputOption{InputPrompt(prompt)}, opts...), inputNumber("float"))...)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(strings.TrimSpace(text), 64)
+}
+
+// inputNumber turns the prompt into a numeric prompt of the given kind, "int"
+// or "float", wrapping any validator set by the caller.
+func inputNumber(kind string) InputOption {
+	return func(c *inputConfig) {
+		c.number = kind
+		c.liveCheck = true
+		validate := c.validate
+		c.validate = func(s string) error {
+			if err := c.checkNumber(s); err != nil {
+				return err
+			}
+			if validate != nil {
+				return validate(s)
+			}
+			return nil
+		}
+	}
+}
+
+// parseNumber parses s as the prompt's kind of number.
+func (cfg *inputConfig) parseNumber(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	if cfg.number == "int" {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%q is not a whole number", s)
+		}
+		return float64(n), nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, fmt.Errorf("%q is not a number", s)
+	}
+	return f, nil
+}
+
+// checkNumber parses s and checks it is within the bounds.
+func (cfg *inputConfig) checkNumber(s string) error {
+	v, err := cfg.parseNumber(s)
+	if err != nil {
+		return err
+	}
+	if cfg.min != nil && v < *cfg.min {
+		return fmt.Errorf("must be at least %s", cfg.formatNumber(*cfg.min))
+	}
+	if cfg.max != nil && v > *cfg.max {
+		return fmt.Errorf("must be at most %s", cfg.formatNumber(*cfg.max))
+	}
+	return nil
+}
+
+// formatNumber formats v as the prompt's kind of number, with as many
+// decimals as the step uses.
+func (cfg *inputConfig) formatNumber(v float64) string {
+	if cfg.number == "int" {
+		return strconv.FormatInt(int64(math.Round(v)), 10)
+	}
+	decimals := 0
+	if step := strconv.FormatFloat(cfg.numberStep(), 'f', -1, 64); strings.Contains(step, ".") {
+		decimals = len(step) - strings.Index(step, ".") - 1
+	}
+	if s := strconv.FormatFloat(v, 'f', -1, 64); strings.Contains(s, ".") {
+		decimals = max(decimals, min(len(s)-strings.Index(s, ".")-1, 6))
+	}
+	return strconv.FormatFloat(v, 'f', decimals, 64)
+}
+
+// numberStep returns the step used by the arrow keys.
+func (cfg *inputConfig) numberStep() float64 {
+	if cfg.step > 0 {
+		return cfg.step
+	}
+	return 1
+}
+
+// stepNumber adds delta steps to the value, keeping it within the bounds.
+func (st *inputState) stepNumber(delta float64) {
+	cfg := st.cfg
+	v := 0.0
+	if strings.TrimSpace(string(st.text)) != "" {
+		var err error
+		if v, err = cfg.parseNumber(string(st.text)); err != nil {
+			return
+		}
+	}
+	v += delta * cfg.numberStep()
+	if cfg.min != nil {
+		v = math.Max(v, *cfg.min)
+	}
+	if cfg.max != nil {
+		v = math.Min(v, *cfg.max)
+	}
+	st.setText([]rune(cfg.formatNumber(v)))
+}
@@ -0,0 +1,144 @@
+package ansi
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// --------------------
+// Pager
+// --------------------
+
+// pager is the state of a running Pager.
+type pager struct {
+	cfg    *inputConfig
+	view   *Viewport
+	query  string // Text searched for.
+	status string // Message shown in the status line until the next key.
+}
+
+// Pager shows the content of r in the alternate screen, like less, keeping
+// its colors. Long lines wrap, and a scrollbar on the right shows where the
+// view is. The arrow keys (or j and k), PageUp and PageDown (or b and Space),
+// g and G move through the content; / searches for text, highlighting every
+// match, and n and N go to the next and previous match. q, Escape and Ctrl-C
+// quit. If the output is not a terminal the content is copied to it instead.
+// InputWriter and InputKeyReader may be given in opts.
+func Pager(r io.Reader, opts ...InputOption) error {
+	cfg := &inputConfig{out: os.Stdout}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.keys == nil {
+		cfg.keys = defaultKeyReader()
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if !isTerminal(cfg.out) {
+		_, err := cfg.out.Write(data)
+		return err
+	}
+	p := &pager{cfg: cfg, view: NewViewport(1, 1, 0, 0)}
+	p.view.Scrollbar = defaultScrollbar()
+	p.view.SetContent(string(data))
+
+	fmt.Fprint(cfg.out, "\033[?1049h\033[?25l")
+	defer fmt.Fprint(cfg.out, "\033[?25h\033[?1049l")
+	for {
+		p.draw()
+		keyType, key, err := cfg.keys.readKey()
+		if err != nil {
+			return err
+		}
+		p.status = ""
+		v := p.view
+		switch {
+		case keyType == "Arrow" && key == "up", keyType == "Character" && key == "k", keyType == "Special" && key == "ctrl-p":
+			v.ScrollUp(1)
+		case keyType == "Arrow" && key == "down", keyType == "Character" && key == "j", keyType == "Special" && (key == "ctrl-n" || key == "enter"):
+			v.ScrollDown(1)
+		case keyType == "Special" && key == "pageup", keyType == "Character" && key == "b":
+			v.PageUp()
+		case keyType == "Special" && key == "pagedown", keyType == "Character" && (key == " " || key == "f"):
+			v.PageDown()
+		case keyType == "Special" && key == "home", keyType == "Character" && key == "g":
+			v.GotoTop()
+		case keyType == "Special" && key == "end", keyType == "Character" && key == "G":
+			v.GotoBottom()
+		case keyType == "Character" && key == "/":
+			query, err := p.readQuery()
+			if err != nil {
+				return err
+			}
+			if query != "" {
+				p.query = query
+				v.Highlight = query
+				p.find(0)
+			}
+		case keyType == "Character" && key == "n" && p.query != "":
+			p.find(1)
+		case keyType == "Character" && key == "N" && p.query != "":
+			p.find(-1)
+		case keyType == "Character" && key == "q", keyType == "Special" && (key == "escape" || key == "ctrl-c"):
+			return nil
+		}
+	}
+}
+
+// find goes to the next match of the query in direction dir, as
+// Viewport.Find does.
+func (p *pager) find(dir int) {
+	if !p.view.Find(p.query, dir) {
+		p.status = "Pattern not found"
+	}
+}
+
+// readQuery reads the text to search for on the status line. Escape gives
+// an empty query.
+func (p *pager) readQuery() (string, error) {
+	return readStatusLine(p.cfg, p.view.Height+1, "/")
+}
+
+// readStatusLine reads a line of text typed on screen row row after prefix,
+// as in the status line of a full-screen widget. Escape, or Backspace on an
+// empty line, gives an empty line.
+func readStatusLine(cfg *inputConfig, row int, prefix string) (string, error) {
+	st := &inputState{cfg: &inputConfig{out: cfg.out}}
+	fmt.Fprint(cfg.out, "\033[?25h")
+	defer fmt.Fprint(cfg.out, "\033[?25l")
+	for {
+		fmt.Fprintf(cfg.out, "\033[%d;1H\033[2K%s%s%s", row, prefix, string(st.text), cursorColumn(visibleWidth(prefix)+runesWidth(st.text[:st.cursor])))
+		keyType, key, err := cfg.keys.readKey()
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case keyType == "Special" && key == "enter":
+			return string(st.text), nil
+		case keyType == "Special" && (key == "escape" || key == "ctrl-c"):
+			return "", nil
+		case keyType == "Special" && key == "backspace" && len(st.text) == 0:
+			return "", nil
+		default:
+			st.handleEditKey(keyType, key)
+		}
+	}
+}
+
+// draw redraws the screen, fitting the view to its size.
+func (p *pager) draw() {
+	v := p.view
+	v.Width, v.Height = termSize(p.cfg.out)
+	v.Height = max(v.Height-1, 1)
+	status := p.status
+	if status == "" {
+		status = fmt.Sprintf("lines %d-%d/%d %.0f%%", v.top+1, min(v.top+v.Height, len(v.wrapped())), len(v.wrapped()), v.Percent())
+		if v.AtBottom() {
+			status += " (END)"
+		}
+	}
+	fmt.Fprint(p.cfg.out, v.Render()+fmt.Sprintf("\033[%d;1H\033[2K", v.Height+1)+Negative+status+End)
+}
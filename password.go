@@ -0,0 +1,86 @@
+package ansi
+
+import "unicode/utf8"
+
+// --------------------
+// Password
+// --------------------
+
+// InputMask hides the typed text, echoing mask once per character, or nothing
+// at all if mask is 0. History and autocomplete are disabled and the editing
+// buffers are zeroed once the prompt finishes. The string returned by Input
+// is a copy that cannot be zeroed; PasswordBytes returns one that can.
+func InputMask(mask rune) InputOption {
+	return func(c *inputConfig) {
+		c.masked = true
+		c.mask = mask
+	}
+}
+
+// InputMaskToggle lets Ctrl-T switch a masked prompt between hidden and
+// visible text.
+func InputMaskToggle() InputOption {
+	return func(c *inputConfig) {
+		c.maskToggle = true
+	}
+}
+
+// Password reads a password, echoing "*" for each typed character. Options
+// such as InputMask(0) or InputValidator may be added with opts.
+func Password(prompt string, opts ...InputOption) (string, error) {
+	return Input(append([]InputOption{InputPrompt(prompt), InputMask('*')}, opts...)...)
+}
+
+// PasswordBytes reads a password like Password, but returns it as UTF-8
+// bytes, which the caller can zero once done with them. A validator set with
+// InputValidator still receives the text as a string.
+func PasswordBytes(prompt string, opts ...InputOption) ([]byte, error) {
+	var password []byte
+	err := input(append([]InputOption{InputPrompt(prompt), InputMask('*')}, opts...), func(text []rune) {
+		n := 0
+		for _, r := range text {
+			n += utf8.RuneLen(r)
+		}
+		password = make([]byte, 0, n)
+		for _, r := range text {
+			password = utf8.AppendRune(password, r)
+		}
+	})
+	return password, err
+}
+
+// Secret reads a secret such as an API token like Password, but Ctrl-T
+// toggles between masked and visible input so it can be checked before
+// submitting.
+func Secret(prompt string, opts ...InputOption) (string, error) {
+	return Password(prompt, append([]InputOption{InputMaskToggle()}, opts...)...)
+}
+
+// hidden reports whether the typed text is currently masked. Revealed text is
+// masked again once the prompt finishes, so it does not stay on screen.
+func (st *inputState) hidden() bool {
+	return st.cfg.masked && (!st.revealed || st.done)
+}
+
+// maskedText returns what is shown in place of the typed text.
+func (st *inputState) maskedText() string {
+	if !st.hidden() {
+		return string(st.text)
+	}
+	if st.cfg.mask == 0 {
+		return ""
+	}
+	masked := make([]rune, len(st.text))
+	for i := range masked {
+		masked[i] = st.cfg.mask
+	}
+	return string(masked)
+}
+
+// wipe zeroes the buffers that held the typed text.
+func (st *inputState) wipe() {
+	clear(st.text[:cap(st.text)])
+	clear(st.killed[:cap(st.killed)])
+	clear(st.vi.undo[:cap(st.vi.undo)])
+	st.text, st.killed, st.vi.undo = nil, nil, nil
+}
@@ -0,0 +1,1080 @@
+package ansi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// --------------------
+// ProgressBar
+// --------------------
+
+// Default layouts of a ProgressBar. See BarTemplate for the placeholders.
+const (
+	DefaultBarTemplate   = "{name} {bar} {percent} {count} {rate} {elapsed} ETA {eta}"
+	DefaultMultiTemplate = "{name}: {bar} {count}"
+
+	// DefaultIndeterminateTemplate replaces DefaultBarTemplate while the total
+	// is unknown.
+	DefaultIndeterminateTemplate = "{name} {bar} {current} {rate} {elapsed}"
+)
+
+// ProgressBar represents an individual progress bar. It is drawn either by a
+// MultiProgressBar or, when created with NewProgressBar, on its own line with
+// the elapsed time, rate, ETA and percentage.
+//
+// A bar with a Total of 0 or less is indeterminate: its size is not known, so
+// it shows a block bouncing back and forth and only counts the progress.
+type ProgressBar struct {
+	Progress int
+	Total    int
+	Line     int
+
+	name     string
+	template string
+	width    int
+	fill     rune
+	empty    rune
+	left     string
+	right    string
+	color    string
+	done     string
+	prefix   BarDecorator
+	suffix   BarDecorator
+	unit     func(float64) string
+	out      io.Writer
+	policy   FinishPolicy
+	logOut   io.Writer // Secondary writer for plain state changes.
+
+	onComplete func(name string)
+	onUpdate   func(name string, progress, total int)
+	updateGap  time.Duration // Minimum time between onUpdate calls.
+	updatedAt  time.Time     // Time of the last onUpdate call.
+	logStep    int           // Last 10% milestone logged.
+	ctx        context.Context
+	unwatch    func() bool // Stops watching ctx.
+	aborted    bool
+	plain      *bool // Whether to print plain lines; nil until resolved.
+	plainAt    time.Time
+	plainFor   int           // Progress shown by the last plain line, or -1.
+	age        time.Duration // Age of the moving average set with BarSmoothing.
+	ewma       float64       // Smoothed rate up to sampleAt.
+	sampled    bool          // Whether ewma holds a rate.
+	sampleAt   time.Time
+	sampleN    int // Progress at sampleAt.
+	phases     []Phase
+	phase      int               // Index of the current phase.
+	phaseAt    time.Time         // When the current phase started, if not the first.
+	pending    atomic.Int64      // Increments from Incr not yet added to Progress.
+	ticking    atomic.Bool       // Whether a standalone bar's refresh goroutine runs.
+	drawnAt    time.Time         // When a standalone bar was last drawn.
+	drawnCells string            // The cells drawn then.
+	stale      bool              // Whether a change is left for refresh to draw.
+	owner      *MultiProgressBar // The MultiProgressBar drawing the bar, if any.
+	parent     string            // Name of the parent bar in a MultiProgressBar.
+	depth      int               // Number of parents above the bar.
+	position   int               // Line requested with BarPosition, or -1.
+	growing    bool
+	start      time.Time
+	end        time.Time
+	finished   bool
+	mu         sync.Mutex
+}
+
+// FinishPolicy decides what becomes of a bar once it is finished.
+type FinishPolicy int
+
+// Finish policies.
+const (
+	FinishKeep     FinishPolicy = iota // Keep the bar as it is.
+	FinishCheck                        // Keep the bar with a ✔ in front.
+	FinishCollapse                     // Replace the bar with a ✔, its name, count and time.
+	FinishRemove                       // Remove the bar.
+)
+
+// BarOption configures a ProgressBar.
+type BarOption func(*ProgressBar)
+
+// BarDecorator returns text shown next to a bar, e.g. the current file of a
+// copy. It is called on every draw with the bar locked, so it may read the
+// bar's fields but must not call its methods.
+type BarDecorator func(pb *ProgressBar) string
+
+// BarName sets the label shown before the bar.
+func BarName(name string) BarOption {
+	return func(pb *ProgressBar) {
+		pb.name = name
+	}
+}
+
+// BarTemplate sets the layout of the bar. These placeholders are replaced:
+//
+//	{name}     the name set with BarName
+//	{bar}      the bar itself
+//	{percent}  the percentage done, e.g. " 45%"
+//	{count}    progress and total, e.g. "450/1000" or "12.3 MiB / 1.0 GiB"
+//	{current}  the progress
+//	{total}    the total
+//	{rate}     progress per second, e.g. "12.3 it/s" or "4.2 MiB/s"
+//	{elapsed}  time since the bar was created
+//	{eta}      estimated time left
+//	{phase}    the current phase of a bar with BarPhases, e.g. "download (2/4)"
+//
+// The default is DefaultBarTemplate.
+func BarTemplate(template string) BarOption {
+	return func(pb *ProgressBar) {
+		pb.template = template
+	}
+}
+
+// BarIndeterminate makes the bar indeterminate, e.g. for a bar created with a
+// total that turns out to be unknown.
+func BarIndeterminate() BarOption {
+	return func(pb *ProgressBar) {
+		pb.Total = 0
+	}
+}
+
+// BarParent nests the bar under the named bar of the same MultiProgressBar.
+// It is drawn indented below its parent, whose progress and total become the
+// sums of its children's. The parent finishes when all its children have.
+func BarParent(name string) BarOption {
+	return func(pb *ProgressBar) {
+		pb.parent = name
+	}
+}
+
+// BarPlain forces plain-text output on or off. Plain output prints a line
+// such as "50% (500/1000)" every 10% or 10 seconds, without escape sequences.
+// By default it is used when the bar does not write to a terminal, as in CI
+// logs and pipes.
+func BarPlain(on bool) BarOption {
+	return func(pb *ProgressBar) {
+		pb.plain = &on
+	}
+}
+
+// BarPosition places the bar on the given line of a MultiProgressBar,
+// counted from 0, instead of below the other bars. Bars already on or below
+// that line move down; the order of bars is otherwise kept as they are added
+// and removed. It has no effect on nested bars, which follow their parent.
+func BarPosition(line int) BarOption {
+	return func(pb *ProgressBar) {
+		pb.position = line
+	}
+}
+
+// Phase is a named stage of a bar, such as "download" or "extract". Weight
+// is its share of the whole bar relative to the other phases; 0 counts as 1.
+type Phase struct {
+	Name   string
+	Weight float64
+}
+
+// BarPhases splits the bar into phases that are done one after the other.
+// Each phase counts its own progress up to its own total, set when it starts
+// with StartPhase, and {bar} and {percent} show the progress of all phases
+// together. The current phase is shown with {phase}, or after the name if the
+// template has no {phase}. The rate and ETA are those of the current phase.
+func BarPhases(phases ...Phase) BarOption {
+	return func(pb *ProgressBar) {
+		pb.phases = phases
+	}
+}
+
+// BarContext ties the bar to ctx: when ctx is canceled before the bar is
+// finished, the bar is aborted as with Abort.
+func BarContext(ctx context.Context) BarOption {
+	return func(pb *ProgressBar) {
+		pb.ctx = ctx
+	}
+}
+
+// BarLog writes the state changes of the bar to w as timestamped plain
+// lines: when it starts, each 10% it passes, and when it finishes or is
+// aborted, so that a log file records what the terminal showed.
+func BarLog(w io.Writer) BarOption {
+	return func(pb *ProgressBar) {
+		pb.logOut = w
+	}
+}
+
+// BarOnComplete calls fn with the bar's name when it completes, but not when
+// it is aborted. fn runs in a goroutine of its own, so it may use the bar or
+// its MultiProgressBar, e.g. to start follow-up work.
+func BarOnComplete(fn func(name string)) BarOption {
+	return func(pb *ProgressBar) {
+		pb.onComplete = fn
+	}
+}
+
+// BarOnUpdate calls fn with the bar's name, progress and total when the
+// progress changes, at most once per interval. fn runs in a goroutine of its
+// own, e.g. to record metrics.
+func BarOnUpdate(interval time.Duration, fn func(name string, progress, total int)) BarOption {
+	return func(pb *ProgressBar) {
+		pb.onUpdate = fn
+		pb.updateGap = interval
+	}
+}
+
+// BarSmoothing makes the rate and ETA follow an exponentially weighted moving
+// average of the speed, where progress made age ago counts for about a third
+// as much as progress made now. A shorter age reacts faster to changes in
+// speed, a longer one gives a steadier ETA. By default the rate is the
+// average since the start.
+func BarSmoothing(age time.Duration) BarOption {
+	return func(pb *ProgressBar) {
+		pb.age = age
+	}
+}
+
+// BarGrowing lets the progress run past the total, raising the total along
+// with it, for work that is discovered while it is done.
+func BarGrowing() BarOption {
+	return func(pb *ProgressBar) {
+		pb.growing = true
+	}
+}
+
+// BarWidth sets the number of cells of {bar}. The default is 40.
+func BarWidth(width int) BarOption {
+	return func(pb *ProgressBar) {
+		pb.width = max(width, 1)
+	}
+}
+
+// BarRunes sets the runes of the done and remaining cells of {bar}. The
+// defaults are '█' and '-', where the '█' cell at the edge of the progress is
+// drawn partly filled with '▏', '▎', '▍' and so on.
+func BarRunes(fill, empty rune) BarOption {
+	return func(pb *ProgressBar) {
+		pb.fill = fill
+		pb.empty = empty
+	}
+}
+
+// BarColor sets the color or style of the done cells of {bar}. The default
+// is Green.
+func BarColor(style string) BarOption {
+	return func(pb *ProgressBar) {
+		pb.color = style
+	}
+}
+
+// BarDoneStyle sets the color or style of the cells once the bar is complete,
+// e.g. LightGreen. By default the BarColor is kept.
+func BarDoneStyle(style string) BarOption {
+	return func(pb *ProgressBar) {
+		pb.done = style
+	}
+}
+
+// BarPrefix shows the text returned by fn before the bar's line.
+func BarPrefix(fn BarDecorator) BarOption {
+	return func(pb *ProgressBar) {
+		pb.prefix = fn
+	}
+}
+
+// BarSuffix shows the text returned by fn after the bar's line.
+func BarSuffix(fn BarDecorator) BarOption {
+	return func(pb *ProgressBar) {
+		pb.suffix = fn
+	}
+}
+
+// BarOnFinish sets what becomes of the bar once it is finished. The default
+// is FinishKeep.
+func BarOnFinish(policy FinishPolicy) BarOption {
+	return func(pb *ProgressBar) {
+		pb.policy = policy
+	}
+}
+
+// BarASCII draws the bar with '#' and '-' only, for terminals and fonts
+// without block characters. It turns off the partial cells of the default
+// '█' fill, as does any fill set with BarRunes.
+func BarASCII() BarOption {
+	return BarRunes('#', '-')
+}
+
+// BarEdges sets the strings drawn around {bar}. The defaults are "[" and "]".
+func BarEdges(left, right string) BarOption {
+	return func(pb *ProgressBar) {
+		pb.left = left
+		pb.right = right
+	}
+}
+
+// BarUnits sets how the progress, total and rate are formatted, e.g.
+// FormatBytes, FormatBytesSI, FormatCount or a function of your own. By
+// default they are shown as plain numbers.
+func BarUnits(format func(n float64) string) BarOption {
+	return func(pb *ProgressBar) {
+		pb.unit = format
+	}
+}
+
+// BarBytes shows the progress as a byte count, e.g. "12.3 MiB / 1.0 GiB @ 4.2 MiB/s".
+func BarBytes() BarOption {
+	return BarUnits(FormatBytes)
+}
+
+// BarWriter sets where a standalone bar is drawn. It defaults to os.Stdout.
+func BarWriter(w io.Writer) BarOption {
+	return func(pb *ProgressBar) {
+		pb.out = w
+	}
+}
+
+// NewProgressBar creates a standalone progress bar counting up to total and
+// draws it. It can be updated from any goroutine.
+func NewProgressBar(total int, opts ...BarOption) *ProgressBar {
+	pb := newBar(total)
+	pb.out = os.Stdout
+	for _, opt := range opts {
+		opt(pb)
+	}
+	if pb.plain == nil {
+		plain := !isTerminal(pb.out)
+		pb.plain = &plain
+	}
+	pb.mu.Lock()
+	pb.logEvent("started")
+	pb.draw()
+	pb.mu.Unlock()
+	pb.watch(pb.Abort)
+	return pb
+}
+
+// newBar returns a bar with the default layout that is not drawn on its own.
+func newBar(total int) *ProgressBar {
+	return &ProgressBar{
+		Total:    total,
+		template: DefaultBarTemplate,
+		width:    40,
+		fill:     '█',
+		empty:    '-',
+		left:     "[",
+		right:    "]",
+		color:    Green,
+		plainFor: -1,
+		position: -1,
+		start:    time.Now(),
+	}
+}
+
+// Set sets the progress, capped at the total.
+func (pb *ProgressBar) Set(progress int) {
+	defer pb.lock()()
+	if pb.finished {
+		return
+	}
+	pb.pending.Store(0)
+	pb.set(progress)
+	pb.redraw()
+}
+
+// Add adds n to the progress.
+func (pb *ProgressBar) Add(n int) {
+	defer pb.lock()()
+	if pb.finished {
+		return
+	}
+	pb.flush()
+	pb.set(pb.Progress + n)
+	pb.redraw()
+}
+
+// Incr adds n to the progress without locking or drawing, for hot loops.
+// The increments are added to Progress and drawn on the next refresh, at most
+// 15 times per second, or by the next call of another method.
+func (pb *ProgressBar) Incr(n int) {
+	pb.pending.Add(int64(n))
+	if pb.owner != nil {
+		pb.owner.wake()
+	} else if pb.out != nil && pb.ticking.CompareAndSwap(false, true) {
+		go pb.refresh()
+	}
+}
+
+// refresh draws the increments made with Incr and the changes redraw held
+// back until there are no more.
+func (pb *ProgressBar) refresh() {
+	ticker := time.NewTicker(time.Second / 15)
+	defer ticker.Stop()
+	for range ticker.C {
+		pb.mu.Lock()
+		if !pb.finished && (pb.flush() || pb.stale) {
+			pb.draw()
+			pb.mu.Unlock()
+			continue
+		}
+		finished := pb.finished
+		pb.ticking.Store(false)
+		pb.mu.Unlock()
+		// Keep going if Incr was called while stopping.
+		if finished || pb.pending.Load() == 0 || !pb.ticking.CompareAndSwap(false, true) {
+			return
+		}
+	}
+}
+
+// SetTotal changes the total, e.g. as more work is discovered. A total of 0
+// or less makes the bar indeterminate.
+func (pb *ProgressBar) SetTotal(total int) {
+	defer pb.lock()()
+	if pb.finished {
+		return
+	}
+	pb.setTotal(total)
+	pb.redraw()
+}
+
+// StartPhase moves on to the named phase with a fresh progress counting up
+// to total. Phases skipped over count as done. Unknown names are ignored.
+func (pb *ProgressBar) StartPhase(name string, total int) {
+	defer pb.lock()()
+	if pb.finished {
+		return
+	}
+	pb.startPhase(name, total)
+	pb.redraw()
+}
+
+// Finish completes the bar and moves to the next line, or clears the bar if
+// its policy is FinishRemove.
+func (pb *ProgressBar) Finish() {
+	defer pb.lock()()
+	if pb.finished {
+		return
+	}
+	if pb.owner != nil {
+		pb.owner.finish(pb)
+		return
+	}
+	pb.complete()
+	if pb.out == nil {
+		return
+	}
+	if pb.isPlain() {
+		if pb.policy != FinishRemove && pb.plainFor != pb.Progress {
+			fmt.Fprintln(pb.out, pb.plainLine())
+		}
+		return
+	}
+	if pb.policy == FinishRemove {
+		fmt.Fprint(pb.out, "\r\033[2K")
+		return
+	}
+	pb.draw()
+	fmt.Fprintln(pb.out)
+}
+
+// Abort stops the bar where it is and marks it as aborted, drawn in red, and
+// moves to the next line. It does nothing on a finished bar.
+func (pb *ProgressBar) Abort() {
+	defer pb.lock()()
+	if pb.finished {
+		return
+	}
+	pb.abort()
+	if pb.owner != nil {
+		pb.owner.changed()
+	}
+	if pb.out == nil {
+		return
+	}
+	if pb.isPlain() {
+		fmt.Fprintln(pb.out, pb.plainLine())
+		return
+	}
+	pb.draw()
+	fmt.Fprintln(pb.out)
+}
+
+// Aborted reports whether the bar was aborted.
+func (pb *ProgressBar) Aborted() bool {
+	defer pb.lock()()
+	return pb.aborted
+}
+
+// Percent returns the progress as a percentage, or 0 for an indeterminate bar
+// that is not finished.
+func (pb *ProgressBar) Percent() float64 {
+	defer pb.lock()()
+	pb.flush()
+	return pb.fraction() * 100
+}
+
+// Elapsed returns the time since the bar was created.
+func (pb *ProgressBar) Elapsed() time.Duration {
+	defer pb.lock()()
+	return pb.elapsed()
+}
+
+// Rate returns the average progress per second.
+func (pb *ProgressBar) Rate() float64 {
+	defer pb.lock()()
+	pb.flush()
+	return pb.rate()
+}
+
+// ETA returns the estimated time until the bar completes, or 0 if it cannot
+// be estimated yet.
+func (pb *ProgressBar) ETA() time.Duration {
+	defer pb.lock()()
+	pb.flush()
+	return pb.eta()
+}
+
+// String renders the bar as a single line.
+func (pb *ProgressBar) String() string {
+	defer pb.lock()()
+	pb.flush()
+	return pb.render()
+}
+
+// lock locks the bar and returns the function unlocking it. A bar of a
+// MultiProgressBar is locked with the lock of its owner, which reads the bar
+// while drawing it.
+func (pb *ProgressBar) lock() func() {
+	if pb.owner != nil {
+		pb.owner.Lock.Lock()
+		return pb.owner.Lock.Unlock
+	}
+	pb.mu.Lock()
+	return pb.mu.Unlock
+}
+
+// redraw draws the bar after a change, or has its MultiProgressBar draw it.
+// A standalone bar whose cells are unchanged is drawn at most 15 times per
+// second, so that small steps such as reads through ProxyReader are cheap.
+func (pb *ProgressBar) redraw() {
+	if pb.owner != nil {
+		pb.owner.changed()
+		return
+	}
+	if pb.out != nil && time.Since(pb.drawnAt) < time.Second/15 && pb.cells() == pb.drawnCells {
+		pb.stale = true
+		if pb.ticking.CompareAndSwap(false, true) {
+			go pb.refresh()
+		}
+		return
+	}
+	pb.draw()
+}
+
+// indeterminate reports whether the total of the bar is unknown.
+func (pb *ProgressBar) indeterminate() bool {
+	return pb.Total <= 0
+}
+
+// set sets the progress, no less than 0 and capped at the total if it is
+// known. A growing bar raises its total instead.
+func (pb *ProgressBar) set(progress int) {
+	progress = max(progress, 0)
+	if !pb.indeterminate() {
+		if pb.growing {
+			pb.Total = max(pb.Total, progress)
+		} else {
+			progress = min(progress, pb.Total)
+		}
+	}
+	pb.Progress = progress
+	pb.sample()
+	pb.logProgress()
+	if pb.onUpdate != nil && time.Since(pb.updatedAt) >= pb.updateGap {
+		pb.updatedAt = time.Now()
+		go pb.onUpdate(pb.name, pb.Progress, pb.Total)
+	}
+}
+
+// sample folds the speed since the last sample into the moving average,
+// at most every 100ms so that bursts of updates count as one.
+func (pb *ProgressBar) sample() {
+	if pb.age <= 0 {
+		return
+	}
+	now := time.Now()
+	if pb.sampleAt.IsZero() {
+		pb.sampleAt, pb.sampleN = pb.rateStart(), 0
+	}
+	if now.Sub(pb.sampleAt) < 100*time.Millisecond {
+		return
+	}
+	pb.ewma = pb.smoothed(now)
+	pb.sampled = true
+	pb.sampleAt, pb.sampleN = now, pb.Progress
+}
+
+// smoothed returns the moving average of the speed including the progress
+// since the last sample, so that it drops while the bar stalls.
+func (pb *ProgressBar) smoothed(now time.Time) float64 {
+	dt := now.Sub(pb.sampleAt).Seconds()
+	if dt <= 0 {
+		return pb.ewma
+	}
+	speed := float64(max(pb.Progress-pb.sampleN, 0)) / dt
+	if !pb.sampled {
+		return speed
+	}
+	alpha := 1 - math.Exp(-dt/pb.age.Seconds())
+	return pb.ewma + alpha*(speed-pb.ewma)
+}
+
+// setTotal changes the total, capping the progress at it.
+func (pb *ProgressBar) setTotal(total int) {
+	pb.flush()
+	pb.Total = total
+	pb.set(pb.Progress)
+}
+
+// flush adds the increments made with Incr to the progress and reports
+// whether there were any.
+func (pb *ProgressBar) flush() bool {
+	n := pb.pending.Swap(0)
+	if n == 0 {
+		return false
+	}
+	pb.set(pb.Progress + int(n))
+	return true
+}
+
+// complete fills the bar. An indeterminate bar takes its progress as total.
+func (pb *ProgressBar) complete() {
+	pb.flush()
+	if pb.indeterminate() {
+		pb.Total = pb.Progress
+	}
+	pb.Progress = pb.Total
+	pb.finish()
+}
+
+// abort finishes the bar without filling it.
+func (pb *ProgressBar) abort() {
+	pb.flush()
+	pb.aborted = true
+	pb.finish()
+}
+
+// finish marks the bar as finished and stops watching its context.
+func (pb *ProgressBar) finish() {
+	pb.finished = true
+	pb.end = time.Now()
+	if pb.aborted {
+		pb.logEvent("aborted at " + pb.plainCount())
+	} else {
+		pb.logEvent("finished " + pb.plainCount() + " in " + formatDuration(pb.elapsed()))
+		if pb.onComplete != nil {
+			go pb.onComplete(pb.name)
+		}
+	}
+	if pb.unwatch != nil {
+		pb.unwatch()
+	}
+}
+
+// watch calls abort once the bar's context is canceled.
+func (pb *ProgressBar) watch(abort func()) {
+	if pb.ctx != nil {
+		pb.unwatch = context.AfterFunc(pb.ctx, abort)
+	}
+}
+
+// elapsed returns the time the bar has been running, up to when it finished.
+func (pb *ProgressBar) elapsed() time.Duration {
+	if pb.finished {
+		return pb.end.Sub(pb.start)
+	}
+	return time.Since(pb.start)
+}
+
+// startPhase switches to the named phase and resets the progress.
+func (pb *ProgressBar) startPhase(name string, total int) {
+	for i, phase := range pb.phases {
+		if phase.Name == name {
+			pb.flush()
+			pb.phase = i
+			pb.Progress, pb.Total = 0, total
+			pb.phaseAt = time.Now()
+			pb.logStep = 0
+			pb.logEvent(name + " started")
+			pb.sampleAt, pb.sampled = time.Time{}, false
+			return
+		}
+	}
+}
+
+// phaseLabel returns the name and number of the current phase.
+func (pb *ProgressBar) phaseLabel() string {
+	if len(pb.phases) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s (%d/%d)", pb.phases[pb.phase].Name, pb.phase+1, len(pb.phases))
+}
+
+// fraction returns the completed fraction of the bar, between 0 and 1,
+// across all phases if it has any.
+func (pb *ProgressBar) fraction() float64 {
+	if len(pb.phases) == 0 || (pb.finished && !pb.aborted) {
+		return pb.phaseFraction()
+	}
+	weight := func(p Phase) float64 {
+		if p.Weight <= 0 {
+			return 1
+		}
+		return p.Weight
+	}
+	done, sum := 0.0, 0.0
+	for i, phase := range pb.phases {
+		if i < pb.phase {
+			done += weight(phase)
+		}
+		sum += weight(phase)
+	}
+	return (done + weight(pb.phases[pb.phase])*pb.phaseFraction()) / sum
+}
+
+// phaseFraction returns the completed fraction of the current phase, or of
+// the bar if it has no phases.
+func (pb *ProgressBar) phaseFraction() float64 {
+	if pb.indeterminate() {
+		if pb.finished && !pb.aborted {
+			return 1
+		}
+		return 0
+	}
+	return min(float64(pb.Progress)/float64(pb.Total), 1)
+}
+
+// rate returns the progress per second: the moving average if smoothing is
+// on and the bar is running, otherwise the average since the start.
+func (pb *ProgressBar) rate() float64 {
+	if pb.age > 0 && !pb.finished && pb.sampled {
+		return pb.smoothed(time.Now())
+	}
+	end := time.Now()
+	if pb.finished {
+		end = pb.end
+	}
+	elapsed := end.Sub(pb.rateStart()).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(pb.Progress) / elapsed
+}
+
+// rateStart returns when the progress started counting from 0: the start of
+// the current phase, or of the bar.
+func (pb *ProgressBar) rateStart() time.Time {
+	if !pb.phaseAt.IsZero() {
+		return pb.phaseAt
+	}
+	return pb.start
+}
+
+// eta returns the estimated time left at the current rate.
+func (pb *ProgressBar) eta() time.Duration {
+	rate := pb.rate()
+	if rate <= 0 || pb.indeterminate() || pb.Progress >= pb.Total {
+		return 0
+	}
+	return time.Duration(float64(pb.Total-pb.Progress) / rate * float64(time.Second))
+}
+
+// render returns the bar laid out with its template.
+func (pb *ProgressBar) render() string {
+	indeterminate := pb.indeterminate() && !pb.finished
+	var bar string
+	if indeterminate {
+		bar = pb.left + pb.bounce() + pb.right
+	} else {
+		color := pb.color
+		if pb.done != "" && pb.fraction() >= 1 {
+			color = pb.done
+		}
+		if pb.aborted {
+			color = Red
+		}
+		bar = pb.left + color + pb.cells() + pb.right
+	}
+
+	current, total := fmt.Sprint(pb.Progress), fmt.Sprint(pb.Total)
+	count := current + "/" + total
+	rate := fmt.Sprintf("%.1f it/s", pb.rate())
+	if pb.unit != nil {
+		current, total = pb.unit(float64(pb.Progress)), pb.unit(float64(pb.Total))
+		count = current + " / " + total
+		rate = pb.unit(pb.rate()) + "/s"
+	}
+	percent := fmt.Sprintf("%3.0f%%", pb.fraction()*100)
+	eta := formatDuration(pb.eta())
+	template := pb.template
+	if indeterminate {
+		// Without a total there is nothing to compare the progress with.
+		total, count, percent, eta = "?", current, "", ""
+		if template == DefaultBarTemplate {
+			template = DefaultIndeterminateTemplate
+		}
+	}
+
+	name := pb.name
+	if len(pb.phases) > 0 && !strings.Contains(template, "{phase}") {
+		name = strings.TrimSpace(name + " " + pb.phaseLabel())
+	}
+	r := strings.NewReplacer(
+		"{name}", name,
+		"{phase}", pb.phaseLabel(),
+		"{bar}", bar,
+		"{percent}", percent,
+		"{count}", count,
+		"{current}", current,
+		"{total}", total,
+		"{rate}", rate,
+		"{elapsed}", formatDuration(pb.elapsed()),
+		"{eta}", eta,
+	)
+	line := strings.TrimSpace(r.Replace(template))
+	if pb.aborted {
+		line += " " + Red + "aborted" + End
+		if pb.policy == FinishCheck || pb.policy == FinishCollapse {
+			line = Red + "✖" + End + " " + line
+		}
+	} else if pb.finished {
+		switch pb.policy {
+		case FinishCheck:
+			line = Green + "✔" + End + " " + line
+		case FinishCollapse:
+			line = fmt.Sprintf("%s✔%s %s %s(%s in %s)%s", Green, End, pb.name, Faint, count, formatDuration(pb.elapsed()), End)
+		}
+	}
+	if pb.prefix != nil {
+		if s := pb.prefix(pb); s != "" {
+			line = s + " " + line
+		}
+	}
+	if pb.suffix != nil {
+		if s := pb.suffix(pb); s != "" {
+			line += " " + s
+		}
+	}
+	return line
+}
+
+// partialBlocks are the eighths of a '█' cell, from ⅛ to ⅞.
+var partialBlocks = []rune{'▏', '▎', '▍', '▌', '▋', '▊', '▉'}
+
+// cells returns the done cells, starting with the bar color, and the
+// remaining cells of a determinate bar. With the default '█' fill the cell at
+// the edge is filled in eighths, so progress moves smoothly on narrow bars.
+func (pb *ProgressBar) cells() string {
+	exact := float64(pb.width) * min(max(pb.fraction(), 0), 1)
+	filledLen := int(exact)
+	filled := strings.Repeat(string(pb.fill), filledLen)
+	if eighths := int((exact - float64(filledLen)) * 8); pb.fill == '█' && eighths > 0 && filledLen < pb.width {
+		filled += string(partialBlocks[eighths-1])
+		filledLen++
+	}
+	return filled + End + strings.Repeat(string(pb.empty), pb.width-filledLen)
+}
+
+// bounce returns the cells of an indeterminate bar: a block moving from one
+// edge to the other and back, one cell every 100ms.
+func (pb *ProgressBar) bounce() string {
+	size := max(pb.width/5, 1)
+	span := pb.width - size
+	pos := 0
+	if span > 0 {
+		pos = int(time.Since(pb.start)/(100*time.Millisecond)) % (2 * span)
+		if pos > span {
+			pos = 2*span - pos
+		}
+	}
+	return strings.Repeat(string(pb.empty), pos) + pb.color + strings.Repeat(string(pb.fill), size) + End +
+		strings.Repeat(string(pb.empty), span-pos)
+}
+
+// draw redraws a standalone bar in place. Bars of a MultiProgressBar have no
+// writer and are drawn by it instead.
+func (pb *ProgressBar) draw() {
+	if pb.out == nil {
+		return
+	}
+	pb.drawnAt, pb.drawnCells, pb.stale = time.Now(), pb.cells(), false
+	if pb.isPlain() {
+		if pb.plainDue() {
+			fmt.Fprintln(pb.out, pb.plainLine())
+		}
+		return
+	}
+	width, _ := termSize(pb.out)
+	fmt.Fprint(pb.out, "\r\033[2K"+pb.renderFit(width))
+}
+
+// renderFit renders the bar within cols columns, first by narrowing the bar,
+// then by shortening the name and finally by cutting off the end.
+func (pb *ProgressBar) renderFit(cols int) string {
+	line := pb.render()
+	over := visibleWidth(line) - cols
+	if over <= 0 {
+		return line
+	}
+	width, name := pb.width, pb.name
+	defer func() { pb.width, pb.name = width, name }()
+	pb.width = max(width-over, min(width, 10))
+	line = pb.render()
+	if over = visibleWidth(line) - cols; over > 0 && name != "" {
+		pb.name = truncateWidth(name, max(visibleWidth(name)-over, 1))
+		line = pb.render()
+	}
+	return truncateWidth(line, cols)
+}
+
+// formatDuration formats d as m:ss, or h:mm:ss for an hour or more.
+func formatDuration(d time.Duration) string {
+	s := int(d.Round(time.Second).Seconds())
+	if s >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", s/3600, s/60%60, s%60)
+	}
+	return fmt.Sprintf("%d:%02d", s/60, s%60)
+}
+
+// ProxyReader returns a reader that adds the bytes read from r to the bar.
+// If r is an io.Closer, closing the returned reader closes r.
+func (pb *ProgressBar) ProxyReader(r io.Reader) io.ReadCloser {
+	return &proxyReader{r: r, bar: pb}
+}
+
+// ProxyWriter returns a writer that adds the bytes written to w to the bar.
+// If w is an io.Closer, closing the returned writer closes w.
+func (pb *ProgressBar) ProxyWriter(w io.Writer) io.WriteCloser {
+	return &proxyWriter{w: w, bar: pb}
+}
+
+// proxyReader counts the bytes read through it on a bar.
+type proxyReader struct {
+	r   io.Reader
+	bar *ProgressBar
+}
+
+func (p *proxyReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.bar.Add(n)
+	}
+	return n, err
+}
+
+func (p *proxyReader) Close() error {
+	if c, ok := p.r.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// proxyWriter counts the bytes written through it on a bar.
+type proxyWriter struct {
+	w   io.Writer
+	bar *ProgressBar
+}
+
+func (p *proxyWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	if n > 0 {
+		p.bar.Add(n)
+	}
+	return n, err
+}
+
+func (p *proxyWriter) Close() error {
+	if c, ok := p.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// isPlain reports whether the bar prints plain lines.
+func (pb *ProgressBar) isPlain() bool {
+	return pb.plain != nil && *pb.plain
+}
+
+// plainDue reports whether a plain line is due: the first one, one each 10%,
+// and one every 10 seconds while there is progress.
+func (pb *ProgressBar) plainDue() bool {
+	if pb.plainFor < 0 {
+		return true
+	}
+	if pb.Progress == pb.plainFor {
+		return false
+	}
+	if !pb.indeterminate() && pb.plainFor <= pb.Total {
+		step := func(n int) int { return n * 10 / pb.Total }
+		if step(pb.Progress) != step(pb.plainFor) {
+			return true
+		}
+	}
+	return time.Since(pb.plainAt) >= 10*time.Second
+}
+
+// plainLine returns the bar as plain text, e.g. "name 50% (500/1000)", and
+// records it as the last one printed.
+func (pb *ProgressBar) plainLine() string {
+	pb.plainFor, pb.plainAt = pb.Progress, time.Now()
+	line := pb.plainCount()
+	if pb.name != "" {
+		line = pb.name + " " + line
+	}
+	if pb.aborted {
+		line += " aborted"
+	}
+	return line
+}
+
+// plainCount returns the progress as plain text, e.g. "50% (500/1000)", or
+// just the count while the total is unknown.
+func (pb *ProgressBar) plainCount() string {
+	current, total := fmt.Sprint(pb.Progress), fmt.Sprint(pb.Total)
+	sep := "/"
+	if pb.unit != nil {
+		current, total = pb.unit(float64(pb.Progress)), pb.unit(float64(pb.Total))
+		sep = " / "
+	}
+	if pb.indeterminate() && !pb.finished {
+		return current
+	}
+	return fmt.Sprintf("%.0f%% (%s%s%s)", pb.fraction()*100, current, sep, total)
+}
+
+// logEvent writes a timestamped line about the bar to its log writer.
+func (pb *ProgressBar) logEvent(event string) {
+	if pb.logOut == nil {
+		return
+	}
+	line := time.Now().Format(time.RFC3339) + " "
+	if pb.name != "" {
+		line += pb.name + " "
+	}
+	fmt.Fprintln(pb.logOut, line+event)
+}
+
+// logProgress logs each 10% milestone the bar passes.
+func (pb *ProgressBar) logProgress() {
+	if pb.logOut == nil || pb.finished || pb.indeterminate() {
+		return
+	}
+	if step := min(pb.Progress*10/pb.Total, 10); step > pb.logStep {
+		pb.logStep = step
+		if step > 0 && step < 10 {
+			pb.logEvent(pb.plainCount())
+		}
+	}
+}
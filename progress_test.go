@@ -0,0 +1,80 @@
+package ansi
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgressBarProxy(t *testing.T) {
+	var out bytes.Buffer
+	pb := NewProgressBar(100, BarWriter(&out))
+	r := pb.ProxyReader(strings.NewReader(strings.Repeat("x", 100)))
+	if _, err := io.CopyBuffer(io.Discard, r, make([]byte, 16)); err != nil {
+		t.Fatal(err)
+	}
+	if got := pb.Percent(); got != 100 {
+		t.Errorf("ProxyReader: Percent() = %v, want 100", got)
+	}
+
+	var dst bytes.Buffer
+	pb = NewProgressBar(100, BarWriter(&out))
+	w := pb.ProxyWriter(&dst)
+	if _, err := io.WriteString(w, strings.Repeat("x", 30)); err != nil {
+		t.Fatal(err)
+	}
+	if pb.Progress != 30 || dst.Len() != 30 {
+		t.Errorf("ProxyWriter: Progress = %d with %d bytes written, want 30", pb.Progress, dst.Len())
+	}
+}
+
+func TestProgressBarBounds(t *testing.T) {
+	var out bytes.Buffer
+	pb := NewProgressBar(100, BarWriter(&out))
+	pb.Set(-3)
+	if pb.Progress != 0 {
+		t.Errorf("Set(-3): Progress = %d, want 0", pb.Progress)
+	}
+	_ = pb.String() // Drawing the cells must not panic.
+	pb.Set(10)
+	pb.Add(-50)
+	if pb.Progress != 0 {
+		t.Errorf("Add(-50): Progress = %d, want 0", pb.Progress)
+	}
+	pb.Set(150)
+	if pb.Progress != 100 || pb.Percent() != 100 {
+		t.Errorf("Set(150): Progress = %d at %v%%, want 100 at 100%%", pb.Progress, pb.Percent())
+	}
+	pb.Finish()
+
+	mpb := NewMultiProgressBar(MultiBarWriter(&out))
+	mpb.AddBar("a", 10)
+	mpb.UpdateBar("a", -5)
+	if got := mpb.Bar("a").Progress; got != 0 {
+		t.Errorf("UpdateBar(-5): Progress = %d, want 0", got)
+	}
+	mpb.FinishBar("a")
+	mpb.Stop()
+}
+
+func TestProgressBarRedrawThrottle(t *testing.T) {
+	var out syncBuffer
+	pb := NewProgressBar(1<<20, BarWriter(&out), BarPlain(false), BarWidth(10))
+	r := pb.ProxyReader(strings.NewReader(strings.Repeat("x", 1000)))
+	if _, err := io.CopyBuffer(io.Discard, r, make([]byte, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(out.String(), "\r"); n > 10 {
+		t.Errorf("1000 reads drew the bar %d times", n)
+	}
+
+	// The last change is drawn by the next refresh.
+	time.Sleep(time.Second / 5)
+	got := out.String()
+	if last := got[strings.LastIndex(got, "\r"):]; !strings.Contains(last, " 1000/1048576 ") {
+		t.Errorf("last draw = %q, want the progress at 1000", last)
+	}
+	pb.Finish()
+}
@@ -0,0 +1,19 @@
+//go:build !windows
+
+package ansi
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyResize sends to c when the terminal is resized.
+func notifyResize(c chan<- os.Signal) {
+	signal.Notify(c, syscall.SIGWINCH)
+}
+
+// stopResize stops the notifications set up by notifyResize.
+func stopResize(c chan<- os.Signal) {
+	signal.Stop(c)
+}
@@ -0,0 +1,12 @@
+//go:build windows
+
+package ansi
+
+import "os"
+
+// notifyResize does nothing on Windows, which has no resize signal. Size
+// changes are picked up by the next draw instead.
+func notifyResize(c chan<- os.Signal) {}
+
+// stopResize does nothing on Windows.
+func stopResize(c chan<- os.Signal) {}
@@ -0,0 +1,113 @@
+package ansi
+
+import (
+	"math"
+	"strings"
+)
+
+// --------------------
+// Scrollbar
+// --------------------
+
+// Scrollbar is the set of strings and styles a scrollbar is drawn with: a
+// track the height of the view, with a thumb on it as long, relative to the
+// track, as the view is to its content. Each string should be one column
+// wide.
+type Scrollbar struct {
+	Track, Thumb           string
+	TrackStyle, ThumbStyle string
+}
+
+// Scrollbar styles.
+var (
+	ScrollbarLight = Scrollbar{Track: "│", Thumb: "┃", TrackStyle: Faint}
+	ScrollbarBlock = Scrollbar{Track: "░", Thumb: "█", TrackStyle: Faint}
+	ScrollbarASCII = Scrollbar{Track: "|", Thumb: "#", TrackStyle: Faint}
+)
+
+// defaultScrollbar returns ScrollbarLight, or ScrollbarASCII if the locale
+// is not UTF-8.
+func defaultScrollbar() *Scrollbar {
+	if !unicodeLocale() {
+		return &ScrollbarASCII
+	}
+	return &ScrollbarLight
+}
+
+// scrollThumb returns the first row and the length of the thumb on a track
+// of height rows, for a view of visible rows from offset in total rows.
+func scrollThumb(height, total, visible, offset int) (int, int) {
+	if total <= visible || height <= 0 {
+		return 0, height
+	}
+	size := max(int(math.Round(float64(height)*float64(visible)/float64(total))), 1)
+	size = min(size, height)
+	offset = max(min(offset, total-visible), 0)
+	start := int(math.Round(float64(height-size) * float64(offset) / float64(total-visible)))
+	return start, size
+}
+
+// Cells returns the scrollbar from top to bottom, one string per row of a
+// track height rows high, for a view of visible rows from offset in total
+// rows.
+func (s Scrollbar) Cells(height, total, visible, offset int) []string {
+	start, size := scrollThumb(height, total, visible, offset)
+	cells := make([]string, max(height, 0))
+	for i := range cells {
+		glyph, style := s.Track, s.TrackStyle
+		if i >= start && i < start+size {
+			glyph, style = s.Thumb, s.ThumbStyle
+		}
+		if style != "" {
+			glyph = style + glyph + End
+		}
+		cells[i] = glyph
+	}
+	return cells
+}
+
+// Attach returns lines, cut and padded to width columns less one, with the
+// scrollbar in the last column, for a view of visible rows from offset in
+// total rows. The lines are returned as they are if the content fits.
+func (s Scrollbar) Attach(lines []string, width, total, visible, offset int) []string {
+	if total <= visible || width < 2 {
+		return lines
+	}
+	cells := s.Cells(len(lines), total, visible, offset)
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		out[i] = padWidth(truncateWidth(line, width-1), width-1) + cells[i]
+	}
+	return out
+}
+
+// ScrollOffset returns the offset a view of visible rows in total rows
+// scrolls to when the track of a scrollbar height rows high is clicked, or
+// its thumb dragged, at row, counted from 0: the one that centers the thumb
+// on the row.
+func ScrollOffset(row, height, total, visible int) int {
+	if total <= visible || height <= 0 {
+		return 0
+	}
+	_, size := scrollThumb(height, total, visible, 0)
+	if height == size {
+		return 0
+	}
+	offset := int(math.Round(float64(row-size/2) * float64(total-visible) / float64(height-size)))
+	return max(min(offset, total-visible), 0)
+}
+
+// attachRows attaches s, as Attach does, to the lines of text, each ending
+// with a newline.
+func (s Scrollbar) attachRows(text string, width, total, visible, offset int) string {
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	return strings.Join(s.Attach(lines, width, total, visible, offset), "\n") + "\n"
+}
+
+// listScrollbar attaches the default scrollbar to the lines of a list below
+// a prompt, showing visible of total options from top, when they do not all
+// fit.
+func (cfg *inputConfig) listScrollbar(lines []string, total, visible, top int) []string {
+	width, _ := termSize(cfg.out)
+	return defaultScrollbar().Attach(lines, width, total, visible, top)
+}
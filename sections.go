@@ -0,0 +1,286 @@
+package ansi
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// --------------------
+// Sections
+// --------------------
+
+// SectionMode is how Sections marks where sections begin and end.
+type SectionMode int
+
+// Section modes.
+const (
+	SectionPlain       SectionMode = iota // Headers without escape sequences.
+	SectionInteractive                    // Styled headers; finished sections fold up to their header.
+	SectionGitHub                         // GitHub Actions ::group:: commands.
+	SectionGitLab                         // GitLab CI section_start and section_end markers.
+)
+
+// section is a titled part of the output of Sections, or output between
+// sections if it has no title.
+type section struct {
+	title    string
+	lines    []string
+	start    time.Time
+	took     time.Duration
+	open     bool
+	expanded bool // Whether Browse shows the lines.
+}
+
+// Sections is an io.Writer whose output is divided into titled sections,
+// each started by Begin and ended by End, like the steps of a build. Headers
+// show whether a section is expanded (▾) or collapsed (▸). In a terminal a
+// finished section folds up to its header, and Browse shows all of them to
+// be expanded and collapsed again. In GitHub Actions and GitLab CI the
+// sections become the folding groups of the job log. It is safe for use from
+// several goroutines.
+type Sections struct {
+	Mode  SectionMode // Set by NewSections from the environment.
+	Style string      // Style of the headers in SectionInteractive. The default is Bold.
+	Fold  bool        // Whether finished sections fold up in SectionInteractive. The default is true.
+
+	mu       sync.Mutex
+	out      io.Writer
+	sections []*section
+	partial  string // Output after the last line break.
+	rows     int    // Screen rows drawn for the open section, including its header.
+	count    int    // Sections begun, naming them for GitLab.
+}
+
+// NewSections creates sections writing to w, in SectionGitHub if the
+// GITHUB_ACTIONS variable is set to "true", in SectionGitLab if GITLAB_CI is,
+// in SectionInteractive if w is a terminal and in SectionPlain otherwise.
+func NewSections(w io.Writer) *Sections {
+	mode := SectionPlain
+	switch {
+	case os.Getenv("GITHUB_ACTIONS") == "true":
+		mode = SectionGitHub
+	case os.Getenv("GITLAB_CI") == "true":
+		mode = SectionGitLab
+	case isTerminal(w):
+		mode = SectionInteractive
+	}
+	return &Sections{Mode: mode, Style: Bold, Fold: true, out: w}
+}
+
+// current returns the open section, or nil.
+func (s *Sections) current() *section {
+	if n := len(s.sections); n > 0 && s.sections[n-1].open {
+		return s.sections[n-1]
+	}
+	return nil
+}
+
+// Begin starts a section titled title, ending the open one if there is one.
+func (s *Sections) Begin(title string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.current() != nil {
+		s.end()
+	}
+	s.flush()
+	sec := &section{title: title, start: time.Now(), open: true}
+	s.sections = append(s.sections, sec)
+	s.count++
+	switch s.Mode {
+	case SectionGitHub:
+		fmt.Fprintf(s.out, "::group::%s\n", title)
+	case SectionGitLab:
+		fmt.Fprintf(s.out, "\033[0Ksection_start:%d:%s[collapsed=true]\r\033[0K%s\n", sec.start.Unix(), s.gitLabName(), title)
+	case SectionInteractive:
+		fmt.Fprintln(s.out, s.header(sec))
+		s.rows = 1
+	default:
+		fmt.Fprintln(s.out, "▾ "+title)
+	}
+}
+
+// End ends the open section. Ending when no section is open does nothing.
+func (s *Sections) End() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.end()
+}
+
+// end ends the open section.
+func (s *Sections) end() {
+	sec := s.current()
+	if sec == nil {
+		return
+	}
+	s.flush()
+	sec.open = false
+	sec.took = time.Since(sec.start)
+	switch s.Mode {
+	case SectionGitHub:
+		fmt.Fprintln(s.out, "::endgroup::")
+	case SectionGitLab:
+		fmt.Fprintf(s.out, "\033[0Ksection_end:%d:%s\r\033[0K\n", time.Now().Unix(), s.gitLabName())
+	case SectionInteractive:
+		// Fold the section up to its header if it is all still on the screen.
+		_, height := termSize(s.out)
+		if s.Fold && s.rows < height {
+			fmt.Fprintf(s.out, "\033[%dA\r\033[J%s\n", s.rows, s.header(sec))
+		}
+	}
+	s.rows = 0
+}
+
+// gitLabNameChars matches characters not allowed in GitLab section names.
+var gitLabNameChars = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+
+// gitLabName returns the name of the latest section for its GitLab markers,
+// which must be the same at its start and end.
+func (s *Sections) gitLabName() string {
+	title := gitLabNameChars.ReplaceAllString(strings.ToLower(s.sections[len(s.sections)-1].title), "_")
+	return fmt.Sprintf("section_%d_%s", s.count, title)
+}
+
+// header returns the header line of sec: its title, then once it has ended
+// how many lines it has and how long it took.
+func (s *Sections) header(sec *section) string {
+	mark := "▸"
+	if sec.open || sec.expanded {
+		mark = "▾"
+	}
+	header := Cyan + mark + End + " " + s.Style + sec.title + End
+	if !sec.open {
+		header += Faint + fmt.Sprintf(" (%s, %s)", pluralize(len(sec.lines), "line"), sec.took.Round(time.Millisecond)) + End
+	}
+	return header
+}
+
+// pluralize returns n followed by noun, with an "s" unless n is 1.
+func pluralize(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// Write writes p to the open section, or between sections if none is open.
+func (s *Sections) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	text := s.partial + string(p)
+	lines := strings.Split(text, "\n")
+	s.partial = lines[len(lines)-1]
+	for _, line := range lines[:len(lines)-1] {
+		s.addLine(line)
+	}
+	return len(p), nil
+}
+
+// flush adds output after the last line break as a line of its own.
+func (s *Sections) flush() {
+	if s.partial != "" {
+		s.addLine(s.partial)
+		s.partial = ""
+	}
+}
+
+// addLine adds a line to the open section, or between sections, and prints
+// it.
+func (s *Sections) addLine(line string) {
+	sec := s.current()
+	if sec == nil {
+		if n := len(s.sections); n == 0 || s.sections[n-1].title != "" {
+			s.sections = append(s.sections, &section{expanded: true})
+		}
+		sec = s.sections[len(s.sections)-1]
+	}
+	sec.lines = append(sec.lines, line)
+	if s.Mode == SectionInteractive && sec.title != "" {
+		line = "  " + line
+		width, _ := termSize(s.out)
+		s.rows += max((visibleWidth(line)+width-1)/max(width, 1), 1)
+	}
+	fmt.Fprintln(s.out, line)
+}
+
+// Browse shows the output in the alternate screen with every section
+// collapsed to its header. Up and down (or k and j) move between headers,
+// Enter or Space expands or collapses the selected one, + and - expand and
+// collapse all of them, and PageUp and PageDown scroll. q, Escape and Ctrl-C
+// quit. InputWriter and InputKeyReader may be given in opts.
+func (s *Sections) Browse(opts ...InputOption) error {
+	s.mu.Lock()
+	s.flush()
+	var headers []*section
+	for _, sec := range s.sections {
+		if sec.title != "" {
+			sec.expanded = false
+			headers = append(headers, sec)
+		}
+	}
+	s.mu.Unlock()
+	selected, top, height := 0, 0, 0
+	follow := true // Whether to scroll to the selected header.
+
+	// lines returns the lines shown and the one of the selected header.
+	lines := func() ([]string, int) {
+		var out []string
+		at := 0
+		for _, sec := range s.sections {
+			if sec.title == "" {
+				out = append(out, sec.lines...)
+				continue
+			}
+			header := s.header(sec)
+			if len(headers) > 0 && sec == headers[selected] {
+				at = len(out)
+				header = Negative + stripANSI(header) + End
+			}
+			out = append(out, header)
+			if sec.expanded {
+				for _, line := range sec.lines {
+					out = append(out, "  "+line)
+				}
+			}
+		}
+		return out, at
+	}
+	return runScreen(opts, false, func(r Rect) string {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		out, at := lines()
+		height = r.Height
+		if follow {
+			top = min(max(top, at-r.Height+1), at)
+		}
+		top = max(min(top, len(out)-r.Height), 0)
+		return renderRect(r, func(width, height int) []string {
+			return out[top:min(top+height, len(out))]
+		})
+	}, func(keyType, key string) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		follow = true
+		switch {
+		case len(headers) == 0:
+		case keyType == "Arrow" && key == "up", keyType == "Character" && key == "k":
+			selected = max(selected-1, 0)
+		case keyType == "Arrow" && key == "down", keyType == "Character" && key == "j":
+			selected = min(selected+1, len(headers)-1)
+		case keyType == "Special" && key == "enter", keyType == "Character" && key == " ":
+			headers[selected].expanded = !headers[selected].expanded
+		case keyType == "Character" && (key == "+" || key == "-"):
+			for _, sec := range headers {
+				sec.expanded = key == "+"
+			}
+		case keyType == "Special" && key == "pageup":
+			top, follow = max(top-height, 0), false
+		case keyType == "Special" && key == "pagedown":
+			top, follow = top+height, false
+		}
+	})
+}
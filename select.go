@@ -0,0 +1,195 @@
+package ansi
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// --------------------
+// Select
+// --------------------
+
+// Select shows options in a list below prompt and returns the index of the
+// one chosen with Enter. Up and down, or k and j, move the highlight. Escape
+// and Ctrl-C cancel with ErrInterrupted. Long lists scroll, showing the
+// position below them, and SelectPageSize sets how many options are shown
+// at once. InputDefault selects the option
+// with that text at first; InputPromptStyle, InputWriter and InputKeyReader
+// may be given in opts as well.
+func Select(prompt string, options []string, opts ...InputOption) (int, error) {
+	cfg := &inputConfig{out: os.Stdout}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	index := 0
+	for i, option := range options {
+		if option == cfg.defaultText {
+			index = i
+		}
+	}
+	return pickOne(cfg, prompt, textOptions(options), index)
+}
+
+// SelectOption is an option of SelectOptions.
+type SelectOption struct {
+	Text        string // Shown in the list, and after the prompt once chosen.
+	Description string // Optional line shown below the text. It may contain colors.
+	Key         string // Optional key, like "r", that chooses the option at once.
+}
+
+// SelectOptions is Select for options with descriptions and hotkeys. The
+// description of an option is shown on a faint line below it, and pressing
+// the key of an option chooses it without Enter. Keys take precedence over j
+// and k. InputDefault selects the option with that text at first.
+func SelectOptions(prompt string, options []SelectOption, opts ...InputOption) (int, error) {
+	cfg := &inputConfig{out: os.Stdout}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	index := 0
+	for i, option := range options {
+		if option.Text == cfg.defaultText {
+			index = i
+		}
+	}
+	return pickOne(cfg, prompt, options, index)
+}
+
+// textOptions returns options without descriptions or keys.
+func textOptions(texts []string) []SelectOption {
+	options := make([]SelectOption, len(texts))
+	for i, text := range texts {
+		options[i].Text = text
+	}
+	return options
+}
+
+// pickOne shows options below the prompt and returns the index chosen with
+// the arrow keys (or j and k) and Enter. Lists longer than the page scroll,
+// with PageUp and PageDown moving a page at a time. Once chosen, the list
+// collapses into one line.
+func pickOne(cfg *inputConfig, prompt string, options []SelectOption, index int) (int, error) {
+	if cfg.keys == nil {
+		cfg.keys = defaultKeyReader()
+	}
+	if cfg.promptStyle != "" {
+		prompt = cfg.promptStyle + prompt + End
+	}
+	page := cfg.listPage()
+	described, keyed := false, false
+	for _, option := range options {
+		described = described || option.Description != ""
+		keyed = keyed || option.Key != ""
+	}
+	if described && cfg.pageSize <= 0 {
+		// Options take two lines each.
+		page = max(page/2, 1)
+	}
+	top := 0
+	fmt.Fprint(cfg.out, "\033[?25l")
+	defer fmt.Fprint(cfg.out, "\033[?25h")
+	for {
+		top = scrollList(index, top, page)
+		var sb strings.Builder
+		sb.WriteString("\r\033[J" + prompt)
+		end := min(top+page, len(options))
+		var lines []string
+		for i := top; i < end; i++ {
+			text := options[i].Text
+			if keyed {
+				key := "   "
+				if options[i].Key != "" {
+					key = "[" + options[i].Key + "]"
+				}
+				text = key + " " + text
+			}
+			if i == index {
+				lines = append(lines, Cyan+"> "+text+End)
+			} else {
+				lines = append(lines, "  "+text)
+			}
+			if desc := options[i].Description; desc != "" {
+				indent := "    "
+				if keyed {
+					indent += "    "
+				}
+				lines = append(lines, indent+Faint+desc+End)
+			}
+		}
+		for _, line := range cfg.listScrollbar(lines, len(options), end-top, top) {
+			sb.WriteString("\r\n" + line)
+		}
+		up := len(lines)
+		if len(options) > page {
+			sb.WriteString("\r\n" + listPosition(index, len(options)))
+			up++
+		}
+		if up > 0 {
+			sb.WriteString(fmt.Sprintf("\033[%dA", up))
+		}
+		fmt.Fprint(cfg.out, sb.String())
+
+		keyType, key, err := cfg.keys.readKey()
+		if err != nil {
+			return index, err
+		}
+		if keyType == "Character" && keyed {
+			if i := slices.IndexFunc(options, func(o SelectOption) bool { return o.Key == key }); i >= 0 {
+				index = i
+				keyType, key = "Special", "enter"
+			}
+		}
+		switch {
+		case (keyType == "Arrow" && key == "up" || keyType == "Character" && key == "k") && index > 0:
+			index--
+		case (keyType == "Arrow" && key == "down" || keyType == "Character" && key == "j") && index < len(options)-1:
+			index++
+		case keyType == "Special" && key == "pageup":
+			index = max(index-page, 0)
+		case keyType == "Special" && key == "pagedown":
+			index = max(min(index+page, len(options)-1), 0)
+		case keyType == "Special" && key == "enter" && len(options) > 0:
+			fmt.Fprintf(cfg.out, "\r\033[J%s %s\n", prompt, Cyan+options[index].Text+End)
+			return index, nil
+		case keyType == "Special" && (key == "escape" || key == "ctrl-c"):
+			fmt.Fprint(cfg.out, "\r\033[J"+prompt+"\n")
+			if key == "escape" && cfg.escapeBack {
+				return index, errBack
+			}
+			return index, ErrInterrupted
+		}
+	}
+}
+
+// SelectPageSize sets how many options Select, SelectOptions, MultiSelect
+// and FuzzySelect, or nodes Tree.Pick, show at once; longer lists scroll.
+func SelectPageSize(n int) InputOption {
+	return func(c *inputConfig) {
+		c.pageSize = n
+	}
+}
+
+// listPage returns how many options a list below a prompt shows at once: the
+// size set with SelectPageSize, or as many as fit in the terminal below the
+// prompt, leaving a line for the position and one for an error.
+func (cfg *inputConfig) listPage() int {
+	if cfg.pageSize > 0 {
+		return cfg.pageSize
+	}
+	_, height := termSize(cfg.out)
+	return max(height-3, 1)
+}
+
+// scrollList returns the first visible option of a list showing page
+// options from top, moved just enough to show option index.
+func scrollList(index, top, page int) int {
+	return max(min(top, index), index-page+1, 0)
+}
+
+// listPosition returns the position indicator shown below a scrolled list,
+// e.g. "12/240".
+func listPosition(index, total int) string {
+	return fmt.Sprintf("%s%d/%d%s", Faint, index+1, total, End)
+}
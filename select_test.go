@@ -0,0 +1,99 @@
+package ansi
+
+import (
+	"bytes"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSelect(t *testing.T) {
+	options := []string{"red", "green", "blue"}
+	tests := []struct {
+		input string
+		want  int
+	}{
+		{"\r", 0},
+		{"jj\r", 2},
+		{"\x1b[B\x1b[B\x1b[A\r", 1},
+	}
+	for _, tt := range tests {
+		got, err := Select("Color:", options, keys(tt.input)...)
+		if got != tt.want || err != nil {
+			t.Errorf("Select(%q) = %d, %v; want %d, nil", tt.input, got, err, tt.want)
+		}
+	}
+}
+
+func TestSelectPageSize(t *testing.T) {
+	options := []string{"a", "b", "c", "d", "e"}
+	got, err := Select("Letter:", options, append(keys("\x1b[6~\r"), SelectPageSize(2))...)
+	if got != 2 || err != nil {
+		t.Errorf("PageDown with SelectPageSize(2) = %d, %v; want 2, nil", got, err)
+	}
+	// The size of the suggestion dropdown of Input does not apply.
+	got, err = Select("Letter:", options, append(keys("\x1b[6~\r"), InputDropdown(2))...)
+	if got != 4 || err != nil {
+		t.Errorf("PageDown with InputDropdown(2) = %d, %v; want 4, nil", got, err)
+	}
+}
+
+func TestMultiSelect(t *testing.T) {
+	got, err := MultiSelect("Colors:", []string{"red", "green", "blue"}, keys(" jj \r")...)
+	if want := []int{0, 2}; !slices.Equal(got, want) || err != nil {
+		t.Errorf("MultiSelect = %v, %v; want %v, nil", got, err, want)
+	}
+}
+
+func TestMultiSelectBounds(t *testing.T) {
+	options := []string{"red", "green", "blue"}
+	tests := []struct {
+		name  string
+		input string
+		opts  []InputOption
+		want  []int
+	}{
+		// Enter with one option chosen is refused, so green is added.
+		{"min", " \rj \r", []InputOption{SelectMin(2)}, []int{0, 1}},
+		// Space on green is refused with red chosen.
+		{"max", " j \r", []InputOption{SelectMax(1)}, []int{0}},
+		// The bounds of numeric prompts do not apply.
+		{"input bounds", "\r", []InputOption{InputMin(2), InputMax(1)}, nil},
+	}
+	for _, tt := range tests {
+		got, err := MultiSelect("Colors:", options, append(keys(tt.input), tt.opts...)...)
+		if !slices.Equal(got, tt.want) || err != nil {
+			t.Errorf("%s: MultiSelect(%q) = %v, %v; want %v, nil", tt.name, tt.input, got, err, tt.want)
+		}
+	}
+}
+
+func TestFuzzySelect(t *testing.T) {
+	options := []string{"red", "green", "blue", strings.Repeat("long ", 40)}
+	tests := []struct {
+		input string
+		want  int
+		err   error
+	}{
+		{"gr\r", 1, nil},
+		{"bl\x03", 2, ErrInterrupted},
+		{"\x1b[B", 1, io.EOF},
+		{"zz\x03", -1, ErrInterrupted},
+	}
+	for _, tt := range tests {
+		got, err := FuzzySelect("Color:", options, keys(tt.input)...)
+		if got != tt.want || err != tt.err {
+			t.Errorf("FuzzySelect(%q) = %d, %v; want %d, %v", tt.input, got, err, tt.want, tt.err)
+		}
+	}
+
+	var out bytes.Buffer
+	FuzzySelect("Color:", options, InputKeyReader(NewKeyReader(strings.NewReader("\r"))), InputWriter(&out))
+	width, _ := termSize(&out)
+	for _, line := range strings.Split(out.String(), "\r\n") {
+		if visibleWidth(line) > width {
+			t.Errorf("row %q is wider than %d columns", line, width)
+		}
+	}
+}
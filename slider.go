@@ -0,0 +1,254 @@
+package ansi
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// --------------------
+// Slider
+// --------------------
+
+// Slider picks a number in a range by moving a handle along a track with the
+// arrow keys or by dragging it with the mouse.
+type Slider struct {
+	Label    string
+	Value    float64
+	Min, Max float64
+	Step     float64              // Amount the arrow keys move the value. The default is 1.
+	Width    int                  // Width of String; 0 uses 40 columns.
+	Style    string               // Style of the track up to the handle. The default is Cyan.
+	Format   func(float64) string // Formats the value shown after the track.
+
+	track    Rect // Where the track was last drawn, for HandleMouse.
+	dragging bool
+}
+
+// NewSlider creates a slider of value between min and max.
+func NewSlider(label string, min, max, value float64) *Slider {
+	s := &Slider{Label: label, Min: min, Max: max, Step: 1, Style: Cyan}
+	s.SetValue(value)
+	return s
+}
+
+// SetValue sets the value, rounded to a whole number of steps from Min and
+// kept within Min and Max.
+func (s *Slider) SetValue(v float64) {
+	if step := s.step(); !math.IsInf(v, 0) && !math.IsNaN(v) {
+		v = s.Min + math.Round((v-s.Min)/step)*step
+		// Drop the error of the sum, so 0.1 steps give 0.6 and not 0.6000000000000001.
+		scale := math.Pow10(s.decimals())
+		v = math.Round(v*scale) / scale
+	}
+	s.Value = math.Min(math.Max(v, s.Min), s.Max)
+}
+
+// step returns the amount the arrow keys move the value.
+func (s *Slider) step() float64 {
+	if s.Step > 0 {
+		return s.Step
+	}
+	return 1
+}
+
+// fraction returns how far along the range the value is, from 0 to 1.
+func (s *Slider) fraction() float64 {
+	if s.Max <= s.Min {
+		return 0
+	}
+	return math.Min(math.Max((s.Value-s.Min)/(s.Max-s.Min), 0), 1)
+}
+
+// format returns the value as shown after the track.
+func (s *Slider) format(v float64) string {
+	if s.Format != nil {
+		return s.Format(v)
+	}
+	return strconv.FormatFloat(v, 'f', s.decimals(), 64)
+}
+
+// decimals returns the number of decimals of the step.
+func (s *Slider) decimals() int {
+	step := strconv.FormatFloat(s.step(), 'f', -1, 64)
+	if i := strings.Index(step, "."); i >= 0 {
+		return len(step) - i - 1
+	}
+	return 0
+}
+
+// HandleKey moves the value and reports whether the key was used: left and
+// right (or h and l) by a step, PageUp and PageDown by ten steps, and Home
+// and End to the ends of the range.
+func (s *Slider) HandleKey(keyType, key string) bool {
+	switch {
+	case keyType == "Arrow" && key == "left", keyType == "Character" && key == "h":
+		s.SetValue(s.Value - s.step())
+	case keyType == "Arrow" && key == "right", keyType == "Character" && key == "l":
+		s.SetValue(s.Value + s.step())
+	case keyType == "Special" && key == "pagedown":
+		s.SetValue(s.Value - 10*s.step())
+	case keyType == "Special" && key == "pageup":
+		s.SetValue(s.Value + 10*s.step())
+	case keyType == "Special" && key == "home":
+		s.SetValue(s.Min)
+	case keyType == "Special" && key == "end":
+		s.SetValue(s.Max)
+	default:
+		return false
+	}
+	return true
+}
+
+// HandleMouse moves the handle to where the track is clicked or dragged with
+// the left button, and reports whether the event was used. A track drawn by
+// Lines rather than Render has no known row, so any row matches it.
+func (s *Slider) HandleMouse(ev MouseEvent) bool {
+	t := s.track
+	if t.Width <= 0 {
+		return false
+	}
+	switch {
+	case ev.Release:
+		used := s.dragging
+		s.dragging = false
+		return used
+	case ev.Motion && !s.dragging:
+		return false
+	case ev.Button != MouseLeft:
+		return false
+	case !ev.Motion && (t.Row > 0 && ev.Row != t.Row || ev.Col < t.Col || ev.Col >= t.Col+t.Width):
+		return false
+	}
+	s.dragging = true
+	at := min(max(ev.Col-t.Col, 0), t.Width-1)
+	if t.Width > 1 {
+		s.SetValue(s.Min + (s.Max-s.Min)*float64(at)/float64(t.Width-1))
+	}
+	return true
+}
+
+// line returns the slider fitted in width columns and the column the track
+// starts at, counted from 0.
+func (s *Slider) line(width int) (string, int) {
+	label := ""
+	if s.Label != "" {
+		label = s.Label + " "
+	}
+	// Keep room for the widest value so the track does not move as it changes.
+	value := s.format(s.Value)
+	valueWidth := max(visibleWidth(s.format(s.Min)), visibleWidth(s.format(s.Max)), visibleWidth(value))
+	area := max(width-visibleWidth(label)-valueWidth-1, 2)
+	handle := int(math.Round(s.fraction() * float64(area-1)))
+	track := s.Style + strings.Repeat("━", handle) + Bold + "●" + End + Faint + strings.Repeat("─", area-handle-1) + End
+	value = strings.Repeat(" ", valueWidth-visibleWidth(value)) + value
+	s.track = Rect{Col: visibleWidth(label) + 1, Width: area}
+	return truncateWidth(label+track+" "+s.Style+value+End, width), visibleWidth(label)
+}
+
+// Lines returns the slider on a line of width columns, for drawing it in a
+// Pane, GridCell or Tab.
+func (s *Slider) Lines(width, height int) []string {
+	if width <= 0 || height <= 0 {
+		return nil
+	}
+	line, _ := s.line(width)
+	return []string{line}
+}
+
+// Render draws the slider on the first row of r, so HandleMouse can find
+// its track.
+func (s *Slider) Render(r Rect) string {
+	line, at := s.line(r.Width)
+	s.track.Row, s.track.Col = r.Row, r.Col+at
+	line += strings.Repeat(" ", max(r.Width-visibleWidth(line), 0))
+	return fmt.Sprintf("\033[%d;%dH%s", r.Row, r.Col, line)
+}
+
+// Print prints the slider to stdout.
+func (s *Slider) Print() {
+	fmt.Print(s.String())
+}
+
+// String renders the slider in Width, ending with a newline.
+func (s *Slider) String() string {
+	width := s.Width
+	if width <= 0 {
+		width = 40
+	}
+	line, _ := s.line(width)
+	return line + "\n"
+}
+
+// InputSlider asks for a number between lo and hi on a slider, moved with
+// the arrow keys or dragged with the mouse, and redrawn as it changes.
+// InputDefault sets the value shown first, which is otherwise lo, InputStep
+// sets how much the arrow keys move it and InputValidator checks the chosen
+// value as shown. Escape and Ctrl-C cancel with ErrInterrupted.
+// InputPromptStyle, InputErrorStyle, InputWriter and InputKeyReader may also
+// be given in opts.
+func InputSlider(prompt string, lo, hi float64, opts ...InputOption) (float64, error) {
+	cfg := &inputConfig{out: os.Stdout, errorStyle: Red}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.keys == nil {
+		cfg.keys = defaultKeyReader()
+	}
+	if cfg.promptStyle != "" {
+		prompt = cfg.promptStyle + prompt + End
+	}
+	s := NewSlider("", lo, hi, lo)
+	if cfg.step > 0 {
+		s.Step = cfg.step
+	}
+	if v, err := strconv.ParseFloat(strings.TrimSpace(cfg.defaultText), 64); err == nil {
+		s.SetValue(v)
+	}
+
+	fmt.Fprint(cfg.out, "\033[?25l")
+	defer fmt.Fprint(cfg.out, "\033[?25h")
+	EnableMouse(cfg.out)
+	defer DisableMouse(cfg.out)
+	message := ""
+	for {
+		width, _ := termSize(cfg.out)
+		line, _ := s.line(min(width-visibleWidth(prompt)-1, 60))
+		s.track.Col += visibleWidth(prompt) + 1
+		hint := Faint + "←→ change · pgup/pgdn ×10 · enter ok" + End
+		if message != "" {
+			hint = cfg.errorStyle + message + End
+		}
+		fmt.Fprintf(cfg.out, "\r\033[J%s %s\r\n%s\033[A", prompt, line, hint)
+
+		keyType, key, err := cfg.keys.readKey()
+		if err != nil {
+			return s.Value, err
+		}
+		switch {
+		case keyType == "Special" && key == "enter":
+			if cfg.validate != nil {
+				if err := cfg.validate(s.format(s.Value)); err != nil {
+					message = err.Error()
+					continue
+				}
+			}
+			fmt.Fprintf(cfg.out, "\r\033[J%s %s\n", prompt, Cyan+s.format(s.Value)+End)
+			return s.Value, nil
+		case keyType == "Special" && (key == "escape" || key == "ctrl-c"):
+			fmt.Fprint(cfg.out, "\r\033[J"+prompt+"\n")
+			if key == "escape" && cfg.escapeBack {
+				return s.Value, errBack
+			}
+			return s.Value, ErrInterrupted
+		case keyType == "Mouse":
+			if ev, ok := ParseMouse(key); ok && s.HandleMouse(ev) {
+				message = ""
+			}
+		case s.HandleKey(keyType, key):
+			message = ""
+		}
+	}
+}
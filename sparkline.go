@@ -0,0 +1,120 @@
+package ansi
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strings"
+)
+
+// --------------------
+// Sparkline
+// --------------------
+
+// sparkBlocks are the glyphs of a Sparkline, from lowest to highest.
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkConfig holds the settings of a Sparkline.
+type sparkConfig struct {
+	labels     bool
+	min, max   *float64
+	thresholds []sparkThreshold
+}
+
+// sparkThreshold colors the values from at upwards.
+type sparkThreshold struct {
+	at    float64
+	style string
+}
+
+// SparklineOption configures a Sparkline.
+type SparklineOption func(*sparkConfig)
+
+// SparkLabels shows the lowest and highest value, in Faint, on either side,
+// formatted with FormatCount.
+func SparkLabels() SparklineOption {
+	return func(c *sparkConfig) {
+		c.labels = true
+	}
+}
+
+// SparkRange sets the values drawn as the lowest and highest glyph instead
+// of the lowest and highest of the values, for comparing sparklines.
+func SparkRange(min, max float64) SparklineOption {
+	return func(c *sparkConfig) {
+		c.min, c.max = &min, &max
+	}
+}
+
+// SparkThreshold colors the values from at upwards with style, up to the
+// next threshold, e.g. SparkThreshold(80, Red).
+func SparkThreshold(at float64, style string) SparklineOption {
+	return func(c *sparkConfig) {
+		c.thresholds = append(c.thresholds, sparkThreshold{at, style})
+	}
+}
+
+// Sparkline returns the last width values as a line of block glyphs from
+// ▁ to █, scaled between the lowest and highest of them, for showing a
+// metric inline. With fewer values it is padded on the left to width
+// columns; a width of 0 or less draws every value. NaN and infinite values are blank.
+func Sparkline(values []float64, width int, opts ...SparklineOption) string {
+	cfg := &sparkConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	slices.SortStableFunc(cfg.thresholds, func(a, b sparkThreshold) int {
+		return cmp.Compare(a.at, b.at)
+	})
+	if width <= 0 {
+		width = len(values)
+	}
+	if len(values) > width {
+		values = values[len(values)-width:]
+	}
+
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		if !math.IsNaN(v) && !math.IsInf(v, 0) {
+			lo, hi = min(lo, v), max(hi, v)
+		}
+	}
+	if cfg.min != nil {
+		lo, hi = *cfg.min, *cfg.max
+	}
+
+	var sb strings.Builder
+	sb.WriteString(strings.Repeat(" ", width-len(values)))
+	style := ""
+	for _, v := range values {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			sb.WriteByte(' ')
+			continue
+		}
+		level := 0
+		if hi > lo {
+			level = int((min(max(v, lo), hi) - lo) / (hi - lo) * float64(len(sparkBlocks)-1))
+		}
+		next := ""
+		for _, t := range cfg.thresholds {
+			if v >= t.at {
+				next = t.style
+			}
+		}
+		if next != style {
+			if style != "" {
+				sb.WriteString(End)
+			}
+			sb.WriteString(next)
+			style = next
+		}
+		sb.WriteRune(sparkBlocks[level])
+	}
+	if style != "" {
+		sb.WriteString(End)
+	}
+	if !cfg.labels || math.IsInf(lo, 1) {
+		return sb.String()
+	}
+	return Faint + FormatCount(lo) + End + " " + sb.String() + " " + Faint + FormatCount(hi) + End
+}
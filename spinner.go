@@ -0,0 +1,288 @@
+package ansi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// --------------------
+// Spinner
+// --------------------
+
+// Frame sets for a Spinner.
+var (
+	SpinnerBraille = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+	SpinnerDots    = []string{"⣾", "⣽", "⣻", "⢿", "⡿", "⣟", "⣯", "⣷"}
+	SpinnerLine    = []string{"-", "\\", "|", "/"}
+	SpinnerClock   = []string{"🕛", "🕐", "🕑", "🕒", "🕓", "🕔", "🕕", "🕖", "🕗", "🕘", "🕙", "🕚"}
+)
+
+// Spinner animates a frame followed by a message on the current line while
+// work is in progress. It is safe for use from several goroutines.
+type Spinner struct {
+	frames   []string
+	interval time.Duration
+	style    string
+	out      io.Writer
+
+	mu      sync.Mutex
+	message string
+	shown   string // Message of the last plain line.
+	plain   *bool
+	frame   int
+	start   time.Time
+	stop    chan struct{}
+	stopped chan struct{}
+	ctx     context.Context
+	unwatch func() bool // Stops watching ctx.
+
+	// Spinners of a SpinnerGroup are drawn by the group, under its lock.
+	group   *SpinnerGroup
+	running bool
+	final   string // Line left by Success, Fail, Warn or Stop.
+}
+
+// SpinnerOption configures a Spinner.
+type SpinnerOption func(*Spinner)
+
+// SpinnerFrames sets the frames of the animation, e.g. SpinnerLine. The
+// default is SpinnerBraille.
+func SpinnerFrames(frames []string) SpinnerOption {
+	return func(s *Spinner) {
+		if len(frames) > 0 {
+			s.frames = frames
+		}
+	}
+}
+
+// SpinnerInterval sets the time between frames. The default is 100ms.
+func SpinnerInterval(d time.Duration) SpinnerOption {
+	return func(s *Spinner) {
+		if d > 0 {
+			s.interval = d
+		}
+	}
+}
+
+// SpinnerStyle sets the color or style of the frame. The default is Cyan.
+func SpinnerStyle(style string) SpinnerOption {
+	return func(s *Spinner) {
+		s.style = style
+	}
+}
+
+// SpinnerWriter sets where the spinner is drawn. It defaults to os.Stdout.
+func SpinnerWriter(w io.Writer) SpinnerOption {
+	return func(s *Spinner) {
+		s.out = w
+	}
+}
+
+// SpinnerContext ties the spinner to ctx: when ctx is canceled while the
+// spinner runs, it stops and leaves a red ✖ line marking the step aborted.
+func SpinnerContext(ctx context.Context) SpinnerOption {
+	return func(s *Spinner) {
+		s.ctx = ctx
+	}
+}
+
+// SpinnerPlain forces plain-text output on or off. Plain output prints the
+// message on a line of its own whenever it changes, without animation or
+// escape sequences. By default it is used when the output is not a terminal.
+func SpinnerPlain(on bool) SpinnerOption {
+	return func(s *Spinner) {
+		s.plain = &on
+	}
+}
+
+// NewSpinner creates a spinner showing message. Call Start to animate it.
+func NewSpinner(message string, opts ...SpinnerOption) *Spinner {
+	s := &Spinner{
+		frames:   SpinnerBraille,
+		interval: 100 * time.Millisecond,
+		style:    Cyan,
+		out:      os.Stdout,
+		message:  message,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.plain == nil {
+		plain := !isTerminal(s.out)
+		s.plain = &plain
+	}
+	return s
+}
+
+// Start starts the animation in the background. Starting a running spinner
+// does nothing.
+func (s *Spinner) Start() {
+	if s.group != nil {
+		s.group.start(s)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil {
+		return
+	}
+	s.stop = make(chan struct{})
+	s.stopped = make(chan struct{})
+	s.start = time.Now()
+	s.frame = 0
+	if !s.isPlain() {
+		fmt.Fprint(s.out, "\033[?25l")
+	}
+	s.draw()
+	go s.run(s.stop, s.stopped)
+	if s.ctx != nil {
+		s.unwatch = context.AfterFunc(s.ctx, s.abort)
+	}
+}
+
+// Stop stops the animation and clears its line. Stopping a spinner that is
+// not running does nothing.
+func (s *Spinner) Stop() {
+	if s.group != nil {
+		s.group.settle(s, "", "")
+		return
+	}
+	s.mu.Lock()
+	if s.stop == nil {
+		s.mu.Unlock()
+		return
+	}
+	close(s.stop)
+	stopped := s.stopped
+	s.stop, s.stopped = nil, nil
+	if s.unwatch != nil {
+		s.unwatch()
+		s.unwatch = nil
+	}
+	s.mu.Unlock()
+	<-stopped
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.isPlain() {
+		fmt.Fprint(s.out, "\r\033[2K\033[?25h")
+	}
+}
+
+// Success stops the spinner and leaves a green ✔ line with message and the
+// time the spinner ran. An empty message keeps the current one.
+func (s *Spinner) Success(message string) {
+	s.finish(Green+"✔"+End, message)
+}
+
+// Fail stops the spinner and leaves a red ✖ line with message and the time
+// the spinner ran. An empty message keeps the current one.
+func (s *Spinner) Fail(message string) {
+	s.finish(Red+"✖"+End, message)
+}
+
+// Warn stops the spinner and leaves a yellow ⚠ line with message and the
+// time the spinner ran. An empty message keeps the current one.
+func (s *Spinner) Warn(message string) {
+	s.finish(Yellow+"⚠"+End, message)
+}
+
+// abort stops the spinner and marks its message as aborted.
+func (s *Spinner) abort() {
+	s.mu.Lock()
+	message := s.message + " " + Red + "aborted" + End
+	s.mu.Unlock()
+	s.finish(Red+"✖"+End, message)
+}
+
+// finish stops the spinner and replaces it with symbol and message.
+func (s *Spinner) finish(symbol, message string) {
+	if s.group != nil {
+		s.group.settle(s, symbol, message)
+		return
+	}
+	s.mu.Lock()
+	start := s.start
+	s.mu.Unlock()
+	s.Stop()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if message == "" {
+		message = s.message
+	}
+	line := symbol + " " + message
+	if !start.IsZero() {
+		line += fmt.Sprintf(" %s(%s)%s", Faint, time.Since(start).Round(100*time.Millisecond), End)
+	}
+	if s.isPlain() {
+		fmt.Fprintln(s.out, stripANSI(line))
+		return
+	}
+	fmt.Fprintln(s.out, "\r\033[2K"+line)
+}
+
+// SetMessage changes the message shown after the frame.
+func (s *Spinner) SetMessage(message string) {
+	if s.group != nil {
+		s.group.setMessage(s, message)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.message = message
+	if s.stop != nil {
+		s.draw()
+	}
+}
+
+// Active reports whether the spinner is running.
+func (s *Spinner) Active() bool {
+	if s.group != nil {
+		s.group.mu.Lock()
+		defer s.group.mu.Unlock()
+		return s.running
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stop != nil
+}
+
+// run advances the animation until stop is closed.
+func (s *Spinner) run(stop, stopped chan struct{}) {
+	defer close(stopped)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			s.frame = (s.frame + 1) % len(s.frames)
+			s.draw()
+			s.mu.Unlock()
+		}
+	}
+}
+
+// draw redraws the current frame and message.
+func (s *Spinner) draw() {
+	if s.isPlain() {
+		if s.message != s.shown {
+			fmt.Fprintln(s.out, s.message)
+			s.shown = s.message
+		}
+		return
+	}
+	fmt.Fprint(s.out, "\r\033[2K"+s.style+s.frames[s.frame]+End+" "+s.message)
+}
+
+// isPlain reports whether the spinner prints plain lines.
+func (s *Spinner) isPlain() bool {
+	return s.plain != nil && *s.plain
+}
@@ -0,0 +1,186 @@
+package ansi
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// --------------------
+// SpinnerGroup
+// --------------------
+
+// SpinnerGroup draws several spinners on consecutive lines with a single
+// render loop, e.g. one per parallel build or upload. Each spinner is added
+// with Add and used like a standalone one. It is safe for use from several
+// goroutines.
+type SpinnerGroup struct {
+	mu       sync.Mutex
+	config   *Spinner // Frames, interval, style, writer and plain mode.
+	spinners []*Spinner
+	frame    int
+	lines    int           // Lines drawn by the last draw.
+	stop     chan struct{} // Closed to stop the render loop; nil when it is not running.
+	settled  *sync.Cond    // Broadcast on mu when a spinner stops.
+}
+
+// NewSpinnerGroup creates an empty group. SpinnerFrames, SpinnerInterval,
+// SpinnerStyle, SpinnerWriter and SpinnerPlain in opts apply to all its
+// spinners.
+func NewSpinnerGroup(opts ...SpinnerOption) *SpinnerGroup {
+	g := &SpinnerGroup{config: NewSpinner("", opts...)}
+	g.settled = sync.NewCond(&g.mu)
+	return g
+}
+
+// Add adds a spinner showing message on a new line below the others. It
+// shows its message without animation until it is started.
+func (g *SpinnerGroup) Add(message string) *Spinner {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	s := &Spinner{group: g, message: message}
+	g.spinners = append(g.spinners, s)
+	g.draw()
+	return s
+}
+
+// Wait waits for every started spinner to stop, then stops the render loop
+// and moves the cursor below the group.
+func (g *SpinnerGroup) Wait() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for g.running() {
+		g.settled.Wait()
+	}
+	if g.stop != nil {
+		close(g.stop)
+		g.stop = nil
+	}
+	g.draw()
+	if !g.config.isPlain() {
+		fmt.Fprint(g.config.out, "\033[?25h")
+	}
+}
+
+// start starts the animation of s.
+func (g *SpinnerGroup) start(s *Spinner) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if s.running {
+		return
+	}
+	s.running, s.final, s.start = true, "", time.Now()
+	if g.config.isPlain() {
+		fmt.Fprintln(g.config.out, s.message)
+		return
+	}
+	if g.stop == nil {
+		g.stop = make(chan struct{})
+		fmt.Fprint(g.config.out, "\033[?25l")
+		go g.run(g.stop)
+	}
+	g.draw()
+}
+
+// settle stops the animation of s and leaves symbol and message in its
+// place, or just its message if symbol is empty, as after Stop.
+func (g *SpinnerGroup) settle(s *Spinner, symbol, message string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if message == "" {
+		message = s.message
+	}
+	line := message
+	if symbol != "" {
+		line = symbol + " " + message
+		if !s.start.IsZero() {
+			line += fmt.Sprintf(" %s(%s)%s", Faint, time.Since(s.start).Round(100*time.Millisecond), End)
+		}
+		if g.config.isPlain() {
+			fmt.Fprintln(g.config.out, stripANSI(line))
+		}
+	}
+	s.running, s.final = false, line
+	g.draw()
+	g.settled.Broadcast()
+}
+
+// setMessage changes the message of s.
+func (g *SpinnerGroup) setMessage(s *Spinner, message string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	s.message = message
+	if g.config.isPlain() {
+		if s.running {
+			fmt.Fprintln(g.config.out, message)
+		}
+		return
+	}
+	g.draw()
+}
+
+// run advances the animation until stop is closed or no spinner runs.
+func (g *SpinnerGroup) run(stop chan struct{}) {
+	ticker := time.NewTicker(g.config.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		g.mu.Lock()
+		if g.stop != stop {
+			g.mu.Unlock()
+			return
+		}
+		g.frame = (g.frame + 1) % len(g.config.frames)
+		g.draw()
+		if !g.running() {
+			g.stop = nil
+			fmt.Fprint(g.config.out, "\033[?25h")
+			g.mu.Unlock()
+			return
+		}
+		g.mu.Unlock()
+	}
+}
+
+// running reports whether any spinner of the group runs.
+func (g *SpinnerGroup) running() bool {
+	for _, s := range g.spinners {
+		if s.running {
+			return true
+		}
+	}
+	return false
+}
+
+// draw redraws every spinner of the group in place.
+func (g *SpinnerGroup) draw() {
+	cfg := g.config
+	if cfg.isPlain() {
+		return
+	}
+	indent := strings.Repeat(" ", visibleWidth(cfg.frames[0])+1)
+	width, _ := termSize(cfg.out)
+	var sb strings.Builder
+	if g.lines > 0 {
+		sb.WriteString(fmt.Sprintf("\033[%dF", g.lines))
+	}
+	for _, s := range g.spinners {
+		var line string
+		switch {
+		case s.running:
+			line = cfg.style + cfg.frames[g.frame] + End + " " + s.message
+		case s.final != "":
+			line = s.final
+		default:
+			line = indent + s.message
+		}
+		sb.WriteString("\033[2K" + truncateWidth(line, width) + "\n")
+	}
+	g.lines = len(g.spinners)
+	fmt.Fprint(cfg.out, sb.String())
+}
@@ -0,0 +1,35 @@
+package ansi
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSpinnerGroupWait(t *testing.T) {
+	var out syncBuffer
+	g := NewSpinnerGroup(SpinnerWriter(&out), SpinnerPlain(false), SpinnerInterval(10*time.Millisecond))
+	build, upload := g.Add("build"), g.Add("upload")
+	build.Start()
+	upload.Start()
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		build.Success("built")
+		time.Sleep(50 * time.Millisecond)
+		upload.Fail("upload failed")
+	}()
+	g.Wait()
+
+	g.mu.Lock()
+	running, stop := g.running(), g.stop
+	g.mu.Unlock()
+	if running || stop != nil {
+		t.Errorf("after Wait: running = %v, render loop stopped = %v", running, stop == nil)
+	}
+	got := out.String()
+	for _, want := range []string{"built", "upload failed"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output lacks %q", want)
+		}
+	}
+}
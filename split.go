@@ -0,0 +1,354 @@
+package ansi
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// --------------------
+// Split Panes
+// --------------------
+
+// Rect is a rectangle of the screen.
+type Rect struct {
+	Row, Col      int // Top left corner, counted from 1.
+	Width, Height int
+}
+
+// PaneSize is how much of a Split a Pane takes. The zero value is SizeFlex(1).
+type PaneSize struct {
+	fixed   int
+	percent float64
+	flex    int
+}
+
+// SizeFixed sizes a pane to n columns or rows.
+func SizeFixed(n int) PaneSize {
+	return PaneSize{fixed: max(n, 1)}
+}
+
+// SizePercent sizes a pane to a percentage of its split, less the dividers.
+func SizePercent(p float64) PaneSize {
+	return PaneSize{percent: p}
+}
+
+// SizeFlex makes a pane share what fixed and percentage panes leave, in
+// proportion to weight.
+func SizeFlex(weight int) PaneSize {
+	return PaneSize{flex: max(weight, 1)}
+}
+
+// SplitDir is the direction in which a Split lays out its panes.
+type SplitDir int
+
+// Split directions.
+const (
+	SplitColumns SplitDir = iota // Panes side by side, divided by vertical lines.
+	SplitRows                    // Panes one above the other, divided by horizontal lines.
+)
+
+// Pane is a part of a Split: either a leaf drawn by Render or a nested Split.
+type Pane struct {
+	Size   PaneSize
+	Render func(width, height int) []string // Lines of a leaf pane.
+	Split  *Split                           // Panes of a nested split.
+
+	rect Rect
+}
+
+// Rect returns where the pane was placed by the last layout.
+func (p *Pane) Rect() Rect {
+	return p.rect
+}
+
+// Split divides a rectangle of the screen between panes, with a line
+// between each pair that can be dragged with the mouse.
+type Split struct {
+	Dir   SplitDir
+	Panes []*Pane
+	Style string // Style of the dividers. The default is Faint.
+
+	rect Rect
+	drag int // One more than the divider being dragged, or 0 if none is.
+}
+
+// NewSplit creates a split of panes.
+func NewSplit(dir SplitDir, panes ...*Pane) *Split {
+	return &Split{Dir: dir, Panes: panes, Style: Faint}
+}
+
+// length returns the size of r along the split's direction.
+func (s *Split) length(r Rect) int {
+	if s.Dir == SplitColumns {
+		return r.Width
+	}
+	return r.Height
+}
+
+// sizes returns the sizes of the panes.
+func (s *Split) sizes() []PaneSize {
+	sizes := make([]PaneSize, len(s.Panes))
+	for i, p := range s.Panes {
+		sizes[i] = p.Size
+	}
+	return sizes
+}
+
+// trackSizes divides avail cells between sizes: fixed sizes first, then
+// percentages of avail, then what is left between flexible sizes by weight.
+func trackSizes(sizes []PaneSize, avail int) []int {
+	result := make([]int, len(sizes))
+	left, weights := avail, 0
+	for i, size := range sizes {
+		switch {
+		case size.fixed > 0:
+			result[i] = size.fixed
+		case size.percent > 0:
+			result[i] = int(float64(avail) * size.percent / 100)
+		default:
+			weights += max(size.flex, 1)
+			continue
+		}
+		result[i] = min(result[i], left)
+		left -= result[i]
+	}
+	last := -1
+	for i, size := range sizes {
+		if size.fixed > 0 || size.percent > 0 {
+			continue
+		}
+		result[i] = left * max(size.flex, 1) / weights
+		last = i
+	}
+	if last >= 0 {
+		// Give the rounding left over to the last flexible size.
+		used := 0
+		for _, n := range result {
+			used += n
+		}
+		result[last] += avail - used
+	}
+	return result
+}
+
+// Layout places the panes in r, nested splits included.
+func (s *Split) Layout(r Rect) {
+	s.rect = r
+	n := len(s.Panes)
+	if n == 0 {
+		return
+	}
+	sizes := trackSizes(s.sizes(), max(s.length(r)-(n-1), 0))
+
+	pos := 0
+	for i, p := range s.Panes {
+		if s.Dir == SplitColumns {
+			p.rect = Rect{r.Row, r.Col + pos, sizes[i], r.Height}
+		} else {
+			p.rect = Rect{r.Row + pos, r.Col, r.Width, sizes[i]}
+		}
+		if p.Split != nil {
+			p.Split.Layout(p.rect)
+		}
+		pos += sizes[i] + 1
+	}
+}
+
+// Render returns the escape sequences drawing every pane and divider.
+func (s *Split) Render() string {
+	var sb strings.Builder
+	for i, p := range s.Panes {
+		r := p.rect
+		switch {
+		case p.Split != nil:
+			sb.WriteString(p.Split.Render())
+		default:
+			sb.WriteString(renderRect(r, p.Render))
+		}
+		if i == len(s.Panes)-1 {
+			break
+		}
+		style := s.Style
+		if style == "" {
+			style = Faint
+		}
+		if i == s.drag-1 {
+			style = Cyan
+		}
+		if s.Dir == SplitColumns {
+			for row := 0; row < r.Height; row++ {
+				sb.WriteString(fmt.Sprintf("\033[%d;%dH%s│%s", r.Row+row, r.Col+r.Width, style, End))
+			}
+		} else {
+			sb.WriteString(fmt.Sprintf("\033[%d;%dH%s%s%s", r.Row+r.Height, r.Col, style, strings.Repeat("─", r.Width), End))
+		}
+	}
+	return sb.String()
+}
+
+// renderRect returns the escape sequences drawing the lines returned by
+// render in r, cut and padded to fill it.
+func renderRect(r Rect, render func(width, height int) []string) string {
+	var lines []string
+	if render != nil {
+		lines = render(r.Width, r.Height)
+	}
+	var sb strings.Builder
+	for row := 0; row < r.Height; row++ {
+		line := ""
+		if row < len(lines) {
+			line = truncateWidth(lines[row], r.Width)
+		}
+		line += strings.Repeat(" ", max(r.Width-visibleWidth(line), 0))
+		sb.WriteString(fmt.Sprintf("\033[%d;%dH%s", r.Row+row, r.Col, line))
+	}
+	return sb.String()
+}
+
+// HandleMouse drags the dividers of the split and nested splits with the
+// left button, and reports whether the event was used. The panes on either
+// side of a dragged divider keep their kind of size: fixed panes get a new
+// fixed size and percentage panes a new percentage.
+func (s *Split) HandleMouse(ev MouseEvent) bool {
+	if s.drag > len(s.Panes)-1 {
+		// The panes changed since the drag started.
+		s.drag = 0
+	}
+	if i := s.drag - 1; i >= 0 {
+		if ev.Release {
+			s.drag = 0
+			return true
+		}
+		if ev.Motion {
+			a := s.Panes[i]
+			pos := ev.Col - a.rect.Col
+			if s.Dir == SplitRows {
+				pos = ev.Row - a.rect.Row
+			}
+			s.resize(i, pos)
+			s.Layout(s.rect)
+			return true
+		}
+	}
+	if ev.Button == MouseLeft && !ev.Release && !ev.Motion {
+		for i, p := range s.Panes[:max(len(s.Panes)-1, 0)] {
+			r := p.rect
+			onColumn := s.Dir == SplitColumns && ev.Col == r.Col+r.Width && ev.Row >= r.Row && ev.Row < r.Row+r.Height
+			onRow := s.Dir == SplitRows && ev.Row == r.Row+r.Height && ev.Col >= r.Col && ev.Col < r.Col+r.Width
+			if onColumn || onRow {
+				s.drag = i + 1
+				return true
+			}
+		}
+	}
+	for _, p := range s.Panes {
+		if p.Split != nil && p.Split.HandleMouse(ev) {
+			return true
+		}
+	}
+	return false
+}
+
+// resize moves divider i so the pane before it is size long.
+func (s *Split) resize(i, size int) {
+	a, b := s.Panes[i], s.Panes[i+1]
+	total := s.length(a.rect) + s.length(b.rect)
+	if total < 2 {
+		return
+	}
+	size = max(min(size, total-1), 1)
+	avail := max(s.length(s.rect)-(len(s.Panes)-1), 1)
+	set := func(p *Pane, n int) {
+		switch {
+		case p.Size.fixed > 0:
+			p.Size = SizeFixed(n)
+		case p.Size.percent > 0:
+			p.Size = SizePercent(float64(n) * 100 / float64(avail))
+		}
+	}
+	aFlex := a.Size.fixed == 0 && a.Size.percent == 0
+	bFlex := b.Size.fixed == 0 && b.Size.percent == 0
+	if aFlex && bFlex {
+		a.Size = SizeFixed(size)
+	}
+	set(a, size)
+	set(b, total-size)
+}
+
+// Run shows the split filling the alternate screen until q, Escape or
+// Ctrl-C is pressed, laying it out again when the terminal is resized.
+// Dividers can be dragged with the mouse. InputWriter and InputKeyReader may
+// be given in opts.
+func (s *Split) Run(opts ...InputOption) error {
+	return runScreen(opts, true, func(r Rect) string {
+		s.Layout(r)
+		return s.Render()
+	}, func(keyType, key string) {
+		if keyType == "Mouse" {
+			if ev, ok := ParseMouse(key); ok {
+				s.HandleMouse(ev)
+			}
+		}
+	})
+}
+
+// runScreen runs a layout in the alternate screen until q, Escape or Ctrl-C
+// is pressed. render lays out and draws the layout in the whole screen, again
+// whenever the terminal is resized, and handle is given the other keys, and
+// mouse events when mouse is set. render and handle are never called at the
+// same time.
+func runScreen(opts []InputOption, mouse bool, render func(Rect) string, handle func(keyType, key string)) error {
+	cfg := &inputConfig{out: os.Stdout}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.keys == nil {
+		cfg.keys = defaultKeyReader()
+	}
+	var mu sync.Mutex
+	draw := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		width, height := termSize(cfg.out)
+		fmt.Fprint(cfg.out, render(Rect{1, 1, width, height}))
+	}
+
+	fmt.Fprint(cfg.out, "\033[?1049h\033[?25l\033[2J")
+	defer fmt.Fprint(cfg.out, "\033[?25h\033[?1049l")
+	if mouse {
+		EnableMouse(cfg.out)
+		defer DisableMouse(cfg.out)
+	}
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		resized := make(chan os.Signal, 1)
+		notifyResize(resized)
+		defer stopResize(resized)
+		for {
+			select {
+			case <-stop:
+				return
+			case <-resized:
+				fmt.Fprint(cfg.out, "\033[2J")
+				draw()
+			}
+		}
+	}()
+
+	for {
+		draw()
+		keyType, key, err := cfg.keys.readKey()
+		if err != nil {
+			return err
+		}
+		if keyType == "Character" && key == "q" || keyType == "Special" && (key == "escape" || key == "ctrl-c") {
+			return nil
+		}
+		mu.Lock()
+		handle(keyType, key)
+		mu.Unlock()
+	}
+}
@@ -0,0 +1,33 @@
+package ansi
+
+import "testing"
+
+func TestSplitDrag(t *testing.T) {
+	screen := Rect{Row: 1, Col: 1, Width: 21, Height: 5}
+
+	// A split made without NewSplit starts out not dragging.
+	one := &Split{Panes: []*Pane{{}}}
+	one.Layout(screen)
+	if one.HandleMouse(MouseEvent{Button: MouseLeft, Row: 2, Col: 5, Motion: true}) {
+		t.Error("one pane: motion without a drag was used")
+	}
+
+	left, right := &Pane{}, &Pane{}
+	s := &Split{Panes: []*Pane{left, right}}
+	s.Layout(screen)
+	if s.HandleMouse(MouseEvent{Button: MouseLeft, Row: 2, Col: 5, Motion: true}) {
+		t.Error("motion without a drag was used")
+	}
+	divider := left.Rect().Col + left.Rect().Width
+	if !s.HandleMouse(MouseEvent{Button: MouseLeft, Row: 2, Col: divider}) {
+		t.Fatal("press on the divider was not used")
+	}
+	s.HandleMouse(MouseEvent{Button: MouseLeft, Row: 2, Col: 6, Motion: true})
+	s.HandleMouse(MouseEvent{Button: MouseLeft, Row: 2, Col: 6, Release: true})
+	if got := left.Rect().Width; got != 5 {
+		t.Errorf("dragged to column 6: left pane is %d wide, want 5", got)
+	}
+	if s.HandleMouse(MouseEvent{Button: MouseLeft, Row: 2, Col: 12, Motion: true}) {
+		t.Error("motion after the release was used")
+	}
+}
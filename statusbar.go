@@ -0,0 +1,183 @@
+package ansi
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// --------------------
+// Status Bar
+// --------------------
+
+// StatusTheme is the set of styles a StatusBar is drawn with. Bar is the
+// style of the whole line; the styles of the sides are added to it.
+type StatusTheme struct {
+	Bar, Left, Center, Right string
+}
+
+// Themes for a StatusBar.
+var (
+	StatusThemeNegative = StatusTheme{Bar: Negative, Left: Bold}
+	StatusThemeBlue     = StatusTheme{Bar: "\033[44;97m", Left: Bold, Right: Bold}
+	StatusThemeDark     = StatusTheme{Bar: "\033[100;97m", Left: Bold, Right: Faint}
+	StatusThemePlain    = StatusTheme{Left: Bold, Right: Faint}
+)
+
+// StatusSegment is a piece of text in a StatusBar. When the bar is too
+// narrow the segments of lowest Priority are dropped first, and the last one
+// left is truncated.
+type StatusSegment struct {
+	Text     string
+	Priority int
+	Style    string // Added to the style of its side.
+}
+
+// StatusBar is a one-line bar with segments on the left, center and right,
+// for a header or status line. It can be pinned to the top or bottom row of
+// the screen while the rest scrolls.
+type StatusBar struct {
+	Left, Center, Right []StatusSegment
+	Theme               StatusTheme
+	Separator           string // Between the segments of a side. The default is " │ ".
+
+	pinned bool
+	top    bool
+}
+
+// NewStatusBar creates a bar with the StatusThemeNegative theme.
+func NewStatusBar() *StatusBar {
+	return &StatusBar{Theme: StatusThemeNegative, Separator: " │ "}
+}
+
+// statusSide is a side of a StatusBar being laid out.
+type statusSide struct {
+	segments []StatusSegment
+	style    string
+}
+
+// sideWidth returns the width of the side's segments and separators.
+func (b *StatusBar) sideWidth(side statusSide) int {
+	width := 0
+	for i, seg := range side.segments {
+		if i > 0 {
+			width += visibleWidth(b.Separator)
+		}
+		width += visibleWidth(seg.Text)
+	}
+	return width
+}
+
+// renderSide returns the segments of side, styled, with the bar style restored
+// after each.
+func (b *StatusBar) renderSide(side statusSide) string {
+	var sb strings.Builder
+	for i, seg := range side.segments {
+		if i > 0 {
+			sb.WriteString(b.Separator)
+		}
+		sb.WriteString(side.style + seg.Style + seg.Text + End + b.Theme.Bar)
+	}
+	return sb.String()
+}
+
+// Line returns the bar as a line of width columns.
+func (b *StatusBar) Line(width int) string {
+	sides := []*statusSide{
+		{slices.Clone(b.Left), b.Theme.Left},
+		{slices.Clone(b.Center), b.Theme.Center},
+		{slices.Clone(b.Right), b.Theme.Right},
+	}
+	inner := max(width-2, 0)
+	fits := func() bool {
+		used, parts := 0, 0
+		for _, side := range sides {
+			if len(side.segments) > 0 {
+				used += b.sideWidth(*side)
+				parts++
+			}
+		}
+		return used+max(parts-1, 0) <= inner
+	}
+	for !fits() {
+		// Drop the segment of lowest priority, the rightmost of equals, unless
+		// it is the last one left.
+		drop, at, count := (*statusSide)(nil), 0, 0
+		for _, side := range sides {
+			for i, seg := range side.segments {
+				count++
+				if drop == nil || seg.Priority <= drop.segments[at].Priority {
+					drop, at = side, i
+				}
+			}
+		}
+		if count <= 1 {
+			if drop != nil {
+				drop.segments[at].Text = truncateWidth(drop.segments[at].Text, inner)
+			}
+			break
+		}
+		drop.segments = append(drop.segments[:at], drop.segments[at+1:]...)
+	}
+
+	left, center, right := *sides[0], *sides[1], *sides[2]
+	leftWidth, centerWidth, rightWidth := b.sideWidth(left), b.sideWidth(center), b.sideWidth(right)
+	line := " " + b.renderSide(left)
+	if len(center.segments) > 0 {
+		// Center the middle side, pushing it aside if it would touch the others.
+		lo := leftWidth
+		if leftWidth > 0 {
+			lo++
+		}
+		hi := inner - rightWidth - centerWidth
+		if rightWidth > 0 {
+			hi--
+		}
+		pos := max(min((inner-centerWidth)/2, hi), lo)
+		line += strings.Repeat(" ", max(pos-leftWidth, 0)) + b.renderSide(center)
+		leftWidth = pos + centerWidth
+	}
+	line += strings.Repeat(" ", max(inner-leftWidth-rightWidth, 0)) + b.renderSide(right) + " "
+	return b.Theme.Bar + truncateWidth(line, width) + End
+}
+
+// Pin keeps the bar on the top row of the terminal w writes to if top is
+// set, or else on the bottom row, by leaving that row out of the scrolling
+// region. Text written afterwards scrolls in the other rows.
+func (b *StatusBar) Pin(w io.Writer, top bool) {
+	b.pinned, b.top = true, top
+	if !top {
+		// Make room for the bar if the cursor is on the bottom row.
+		fmt.Fprint(w, "\n\033[A")
+	}
+	b.Update(w)
+}
+
+// Update redraws a pinned bar after its segments changed or the terminal was
+// resized, leaving the cursor where it was.
+func (b *StatusBar) Update(w io.Writer) {
+	if !b.pinned {
+		return
+	}
+	width, height := termSize(w)
+	row, region := height, fmt.Sprintf("\033[1;%dr", height-1)
+	if b.top {
+		row, region = 1, fmt.Sprintf("\033[2;%dr", height)
+	}
+	fmt.Fprintf(w, "\0337%s\033[%d;1H\033[2K%s\0338", region, row, b.Line(width))
+}
+
+// Unpin gives the bar's row back to the scrolling region and clears it.
+func (b *StatusBar) Unpin(w io.Writer) {
+	if !b.pinned {
+		return
+	}
+	b.pinned = false
+	_, height := termSize(w)
+	row := height
+	if b.top {
+		row = 1
+	}
+	fmt.Fprintf(w, "\0337\033[r\033[%d;1H\033[2K\0338", row)
+}
@@ -0,0 +1,158 @@
+package ansi
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// --------------------
+// Stepper
+// --------------------
+
+// StepperGlyphs are the marks a Stepper draws before the names of its steps
+// and between them.
+type StepperGlyphs struct {
+	Done      string   // Marks a completed step.
+	Numbers   []string // Mark the other steps, in order.
+	Number    string   // Format of the number of steps beyond Numbers, as for fmt.Sprintf.
+	Connector string   // Between two steps.
+}
+
+// Glyphs for a Stepper.
+var (
+	StepperCircled = StepperGlyphs{
+		Done:      "✔",
+		Numbers:   strings.Split("①②③④⑤⑥⑦⑧⑨⑩⑪⑫⑬⑭⑮⑯⑰⑱⑲⑳", ""),
+		Number:    "(%d)",
+		Connector: " ─ ",
+	}
+	StepperASCII = StepperGlyphs{Done: "[x]", Number: "[%d]", Connector: " - "}
+)
+
+// Stepper shows the progress through the steps of a process, like
+// "① Configure ─ ② Review ─ ③ Apply", with the completed steps, the current
+// one and those still pending each in their own style.
+type Stepper struct {
+	Steps        []string
+	Glyphs       StepperGlyphs // StepperCircled, or StepperASCII without UTF-8, if not set with NewStepper.
+	DoneStyle    string        // The default is Green.
+	CurrentStyle string        // The default is Cyan and Bold.
+	PendingStyle string        // The default is Faint.
+
+	current int // Index of the current step; len(Steps) once all are done.
+}
+
+// NewStepper creates a stepper of steps at the first one. It uses
+// StepperCircled glyphs, or StepperASCII if the locale is not UTF-8.
+func NewStepper(steps ...string) *Stepper {
+	s := &Stepper{Steps: steps, Glyphs: StepperCircled, DoneStyle: Green, CurrentStyle: Cyan + Bold, PendingStyle: Faint}
+	if !unicodeLocale() {
+		s.Glyphs = StepperASCII
+	}
+	return s
+}
+
+// Current returns the index of the current step, or the number of steps once
+// all are done.
+func (s *Stepper) Current() int {
+	return s.current
+}
+
+// SetCurrent makes step i current, marking those before it done. Setting the
+// number of steps marks all of them done.
+func (s *Stepper) SetCurrent(i int) {
+	s.current = max(min(i, len(s.Steps)), 0)
+}
+
+// Next marks the current step done and moves to the next one. It reports
+// whether there was a step left to complete.
+func (s *Stepper) Next() bool {
+	if s.current >= len(s.Steps) {
+		return false
+	}
+	s.current++
+	return true
+}
+
+// Prev goes back to the step before the current one, if there is one.
+func (s *Stepper) Prev() {
+	s.SetCurrent(s.current - 1)
+}
+
+// Done reports whether every step is done.
+func (s *Stepper) Done() bool {
+	return s.current >= len(s.Steps)
+}
+
+// mark returns the glyph and style of step i.
+func (s *Stepper) mark(i int) (string, string) {
+	switch {
+	case i < s.current:
+		return s.Glyphs.Done, s.DoneStyle
+	case i < len(s.Glyphs.Numbers):
+		return s.Glyphs.Numbers[i], s.style(i)
+	}
+	return fmt.Sprintf(s.Glyphs.Number, i+1), s.style(i)
+}
+
+// style returns the style of step i, which is not done.
+func (s *Stepper) style(i int) string {
+	if i == s.current {
+		return s.CurrentStyle
+	}
+	return s.PendingStyle
+}
+
+// Line returns the stepper fitted in width columns. When the names of all
+// the steps do not fit only the current one is named, and if that does not
+// fit either the line is truncated.
+func (s *Stepper) Line(width int) string {
+	if width <= 0 || len(s.Steps) == 0 {
+		return ""
+	}
+	line := func(named func(i int) bool) string {
+		var sb strings.Builder
+		for i, name := range s.Steps {
+			if i > 0 {
+				style := s.PendingStyle
+				if i <= s.current {
+					style = s.DoneStyle
+				}
+				sb.WriteString(style + s.Glyphs.Connector + End)
+			}
+			text, style := s.mark(i)
+			if named(i) {
+				text += " " + name
+			}
+			sb.WriteString(style + text + End)
+		}
+		return sb.String()
+	}
+	l := line(func(int) bool { return true })
+	if visibleWidth(l) > width {
+		l = line(func(i int) bool { return i == s.current })
+	}
+	return truncateWidth(l, width)
+}
+
+// Lines returns the stepper on a line of width columns, for drawing it in a
+// Pane, GridCell or Tab.
+func (s *Stepper) Lines(width, height int) []string {
+	if height <= 0 {
+		return nil
+	}
+	return []string{s.Line(width)}
+}
+
+// Print prints the stepper to stdout.
+func (s *Stepper) Print() {
+	fmt.Print(s.String())
+}
+
+// String renders the stepper in the width of the terminal, ending with a
+// newline.
+func (s *Stepper) String() string {
+	width, _ := termSize(os.Stdout)
+	return s.Line(width) + "\n"
+}
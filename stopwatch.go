@@ -0,0 +1,274 @@
+package ansi
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// --------------------
+// Stopwatch and Countdown
+// --------------------
+
+// clock is the time counting of a Stopwatch or Countdown: it can be paused
+// and reset, and is drawn in place while started.
+type clock struct {
+	Label    string
+	Style    string                     // Style of the time. The default is Cyan.
+	Format   func(time.Duration) string // Formats the time shown. The default is FormatClock.
+	Interval time.Duration              // Time between redraws. The default is 100ms.
+	Row, Col int                        // Screen position to draw at; 0 draws on the current line.
+
+	mu      sync.Mutex
+	elapsed time.Duration // Time counted before the current run.
+	since   time.Time     // Start of the current run; zero while paused.
+	limit   time.Duration // Time at which a countdown finishes.
+	counts  bool          // Whether the clock is a countdown, stopping at limit.
+	done    chan struct{} // Closed when the countdown finishes.
+	onDone  func()
+	shown   func(elapsed time.Duration) time.Duration
+	out     io.Writer
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// newClock returns a paused clock at zero showing shown(elapsed).
+func newClock(label string, shown func(time.Duration) time.Duration) clock {
+	return clock{Label: label, Style: Cyan, Format: FormatClock, Interval: 100 * time.Millisecond,
+		shown: shown, out: os.Stdout, done: make(chan struct{})}
+}
+
+// FormatClock formats d like a clock: minutes, seconds and tenths, as in
+// "04:05.6", with the hours in front from an hour, as in "1:04:05".
+func FormatClock(d time.Duration) string {
+	d = max(d, 0)
+	if d >= time.Hour {
+		d = d.Truncate(time.Second)
+		return fmt.Sprintf("%d:%02d:%02d", int(d/time.Hour), int(d%time.Hour/time.Minute), int(d%time.Minute/time.Second))
+	}
+	return fmt.Sprintf("%02d:%02d.%d", int(d/time.Minute), int(d%time.Minute/time.Second), int(d%time.Second/(100*time.Millisecond)))
+}
+
+// SetWriter sets where the clock is drawn. The default is os.Stdout.
+func (c *clock) SetWriter(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.out = w
+}
+
+// Elapsed returns the time counted so far.
+func (c *clock) Elapsed() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now()
+}
+
+// now returns the time counted so far, up to the limit.
+func (c *clock) now() time.Duration {
+	e := c.elapsed
+	if !c.since.IsZero() {
+		e += time.Since(c.since)
+	}
+	if c.counts {
+		e = min(e, c.limit)
+	}
+	return e
+}
+
+// Running reports whether the clock is counting.
+func (c *clock) Running() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return !c.since.IsZero()
+}
+
+// Start starts counting, or goes on after Pause, and draws the clock in
+// place until Stop. A finished countdown has to be Reset first.
+func (c *clock) Start() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.since.IsZero() && (!c.counts || c.elapsed < c.limit) {
+		c.since = time.Now()
+	}
+	if c.stop == nil {
+		c.stop = make(chan struct{})
+		c.stopped = make(chan struct{})
+		fmt.Fprint(c.out, "\033[?25l")
+		go c.run(c.out, c.stop, c.stopped)
+	}
+}
+
+// Pause stops counting, keeping the time counted so far.
+func (c *clock) Pause() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.elapsed = c.now()
+	c.since = time.Time{}
+}
+
+// Reset sets the time counted back to zero, going on counting if the clock
+// is running.
+func (c *clock) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.elapsed = 0
+	if !c.since.IsZero() {
+		c.since = time.Now()
+	}
+	if c.finished() {
+		c.done = make(chan struct{})
+	}
+}
+
+// Stop stops counting and drawing, leaving the last time drawn on its line.
+// Stopping a clock that is not drawn does nothing.
+func (c *clock) Stop() {
+	c.Pause()
+	c.mu.Lock()
+	if c.stop == nil {
+		c.mu.Unlock()
+		return
+	}
+	close(c.stop)
+	stopped := c.stopped
+	c.stop, c.stopped = nil, nil
+	c.mu.Unlock()
+	<-stopped
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.draw(c.out)
+	if c.Row <= 0 {
+		fmt.Fprint(c.out, "\n")
+	}
+	fmt.Fprint(c.out, "\033[?25h")
+}
+
+// run redraws the clock on out every Interval until stop is closed, and
+// finishes a countdown when its time is up.
+func (c *clock) run(out io.Writer, stop, stopped chan struct{}) {
+	defer close(stopped)
+	ticker := time.NewTicker(max(c.Interval, 10*time.Millisecond))
+	defer ticker.Stop()
+	for {
+		c.mu.Lock()
+		finished := c.counts && c.now() >= c.limit && !c.finished()
+		if finished {
+			c.elapsed, c.since = c.limit, time.Time{}
+			close(c.done)
+		}
+		c.draw(out)
+		c.mu.Unlock()
+		if finished && c.onDone != nil {
+			// In its own goroutine, so it may call Stop.
+			go c.onDone()
+		}
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// finished reports whether done is closed.
+func (c *clock) finished() bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// draw draws the clock at its position, or over the current line.
+func (c *clock) draw(out io.Writer) {
+	if c.Row > 0 {
+		fmt.Fprintf(out, "\0337\033[%d;%dH%s\033[K\0338", c.Row, max(c.Col, 1), c.line())
+	} else {
+		fmt.Fprint(out, "\r\033[2K"+c.line())
+	}
+}
+
+// line returns the label and the time shown.
+func (c *clock) line() string {
+	format := c.Format
+	if format == nil {
+		format = FormatClock
+	}
+	text := c.Style + format(c.shown(c.now())) + End
+	if c.Label != "" {
+		text = c.Label + " " + text
+	}
+	return text
+}
+
+// Lines returns the label and time on a line of width columns, for drawing
+// the clock in a Pane, GridCell or Tab instead of in place.
+func (c *clock) Lines(width, height int) []string {
+	if height <= 0 {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return []string{truncateWidth(c.line(), width)}
+}
+
+// String returns the label and time.
+func (c *clock) String() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.line()
+}
+
+// Stopwatch counts the time up from zero, shown after a label and redrawn
+// in place while started. It is safe for use from several goroutines.
+type Stopwatch struct {
+	clock
+}
+
+// NewStopwatch creates a paused stopwatch at zero. Call Start to run it.
+func NewStopwatch(label string) *Stopwatch {
+	return &Stopwatch{newClock(label, func(elapsed time.Duration) time.Duration { return elapsed })}
+}
+
+// Countdown counts the time left down from a duration to zero, shown after
+// a label and redrawn in place while started. It is safe for use from
+// several goroutines.
+type Countdown struct {
+	clock
+
+	// OnDone, if set, is called in its own goroutine when the time is up,
+	// while the countdown is drawn.
+	OnDone func()
+}
+
+// NewCountdown creates a paused countdown of d. Call Start to run it. A
+// countdown of 0 or less is up as soon as it is started.
+func NewCountdown(label string, d time.Duration) *Countdown {
+	d = max(d, 0)
+	cd := &Countdown{}
+	cd.clock = newClock(label, func(elapsed time.Duration) time.Duration { return d - elapsed })
+	cd.limit, cd.counts = d, true
+	cd.onDone = func() {
+		if cd.OnDone != nil {
+			cd.OnDone()
+		}
+	}
+	return cd
+}
+
+// Remaining returns the time left.
+func (cd *Countdown) Remaining() time.Duration {
+	return cd.limit - cd.Elapsed()
+}
+
+// Done returns a channel closed when the time is up. After Reset it is a
+// new channel.
+func (cd *Countdown) Done() <-chan struct{} {
+	cd.mu.Lock()
+	defer cd.mu.Unlock()
+	return cd.done
+}
@@ -0,0 +1,29 @@
+package ansi
+
+import (
+	"io"
+	"testing"
+	"time"
+)
+
+func TestCountdownDone(t *testing.T) {
+	for _, d := range []time.Duration{-time.Second, 0, 30 * time.Millisecond} {
+		cd := NewCountdown("left", d)
+		cd.SetWriter(io.Discard)
+		cd.Interval = 10 * time.Millisecond
+		called := make(chan struct{})
+		cd.OnDone = func() { close(called) }
+		cd.Start()
+		for name, ch := range map[string]<-chan struct{}{"Done": cd.Done(), "OnDone": called} {
+			select {
+			case <-ch:
+			case <-time.After(time.Second):
+				t.Errorf("NewCountdown(%v): %s not reached", d, name)
+			}
+		}
+		if got := cd.Remaining(); got != 0 {
+			t.Errorf("NewCountdown(%v): Remaining() = %v, want 0", d, got)
+		}
+		cd.Stop()
+	}
+}
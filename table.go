@@ -0,0 +1,265 @@
+package ansi
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// --------------------
+// Table
+// --------------------
+
+// Border is the set of strings a Table draws its lines with. Each should be
+// one column wide.
+type Border struct {
+	Horizontal, Vertical                       string
+	TopLeft, TopRight, BottomLeft, BottomRight string
+	TopT, BottomT, LeftT, RightT, Cross        string
+}
+
+// Border styles for a Table. BorderNone separates columns with spaces only.
+var (
+	BorderNone    = Border{}
+	BorderASCII   = Border{"-", "|", "+", "+", "+", "+", "+", "+", "+", "+", "+"}
+	BorderLight   = Border{"─", "│", "┌", "┐", "└", "┘", "┬", "┴", "├", "┤", "┼"}
+	BorderRounded = Border{"─", "│", "╭", "╮", "╰", "╯", "┬", "┴", "├", "┤", "┼"}
+	BorderHeavy   = Border{"━", "┃", "┏", "┓", "┗", "┛", "┳", "┻", "┣", "┫", "╋"}
+	BorderDouble  = Border{"═", "║", "╔", "╗", "╚", "╝", "╦", "╩", "╠", "╣", "╬"}
+)
+
+// Align is the horizontal alignment of a Table column.
+type Align int
+
+// Alignments.
+const (
+	AlignLeft Align = iota
+	AlignRight
+	AlignCenter
+)
+
+// Overflow decides what happens to cells wider than their column.
+type Overflow int
+
+// Overflow policies.
+const (
+	OverflowTruncate Overflow = iota // Cut the cell off with "…".
+	OverflowWrap                     // Wrap the cell onto more lines, at spaces where possible.
+)
+
+// Table renders rows of cells in aligned columns, fitted to the terminal
+// width. Cells may contain colors and wide characters, and line breaks.
+type Table struct {
+	Header      []string
+	Rows        [][]string
+	Border      Border     // BorderLight if not set with NewTable.
+	Align       []Align    // Alignment by column; missing columns are left-aligned.
+	MaxWidth    []int      // Maximum width by column; 0 or missing for none.
+	Overflow    []Overflow // What happens to cells too wide for their column.
+	HeaderStyle string     // Style of the header cells. The default is Bold.
+	Width       int        // Maximum width; 0 uses the width of the terminal.
+
+	// OnSelect, if set, is called by Pick with the index in Rows of the
+	// chosen row.
+	OnSelect func(row int)
+}
+
+// NewTable creates a table with the given header, light borders and a bold
+// header.
+func NewTable(header ...string) *Table {
+	return &Table{Header: header, Border: BorderLight, HeaderStyle: Bold}
+}
+
+// AddRow adds a row of cells.
+func (t *Table) AddRow(cells ...string) {
+	t.Rows = append(t.Rows, cells)
+}
+
+// SetAlign sets the alignment of column col, counted from 0.
+func (t *Table) SetAlign(col int, align Align) {
+	for len(t.Align) <= col {
+		t.Align = append(t.Align, AlignLeft)
+	}
+	t.Align[col] = align
+}
+
+// SetMaxWidth limits column col, counted from 0, to width columns, wrapping
+// or truncating wider cells as set by overflow. Columns that have to shrink
+// to fit the table in its width follow the same overflow policy.
+func (t *Table) SetMaxWidth(col, width int, overflow Overflow) {
+	for len(t.MaxWidth) <= col {
+		t.MaxWidth = append(t.MaxWidth, 0)
+	}
+	for len(t.Overflow) <= col {
+		t.Overflow = append(t.Overflow, OverflowTruncate)
+	}
+	t.MaxWidth[col] = width
+	t.Overflow[col] = overflow
+}
+
+// Print prints the table to stdout.
+func (t *Table) Print() {
+	fmt.Print(t.String())
+}
+
+// String renders the table, ending with a newline.
+func (t *Table) String() string {
+	cols := len(t.Header)
+	for _, row := range t.Rows {
+		cols = max(cols, len(row))
+	}
+	if cols == 0 {
+		return ""
+	}
+	widths := t.fit(t.naturalWidths(cols))
+
+	var sb strings.Builder
+	b := t.Border
+	bordered := b.Vertical != ""
+	if bordered {
+		sb.WriteString(t.rule(widths, b.TopLeft, b.TopT, b.TopRight))
+	}
+	if len(t.Header) > 0 {
+		t.writeRow(&sb, t.Header, widths, t.HeaderStyle)
+		if bordered {
+			sb.WriteString(t.rule(widths, b.LeftT, b.Cross, b.RightT))
+		}
+	}
+	for _, row := range t.Rows {
+		t.writeRow(&sb, row, widths, "")
+	}
+	if bordered {
+		sb.WriteString(t.rule(widths, b.BottomLeft, b.BottomT, b.BottomRight))
+	}
+	return sb.String()
+}
+
+// naturalWidths returns the width of the widest line of each column.
+func (t *Table) naturalWidths(cols int) []int {
+	widths := make([]int, cols)
+	measure := func(row []string) {
+		for i, cell := range row {
+			for _, line := range strings.Split(cell, "\n") {
+				widths[i] = max(widths[i], visibleWidth(line))
+			}
+		}
+	}
+	measure(t.Header)
+	for _, row := range t.Rows {
+		measure(row)
+	}
+	for i, w := range t.MaxWidth {
+		if w > 0 && i < cols {
+			widths[i] = min(widths[i], w)
+		}
+	}
+	return widths
+}
+
+// fit narrows the widest columns until the table fits its width.
+func (t *Table) fit(widths []int) []int {
+	limit := t.Width
+	if limit <= 0 {
+		limit, _ = termSize(os.Stdout)
+	}
+	avail := limit - t.overhead(len(widths))
+	total := 0
+	for _, w := range widths {
+		total += w
+	}
+	for total > avail {
+		widest := 0
+		for i, w := range widths {
+			if w > widths[widest] {
+				widest = i
+			}
+		}
+		if widths[widest] <= 1 {
+			break
+		}
+		widths[widest]--
+		total--
+	}
+	return widths
+}
+
+// overhead returns the columns taken by borders and padding.
+func (t *Table) overhead(cols int) int {
+	if t.Border.Vertical == "" {
+		return 2 * (cols - 1)
+	}
+	return 3*cols + 1
+}
+
+// rule returns a horizontal border line.
+func (t *Table) rule(widths []int, left, mid, right string) string {
+	var sb strings.Builder
+	sb.WriteString(left)
+	for i, w := range widths {
+		if i > 0 {
+			sb.WriteString(mid)
+		}
+		sb.WriteString(strings.Repeat(t.Border.Horizontal, w+2))
+	}
+	sb.WriteString(right + "\n")
+	return sb.String()
+}
+
+// writeRow writes a row, which is as high as its cell with the most lines.
+func (t *Table) writeRow(sb *strings.Builder, row []string, widths []int, style string) {
+	cells := make([][]string, len(widths))
+	height := 1
+	for i, w := range widths {
+		if i < len(row) {
+			if i < len(t.Overflow) && t.Overflow[i] == OverflowWrap {
+				cells[i] = wrapText(row[i], w)
+			} else {
+				cells[i] = strings.Split(row[i], "\n")
+			}
+		}
+		height = max(height, len(cells[i]))
+	}
+	bordered := t.Border.Vertical != ""
+	for line := 0; line < height; line++ {
+		if bordered {
+			sb.WriteString(t.Border.Vertical + " ")
+		}
+		for i, w := range widths {
+			if i > 0 {
+				if bordered {
+					sb.WriteString(" " + t.Border.Vertical + " ")
+				} else {
+					sb.WriteString("  ")
+				}
+			}
+			text := ""
+			if line < len(cells[i]) {
+				text = truncateWidth(cells[i][line], w)
+			}
+			if style != "" && text != "" {
+				text = style + text + End
+			}
+			sb.WriteString(t.pad(text, w, i))
+		}
+		if bordered {
+			sb.WriteString(" " + t.Border.Vertical)
+		}
+		sb.WriteString("\n")
+	}
+}
+
+// pad aligns text within width columns as set for column col.
+func (t *Table) pad(text string, width, col int) string {
+	gap := max(width-visibleWidth(text), 0)
+	align := AlignLeft
+	if col < len(t.Align) {
+		align = t.Align[col]
+	}
+	switch align {
+	case AlignRight:
+		return strings.Repeat(" ", gap) + text
+	case AlignCenter:
+		return strings.Repeat(" ", gap/2) + text + strings.Repeat(" ", gap-gap/2)
+	}
+	return text + strings.Repeat(" ", gap)
+}
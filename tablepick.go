@@ -0,0 +1,253 @@
+package ansi
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// --------------------
+// Interactive Table
+// --------------------
+
+// tablePick is the state of a running Table.Pick.
+type tablePick struct {
+	t       *Table
+	cfg     *inputConfig
+	order   []int // Indexes into t.Rows in display order.
+	index   int   // Position of the selected row in order.
+	top     int   // Position of the first visible row in order.
+	page    int   // Number of rows visible in the last drawing.
+	sortCol int   // Column the rows are sorted by, or -1.
+	desc    bool  // Whether the sort is descending.
+	lines   int   // Lines drawn above the cursor.
+}
+
+// Pick shows the table as a scrollable list and returns the index in Rows of
+// the row chosen with Enter. The arrow keys, PageUp, PageDown, Home and End
+// move the selection; the keys 1 to 9 sort by that column, and pressing the
+// same key again reverses the order. Numeric columns sort by value. Escape
+// and Ctrl-C cancel with ErrInterrupted. The table is erased when Pick
+// returns. InputWriter and InputKeyReader may be given in opts.
+func (t *Table) Pick(opts ...InputOption) (int, error) {
+	cfg := &inputConfig{out: os.Stdout}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.keys == nil {
+		cfg.keys = defaultKeyReader()
+	}
+	p := &tablePick{t: t, cfg: cfg, sortCol: -1}
+	for i := range t.Rows {
+		p.order = append(p.order, i)
+	}
+
+	fmt.Fprint(cfg.out, "\033[?25l")
+	defer fmt.Fprint(cfg.out, "\033[?25h")
+	for {
+		p.draw()
+		keyType, key, err := cfg.keys.readKey()
+		if err != nil {
+			p.erase()
+			return -1, err
+		}
+		switch {
+		case keyType == "Arrow" && key == "up":
+			p.move(-1)
+		case keyType == "Arrow" && key == "down":
+			p.move(1)
+		case keyType == "Special" && key == "pageup":
+			p.move(-p.page)
+		case keyType == "Special" && key == "pagedown":
+			p.move(p.page)
+		case keyType == "Special" && key == "home":
+			p.move(-len(p.order))
+		case keyType == "Special" && key == "end":
+			p.move(len(p.order))
+		case keyType == "Character" && len(key) == 1 && key[0] >= '1' && key[0] <= '9':
+			p.sortBy(int(key[0] - '1'))
+		case keyType == "Special" && key == "enter" && len(p.order) > 0:
+			p.erase()
+			row := p.order[p.index]
+			if t.OnSelect != nil {
+				t.OnSelect(row)
+			}
+			return row, nil
+		case keyType == "Special" && (key == "escape" || key == "ctrl-c"):
+			p.erase()
+			return -1, ErrInterrupted
+		}
+	}
+}
+
+// move moves the selection by n rows, stopping at the first and last row.
+func (p *tablePick) move(n int) {
+	p.index = max(min(p.index+n, len(p.order)-1), 0)
+}
+
+// sortBy sorts the rows by column col, reversing the order if they are
+// already sorted by it. The selected row stays selected.
+func (p *tablePick) sortBy(col int) {
+	cols := len(p.t.Header)
+	for _, row := range p.t.Rows {
+		cols = max(cols, len(row))
+	}
+	if col >= cols {
+		return
+	}
+	p.desc = col == p.sortCol && !p.desc
+	p.sortCol = col
+	selected := -1
+	if len(p.order) > 0 {
+		selected = p.order[p.index]
+	}
+	sort.SliceStable(p.order, func(i, j int) bool {
+		a, b := p.cell(p.order[i]), p.cell(p.order[j])
+		if p.desc {
+			return lessCell(b, a)
+		}
+		return lessCell(a, b)
+	})
+	for i, row := range p.order {
+		if row == selected {
+			p.index = i
+		}
+	}
+}
+
+// cell returns the text of the sort column in row.
+func (p *tablePick) cell(row int) string {
+	if cells := p.t.Rows[row]; p.sortCol < len(cells) {
+		return stripANSI(cells[p.sortCol])
+	}
+	return ""
+}
+
+// lessCell orders two cells by value if both are numbers and by text,
+// ignoring case, otherwise.
+func lessCell(a, b string) bool {
+	x, errX := strconv.ParseFloat(strings.TrimSpace(a), 64)
+	y, errY := strconv.ParseFloat(strings.TrimSpace(b), 64)
+	if errX == nil && errY == nil {
+		return x < y
+	}
+	return strings.ToLower(a) < strings.ToLower(b)
+}
+
+// draw redraws the table, showing as many rows around the selection as fit
+// the terminal, and a status line below it.
+func (p *tablePick) draw() {
+	t := p.t
+	view := *t
+	if view.Width <= 0 {
+		// Leave the last column to the scrollbar.
+		width, _ := termSize(p.cfg.out)
+		view.Width = max(width-1, 1)
+	}
+	if p.sortCol >= 0 {
+		view.Header = append([]string(nil), t.Header...)
+		for len(view.Header) <= p.sortCol {
+			view.Header = append(view.Header, "")
+		}
+		arrow := " ▲"
+		if p.desc {
+			arrow = " ▼"
+		}
+		view.Header[p.sortCol] += arrow
+	}
+	view.Rows = make([][]string, len(p.order))
+	for i, row := range p.order {
+		view.Rows[i] = t.Rows[row]
+	}
+	cols := len(view.Header)
+	for _, row := range view.Rows {
+		cols = max(cols, len(row))
+	}
+	widths := view.fit(view.naturalWidths(cols))
+
+	rows := make([]string, len(view.Rows))
+	for i, row := range view.Rows {
+		var sb strings.Builder
+		style := ""
+		if i == p.index {
+			style = Negative
+		}
+		view.writeRow(&sb, row, widths, style)
+		rows[i] = sb.String()
+	}
+
+	var head, foot strings.Builder
+	b := view.Border
+	bordered := b.Vertical != ""
+	if bordered {
+		head.WriteString(view.rule(widths, b.TopLeft, b.TopT, b.TopRight))
+	}
+	if len(view.Header) > 0 && cols > 0 {
+		view.writeRow(&head, view.Header, widths, view.HeaderStyle)
+		if bordered {
+			head.WriteString(view.rule(widths, b.LeftT, b.Cross, b.RightT))
+		}
+	}
+	if bordered {
+		foot.WriteString(view.rule(widths, b.BottomLeft, b.BottomT, b.BottomRight))
+	}
+
+	// Scroll so the selected row is visible, filling the height of the
+	// terminal less the header, the footer and the status line.
+	_, height := termSize(p.cfg.out)
+	avail := max(height-strings.Count(head.String(), "\n")-strings.Count(foot.String(), "\n")-1, 1)
+	p.top = min(p.top, p.index)
+	for p.top < p.index && spanLines(rows[p.top:p.index+1]) > avail {
+		p.top++
+	}
+	end := p.top
+	for used := 0; end < len(rows); end++ {
+		n := strings.Count(rows[end], "\n")
+		if used+n > avail && end > p.top {
+			break
+		}
+		used += n
+	}
+	p.page = max(end-p.top, 1)
+
+	var sb strings.Builder
+	if p.lines > 0 {
+		sb.WriteString(fmt.Sprintf("\033[%dF", p.lines))
+	}
+	sb.WriteString("\r\033[J")
+	sb.WriteString(head.String())
+	shown := strings.Join(rows[p.top:end], "")
+	if end-p.top < len(rows) {
+		width := visibleWidth(strings.SplitN(shown, "\n", 2)[0])
+		shown = defaultScrollbar().attachRows(shown, width+1, len(rows), end-p.top, p.top)
+	}
+	sb.WriteString(shown)
+	sb.WriteString(foot.String())
+	status := "no rows"
+	if len(rows) > 0 {
+		status = fmt.Sprintf("row %d of %d", p.index+1, len(rows))
+	}
+	sb.WriteString(Faint + status + " · ↑↓ move · 1-9 sort · enter select · esc cancel" + End)
+	p.lines = strings.Count(sb.String(), "\n")
+	fmt.Fprint(p.cfg.out, strings.ReplaceAll(sb.String(), "\n", "\r\n"))
+}
+
+// spanLines returns the number of lines taken by rows.
+func spanLines(rows []string) int {
+	n := 0
+	for _, row := range rows {
+		n += strings.Count(row, "\n")
+	}
+	return n
+}
+
+// erase clears the table from the screen.
+func (p *tablePick) erase() {
+	if p.lines > 0 {
+		fmt.Fprintf(p.cfg.out, "\033[%dF", p.lines)
+	}
+	fmt.Fprint(p.cfg.out, "\r\033[J")
+	p.lines = 0
+}
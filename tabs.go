@@ -0,0 +1,141 @@
+package ansi
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// --------------------
+// Tabs
+// --------------------
+
+// Tab is a named page of Tabs. Render returns the lines of the page for a
+// region of width columns and height rows.
+type Tab struct {
+	Name   string
+	Render func(width, height int) []string
+}
+
+// Tabs shows a bar of named tabs in the top row of a rectangle of the screen
+// and the active tab's page below it.
+type Tabs struct {
+	Row, Col      int    // Screen position of the top left corner, counted from 1.
+	Width, Height int    // Size in columns and rows, including the bar.
+	ActiveStyle   string // Style of the active tab's name. The default is Negative.
+
+	tabs   []Tab
+	active int
+}
+
+// NewTabs creates tabs in a rectangle of the screen.
+func NewTabs(row, col, width, height int) *Tabs {
+	return &Tabs{Row: row, Col: col, Width: width, Height: height, ActiveStyle: Negative}
+}
+
+// Add adds a tab at the end of the bar.
+func (t *Tabs) Add(name string, render func(width, height int) []string) {
+	t.tabs = append(t.tabs, Tab{name, render})
+}
+
+// Active returns the index of the active tab.
+func (t *Tabs) Active() int {
+	return t.active
+}
+
+// Select makes tab i active.
+func (t *Tabs) Select(i int) {
+	if i >= 0 && i < len(t.tabs) {
+		t.active = i
+	}
+}
+
+// HandleKey switches tabs with left and right, which wrap around, or with
+// the keys 1 to 9, and reports whether the key was used.
+func (t *Tabs) HandleKey(keyType, key string) bool {
+	if len(t.tabs) == 0 {
+		return false
+	}
+	switch {
+	case keyType == "Arrow" && key == "left", keyType == "Special" && key == "shift-tab":
+		t.active = (t.active + len(t.tabs) - 1) % len(t.tabs)
+	case keyType == "Arrow" && key == "right", keyType == "Special" && key == "tab":
+		t.active = (t.active + 1) % len(t.tabs)
+	case keyType == "Character" && len(key) == 1 && key[0] >= '1' && key[0] <= '9' && int(key[0]-'1') < len(t.tabs):
+		t.active = int(key[0] - '1')
+	default:
+		return false
+	}
+	return true
+}
+
+// Render returns the escape sequences drawing the bar and the active page.
+func (t *Tabs) Render() string {
+	return t.RenderBar() + t.RenderPage()
+}
+
+// RenderBar returns the escape sequences drawing the bar, with the number
+// and name of each tab.
+func (t *Tabs) RenderBar() string {
+	var sb strings.Builder
+	for i, tab := range t.tabs {
+		if i > 0 {
+			sb.WriteString(Faint + "│" + End)
+		}
+		name := fmt.Sprintf(" %d %s ", i+1, tab.Name)
+		if i == t.active {
+			name = t.ActiveStyle + name + End
+		}
+		sb.WriteString(name)
+	}
+	bar := truncateWidth(sb.String(), t.Width)
+	bar += strings.Repeat(" ", max(t.Width-visibleWidth(bar), 0))
+	return fmt.Sprintf("\033[%d;%dH%s", t.Row, t.Col, bar)
+}
+
+// RenderPage returns the escape sequences drawing only the active tab's page,
+// in the rows below the bar, for updating it without redrawing the bar.
+func (t *Tabs) RenderPage() string {
+	height := max(t.Height-1, 0)
+	var lines []string
+	if t.active < len(t.tabs) && t.tabs[t.active].Render != nil {
+		lines = t.tabs[t.active].Render(t.Width, height)
+	}
+	var sb strings.Builder
+	for i := 0; i < height; i++ {
+		line := ""
+		if i < len(lines) {
+			line = truncateWidth(lines[i], t.Width)
+		}
+		line += strings.Repeat(" ", max(t.Width-visibleWidth(line), 0))
+		sb.WriteString(fmt.Sprintf("\033[%d;%dH%s", t.Row+1+i, t.Col, line))
+	}
+	return sb.String()
+}
+
+// Run shows the tabs filling the alternate screen until q, Escape or Ctrl-C
+// is pressed. InputWriter and InputKeyReader may be given in opts.
+func (t *Tabs) Run(opts ...InputOption) error {
+	cfg := &inputConfig{out: os.Stdout}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.keys == nil {
+		cfg.keys = defaultKeyReader()
+	}
+	fmt.Fprint(cfg.out, "\033[?1049h\033[?25l\033[2J")
+	defer fmt.Fprint(cfg.out, "\033[?25h\033[?1049l")
+	t.Row, t.Col = 1, 1
+	for {
+		t.Width, t.Height = termSize(cfg.out)
+		fmt.Fprint(cfg.out, t.Render())
+		keyType, key, err := cfg.keys.readKey()
+		if err != nil {
+			return err
+		}
+		if keyType == "Character" && key == "q" || keyType == "Special" && (key == "escape" || key == "ctrl-c") {
+			return nil
+		}
+		t.HandleKey(keyType, key)
+	}
+}
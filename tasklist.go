@@ -0,0 +1,385 @@
+package ansi
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// --------------------
+// TaskList
+// --------------------
+
+// TaskState is the state of a step in a TaskList.
+type TaskState int
+
+// Task states.
+const (
+	TaskPending TaskState = iota
+	TaskRunning
+	TaskDone
+	TaskFailed
+	TaskSkipped
+)
+
+// task is one step of a TaskList.
+type task struct {
+	name  string
+	state TaskState
+	note  string // Shown after the name, e.g. the error of a failed step.
+	err   error  // Error of a failed step.
+	start time.Time
+	end   time.Time
+}
+
+// TaskList shows a checklist of steps, each pending, running with a spinner,
+// done, failed or skipped, with the time the finished steps took. It is safe
+// for use from several goroutines, so steps may run concurrently, and Go
+// runs a step in a goroutine of its own.
+type TaskList struct {
+	mu     sync.Mutex
+	out    io.Writer
+	plain  *bool
+	frames []string
+	frame  int
+	tasks  []*task
+	lines  int           // Lines drawn by the last draw.
+	stop   chan struct{} // Closed to stop the animation; nil when it is not running.
+
+	wg    sync.WaitGroup
+	slots chan struct{} // Limits the steps run by Go at once; nil for no limit.
+}
+
+// TaskListOption configures a TaskList.
+type TaskListOption func(*TaskList)
+
+// TaskListWriter sets where the list is drawn. It defaults to os.Stdout.
+func TaskListWriter(w io.Writer) TaskListOption {
+	return func(tl *TaskList) {
+		tl.out = w
+	}
+}
+
+// TaskListFrames sets the spinner frames of running steps. The default is
+// SpinnerBraille.
+func TaskListFrames(frames []string) TaskListOption {
+	return func(tl *TaskList) {
+		if len(frames) > 0 {
+			tl.frames = frames
+		}
+	}
+}
+
+// TaskListPlain forces plain-text output on or off. Plain output prints a
+// line whenever a step starts or finishes. By default it is used when the
+// output is not a terminal.
+func TaskListPlain(on bool) TaskListOption {
+	return func(tl *TaskList) {
+		tl.plain = &on
+	}
+}
+
+// TaskListLimit sets how many steps run by Go may run at once. The others
+// stay pending until one finishes. By default there is no limit.
+func TaskListLimit(n int) TaskListOption {
+	return func(tl *TaskList) {
+		if n > 0 {
+			tl.slots = make(chan struct{}, n)
+		}
+	}
+}
+
+// NewTaskList creates a task list with the given pending steps. More can be
+// added with Add.
+func NewTaskList(steps []string, opts ...TaskListOption) *TaskList {
+	tl := &TaskList{out: os.Stdout, frames: SpinnerBraille}
+	for _, opt := range opts {
+		opt(tl)
+	}
+	if tl.plain == nil {
+		plain := !isTerminal(tl.out)
+		tl.plain = &plain
+	}
+	for _, name := range steps {
+		tl.tasks = append(tl.tasks, &task{name: name})
+	}
+	tl.mu.Lock()
+	tl.draw()
+	tl.mu.Unlock()
+	return tl
+}
+
+// Add adds a pending step at the end of the list.
+func (tl *TaskList) Add(name string) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	tl.tasks = append(tl.tasks, &task{name: name})
+	tl.draw()
+}
+
+// Start marks the named step as running. A step that is not in the list is
+// added first.
+func (tl *TaskList) Start(name string) {
+	tl.set(name, TaskRunning, "", nil)
+}
+
+// Done marks the named step as done.
+func (tl *TaskList) Done(name string) {
+	tl.set(name, TaskDone, "", nil)
+}
+
+// Fail marks the named step as failed, showing err after it if not nil.
+func (tl *TaskList) Fail(name string, err error) {
+	note := ""
+	if err != nil {
+		note = err.Error()
+	}
+	tl.set(name, TaskFailed, note, err)
+}
+
+// Skip marks the named step as skipped, showing reason after it.
+func (tl *TaskList) Skip(name, reason string) {
+	tl.set(name, TaskSkipped, reason, nil)
+}
+
+// Go runs fn in a new goroutine as the named step, which is running while fn
+// runs and then done, or failed if fn returns an error. A step that is not
+// in the list is added first. Wait waits for the steps run by Go.
+func (tl *TaskList) Go(name string, fn func() error) {
+	tl.mu.Lock()
+	if tl.find(name) == nil {
+		tl.tasks = append(tl.tasks, &task{name: name})
+		tl.draw()
+	}
+	tl.mu.Unlock()
+	tl.wg.Add(1)
+	go func() {
+		defer tl.wg.Done()
+		if tl.slots != nil {
+			tl.slots <- struct{}{}
+			defer func() { <-tl.slots }()
+		}
+		tl.Start(name)
+		if err := fn(); err != nil {
+			tl.Fail(name, err)
+		} else {
+			tl.Done(name)
+		}
+	}()
+}
+
+// Wait waits for the steps run by Go to finish, stops the animation and
+// prints the Summary below the list. It returns the errors of the failed
+// steps, each prefixed with the name of its step, joined with errors.Join.
+func (tl *TaskList) Wait() error {
+	tl.wg.Wait()
+	tl.Stop()
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	summary := tl.summary()
+	if tl.isPlain() {
+		summary = stripANSI(summary)
+	}
+	fmt.Fprintln(tl.out, summary)
+	var errs []error
+	for _, t := range tl.tasks {
+		if t.state == TaskFailed {
+			err := t.err
+			if err == nil {
+				err = errors.New("failed")
+			}
+			errs = append(errs, fmt.Errorf("%s: %w", t.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Summary returns the number of steps in each state, like "3 done, 1 failed
+// in 4.2s", counting from the start of the first step to the end of the last
+// one finished.
+func (tl *TaskList) Summary() string {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	return tl.summary()
+}
+
+// summary returns the Summary.
+func (tl *TaskList) summary() string {
+	var counts [5]int
+	var first, last time.Time
+	for _, t := range tl.tasks {
+		counts[t.state]++
+		if !t.start.IsZero() && (first.IsZero() || t.start.Before(first)) {
+			first = t.start
+		}
+		if t.state >= TaskDone && t.end.After(last) {
+			last = t.end
+		}
+	}
+	var parts []string
+	for _, c := range []struct {
+		state TaskState
+		word  string
+		style string
+	}{
+		{TaskDone, "done", Green},
+		{TaskFailed, "failed", Red},
+		{TaskSkipped, "skipped", Faint},
+		{TaskRunning, "running", Cyan},
+		{TaskPending, "pending", Faint},
+	} {
+		if n := counts[c.state]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%s%d %s%s", c.style, n, c.word, End))
+		}
+	}
+	if len(parts) == 0 {
+		return Faint + "no steps" + End
+	}
+	summary := strings.Join(parts, ", ")
+	if !first.IsZero() && last.After(first) {
+		summary += fmt.Sprintf(" in %s", last.Sub(first).Round(100*time.Millisecond))
+	}
+	return summary
+}
+
+// State returns the state of the named step.
+func (tl *TaskList) State(name string) TaskState {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	if t := tl.find(name); t != nil {
+		return t.state
+	}
+	return TaskPending
+}
+
+// Stop stops the animation of running steps and draws the list a last time.
+// It is only needed when steps are left running; the animation stops on its
+// own once no step is running.
+func (tl *TaskList) Stop() {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	if tl.stop != nil {
+		close(tl.stop)
+		tl.stop = nil
+	}
+	tl.draw()
+}
+
+// find returns the named step, or nil.
+func (tl *TaskList) find(name string) *task {
+	for _, t := range tl.tasks {
+		if t.name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// set changes the state of a step and redraws the list.
+func (tl *TaskList) set(name string, state TaskState, note string, err error) {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	t := tl.find(name)
+	if t == nil {
+		t = &task{name: name}
+		tl.tasks = append(tl.tasks, t)
+	}
+	now := time.Now()
+	if state == TaskRunning || t.start.IsZero() {
+		t.start = now
+	}
+	t.state, t.note, t.err, t.end = state, note, err, now
+	if tl.isPlain() {
+		fmt.Fprintln(tl.out, stripANSI(tl.line(t)))
+		return
+	}
+	if state == TaskRunning && tl.stop == nil {
+		tl.stop = make(chan struct{})
+		go tl.animate(tl.stop)
+	}
+	tl.draw()
+}
+
+// animate advances the spinners of running steps until stop is closed or no
+// step is running.
+func (tl *TaskList) animate(stop chan struct{}) {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		tl.mu.Lock()
+		if tl.stop != stop {
+			tl.mu.Unlock()
+			return
+		}
+		tl.frame = (tl.frame + 1) % len(tl.frames)
+		tl.draw()
+		running := false
+		for _, t := range tl.tasks {
+			running = running || t.state == TaskRunning
+		}
+		if !running {
+			tl.stop = nil
+			tl.mu.Unlock()
+			return
+		}
+		tl.mu.Unlock()
+	}
+}
+
+// line returns the line of a step.
+func (tl *TaskList) line(t *task) string {
+	var line string
+	switch t.state {
+	case TaskPending:
+		return Faint + "○ " + t.name + End
+	case TaskRunning:
+		if tl.isPlain() {
+			return "… " + t.name
+		}
+		return Cyan + tl.frames[tl.frame] + End + " " + t.name + Faint + " " + formatDuration(time.Since(t.start)) + End
+	case TaskDone:
+		line = Green + "✔" + End + " " + t.name
+	case TaskFailed:
+		line = Red + "✖" + End + " " + t.name
+	case TaskSkipped:
+		line = Faint + "- " + t.name + End
+	}
+	if t.state != TaskSkipped {
+		line += fmt.Sprintf(" %s(%s)%s", Faint, t.end.Sub(t.start).Round(100*time.Millisecond), End)
+	}
+	if t.note != "" {
+		line += " " + Faint + t.note + End
+	}
+	return line
+}
+
+// draw redraws the whole list in place.
+func (tl *TaskList) draw() {
+	if tl.isPlain() {
+		return
+	}
+	var sb strings.Builder
+	if tl.lines > 0 {
+		sb.WriteString(fmt.Sprintf("\033[%dF", tl.lines))
+	}
+	width, _ := termSize(tl.out)
+	for _, t := range tl.tasks {
+		sb.WriteString("\033[2K" + truncateWidth(tl.line(t), width) + "\n")
+	}
+	tl.lines = len(tl.tasks)
+	fmt.Fprint(tl.out, sb.String())
+}
+
+// isPlain reports whether the list prints plain lines.
+func (tl *TaskList) isPlain() bool {
+	return tl.plain != nil && *tl.plain
+}
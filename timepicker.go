@@ -0,0 +1,228 @@
+package ansi
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// --------------------
+// Time Pickers
+// --------------------
+
+// segment is a numeric field of a segmented prompt, like the hours of a time.
+type segment struct {
+	value    int
+	min, max int
+	width    int    // Digits shown.
+	wrap     bool   // Whether stepping past one end goes to the other.
+	suffix   string // Shown after the field, like ":" or "h".
+	typed    int    // Digits typed into the field since it was focused.
+}
+
+// step changes the value by n, wrapping around or stopping at the ends.
+func (s *segment) step(n int) {
+	s.typed = 0
+	v := s.value + n
+	switch {
+	case s.wrap:
+		span := s.max - s.min + 1
+		v = s.min + ((v-s.min)%span+span)%span
+	default:
+		v = min(max(v, s.min), s.max)
+	}
+	s.value = v
+}
+
+// typeDigit types digit d into the field and reports whether the field is
+// full, so the next one should be focused.
+func (s *segment) typeDigit(d int) bool {
+	if s.typed == 0 {
+		s.value = 0
+	}
+	s.value = min(s.value*10+d, s.max)
+	s.typed++
+	return s.typed >= s.width || s.value*10 > s.max
+}
+
+// pickSegments runs a prompt editing segs: left, right, Tab and Shift-Tab
+// move between fields, up and down (or k and j) step the focused one and
+// digits type into it. check is called on Enter and its error shown
+// instead of returning. Escape and Ctrl-C cancel with ErrInterrupted.
+func pickSegments(cfg *inputConfig, prompt string, segs []*segment, check func() error) error {
+	if cfg.keys == nil {
+		cfg.keys = defaultKeyReader()
+	}
+	if cfg.promptStyle != "" {
+		prompt = cfg.promptStyle + prompt + End
+	}
+	text := func(focus int) string {
+		var sb strings.Builder
+		for i, s := range segs {
+			field := fmt.Sprintf("%0*d", s.width, s.value)
+			if i == focus {
+				field = Negative + field + End
+			} else if focus >= 0 {
+				field = Cyan + field + End
+			}
+			sb.WriteString(field + s.suffix)
+		}
+		return sb.String()
+	}
+
+	fmt.Fprint(cfg.out, "\033[?25l")
+	defer fmt.Fprint(cfg.out, "\033[?25h")
+	focus, message := 0, ""
+	for {
+		hint := Faint + "↑↓ change · ←→ field · enter ok" + End
+		if message != "" {
+			hint = cfg.errorStyle + message + End
+		}
+		fmt.Fprintf(cfg.out, "\r\033[J%s %s\r\n%s\033[A", prompt, text(focus), hint)
+
+		keyType, key, err := cfg.keys.readKey()
+		if err != nil {
+			return err
+		}
+		s := segs[focus]
+		message = ""
+		switch {
+		case keyType == "Special" && key == "enter":
+			if err := check(); err != nil {
+				message = err.Error()
+				continue
+			}
+			fmt.Fprintf(cfg.out, "\r\033[J%s %s\n", prompt, Cyan+stripANSI(text(-1))+End)
+			return nil
+		case keyType == "Special" && (key == "escape" || key == "ctrl-c"):
+			fmt.Fprint(cfg.out, "\r\033[J"+prompt+"\n")
+			if key == "escape" && cfg.escapeBack {
+				return errBack
+			}
+			return ErrInterrupted
+		case keyType == "Arrow" && key == "left", keyType == "Special" && key == "shift-tab":
+			focus = max(focus-1, 0)
+			s.typed = 0
+		case keyType == "Arrow" && key == "right", keyType == "Special" && key == "tab":
+			focus = min(focus+1, len(segs)-1)
+			s.typed = 0
+		case keyType == "Arrow" && key == "up", keyType == "Character" && key == "k":
+			s.step(1)
+		case keyType == "Arrow" && key == "down", keyType == "Character" && key == "j":
+			s.step(-1)
+		case keyType == "Special" && key == "pageup":
+			s.step(10)
+		case keyType == "Special" && key == "pagedown":
+			s.step(-10)
+		case keyType == "Character" && len(key) == 1 && key[0] >= '0' && key[0] <= '9':
+			if s.typeDigit(int(key[0]-'0')) && focus < len(segs)-1 {
+				focus++
+			}
+		case keyType == "Special" && key == "backspace":
+			s.value, s.typed = s.min, 0
+		}
+	}
+}
+
+// PickTime asks for a time of day in hours and minutes, each changed with
+// the arrow keys or typed in, and returns it on the current day. InputDefault
+// sets the time shown first, as "15:04" or, to also ask for seconds,
+// "15:04:05"; the default is the current time. InputValidator checks the
+// chosen time in the same form. Escape and Ctrl-C cancel with
+// ErrInterrupted. InputPromptStyle, InputErrorStyle, InputWriter and
+// InputKeyReader may also be given in opts.
+func PickTime(prompt string, opts ...InputOption) (time.Time, error) {
+	cfg := &inputConfig{out: os.Stdout, errorStyle: Red}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	now := time.Now()
+	start, layout := now, "15:04"
+	if t, err := time.Parse(time.TimeOnly, cfg.defaultText); err == nil {
+		start, layout = t, time.TimeOnly
+	} else if t, err := time.Parse("15:04", cfg.defaultText); err == nil {
+		start = t
+	}
+	segs := []*segment{
+		{value: start.Hour(), max: 23, width: 2, wrap: true, suffix: ":"},
+		{value: start.Minute(), max: 59, width: 2, wrap: true},
+	}
+	if layout == time.TimeOnly {
+		segs[1].suffix = ":"
+		segs = append(segs, &segment{value: start.Second(), max: 59, width: 2, wrap: true})
+	}
+	result := func() time.Time {
+		sec := 0
+		if len(segs) > 2 {
+			sec = segs[2].value
+		}
+		return time.Date(now.Year(), now.Month(), now.Day(), segs[0].value, segs[1].value, sec, 0, now.Location())
+	}
+	err := pickSegments(cfg, prompt, segs, func() error {
+		if cfg.validate != nil {
+			return cfg.validate(result().Format(layout))
+		}
+		return nil
+	})
+	return result(), err
+}
+
+// DurationMin sets the shortest duration PickDuration accepts.
+func DurationMin(d time.Duration) InputOption {
+	return func(c *inputConfig) {
+		c.durationMin = d
+	}
+}
+
+// DurationMax sets the longest duration PickDuration accepts, which also
+// limits the hours that can be entered. The default is 999 hours.
+func DurationMax(d time.Duration) InputOption {
+	return func(c *inputConfig) {
+		c.durationMax = d
+	}
+}
+
+// PickDuration asks for a duration in hours, minutes and seconds, each
+// changed with the arrow keys or typed in. InputDefault sets the duration
+// shown first, like "1h30m", and DurationMin and DurationMax bound it.
+// InputValidator checks the chosen duration as formatted by
+// time.Duration.String. Escape and Ctrl-C cancel with ErrInterrupted.
+// InputPromptStyle, InputErrorStyle, InputWriter and InputKeyReader may also
+// be given in opts.
+func PickDuration(prompt string, opts ...InputOption) (time.Duration, error) {
+	cfg := &inputConfig{out: os.Stdout, errorStyle: Red}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	longest := 999 * time.Hour
+	if cfg.durationMax > 0 {
+		longest = cfg.durationMax
+	}
+	start, _ := time.ParseDuration(cfg.defaultText)
+	start = min(max(start, cfg.durationMin, 0), longest).Truncate(time.Second)
+	hours := int(longest / time.Hour)
+	segs := []*segment{
+		{value: int(start / time.Hour), max: hours, width: max(len(strconv.Itoa(hours)), 2), suffix: "h "},
+		{value: int(start % time.Hour / time.Minute), max: 59, width: 2, wrap: true, suffix: "m "},
+		{value: int(start % time.Minute / time.Second), max: 59, width: 2, wrap: true, suffix: "s"},
+	}
+	result := func() time.Duration {
+		return time.Duration(segs[0].value)*time.Hour + time.Duration(segs[1].value)*time.Minute + time.Duration(segs[2].value)*time.Second
+	}
+	err := pickSegments(cfg, prompt, segs, func() error {
+		d := result()
+		if d < cfg.durationMin {
+			return fmt.Errorf("must be at least %s", cfg.durationMin)
+		}
+		if d > longest {
+			return fmt.Errorf("must be at most %s", longest)
+		}
+		if cfg.validate != nil {
+			return cfg.validate(d.String())
+		}
+		return nil
+	})
+	return result(), err
+}
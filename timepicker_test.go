@@ -0,0 +1,27 @@
+package ansi
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPickDuration(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  []InputOption
+		want  time.Duration
+	}{
+		{"three digit hours", "100\r", nil, 100 * time.Hour},
+		{"max", "\x1b[A\x1b[A\r", []InputOption{DurationMax(90 * time.Minute)}, time.Hour},
+		// Starts at 2m; Enter at 1m is refused.
+		{"min", "\x1b[C\x1b[B\r\x1b[A\x1b[A\r", []InputOption{DurationMin(2 * time.Minute)}, 3 * time.Minute},
+		{"default", "\r", []InputOption{InputDefault("1h30m")}, 90 * time.Minute},
+	}
+	for _, tt := range tests {
+		got, err := PickDuration("Timeout:", append(keys(tt.input), tt.opts...)...)
+		if got != tt.want || err != nil {
+			t.Errorf("%s: PickDuration(%q) = %v, %v; want %v, nil", tt.name, tt.input, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package ansi
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// --------------------
+// Toast
+// --------------------
+
+// ToastLevel is the kind of a toast, which sets its icon and color.
+type ToastLevel int
+
+// Toast levels.
+const (
+	ToastInfo ToastLevel = iota
+	ToastSuccess
+	ToastWarn
+	ToastError
+)
+
+// toastStyles are the icons and colors of toasts by level.
+var toastStyles = map[ToastLevel][2]string{
+	ToastInfo:    {"ℹ", Cyan},
+	ToastSuccess: {"✔", Green},
+	ToastWarn:    {"⚠", Yellow},
+	ToastError:   {"✖", Red},
+}
+
+// Corner is a corner of the screen.
+type Corner int
+
+// Corners.
+const (
+	CornerTopRight Corner = iota
+	CornerTopLeft
+	CornerBottomRight
+	CornerBottomLeft
+)
+
+// toastSlide is how long a toast takes to slide in or out.
+const toastSlide = 150 * time.Millisecond
+
+// Toaster shows toasts: short notifications that slide into a corner of the
+// screen over the main content and go away after a timeout. Toasts shown
+// while others are up stack away from the corner, oldest nearest to it. The
+// cursor is saved and restored around each update, so the main content is
+// not disturbed; it should write through the Toaster, which is an
+// io.Writer, so its output never interleaves with a toast. A Toaster is safe
+// for use from several goroutines.
+type Toaster struct {
+	corner   Corner
+	timeout  time.Duration
+	width    int
+	out      io.Writer
+	backdrop func() string
+
+	mu      sync.Mutex
+	toasts  []*toast
+	covered map[int][2]int // Start column and width covered on each row by the last draw.
+	drawn   string         // Escape sequences of the last draw.
+	running bool           // Whether the animation goroutine runs.
+}
+
+// toast is a toast on screen.
+type toast struct {
+	level   ToastLevel
+	message string
+	shown   time.Time
+	expires time.Time
+}
+
+// ToasterOption configures a Toaster.
+type ToasterOption func(*Toaster)
+
+// ToastCorner sets the corner toasts appear in. The default is
+// CornerTopRight.
+func ToastCorner(c Corner) ToasterOption {
+	return func(t *Toaster) {
+		t.corner = c
+	}
+}
+
+// ToastTimeout sets how long toasts stay up. The default is 3s.
+func ToastTimeout(d time.Duration) ToasterOption {
+	return func(t *Toaster) {
+		if d > 0 {
+			t.timeout = d
+		}
+	}
+}
+
+// ToastWidth sets the width of toasts, borders included. The default is 40.
+func ToastWidth(n int) ToasterOption {
+	return func(t *Toaster) {
+		t.width = max(n, 8)
+	}
+}
+
+// ToastWriter sets where toasts are drawn. It defaults to os.Stdout.
+func ToastWriter(w io.Writer) ToasterOption {
+	return func(t *Toaster) {
+		t.out = w
+	}
+}
+
+// ToastBackdrop sets a function returning the escape sequences that redraw
+// the main content, used to restore it where a toast went away. Without it
+// that area is cleared.
+func ToastBackdrop(redraw func() string) ToasterOption {
+	return func(t *Toaster) {
+		t.backdrop = redraw
+	}
+}
+
+// NewToaster creates a toaster.
+func NewToaster(opts ...ToasterOption) *Toaster {
+	t := &Toaster{
+		timeout: 3 * time.Second,
+		width:   40,
+		out:     os.Stdout,
+		covered: map[int][2]int{},
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Show shows a toast of level with message.
+func (t *Toaster) Show(level ToastLevel, message string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	t.toasts = append(t.toasts, &toast{level, stripANSI(message), now, now.Add(t.timeout)})
+	t.draw(now)
+	if !t.running {
+		t.running = true
+		go t.animate()
+	}
+}
+
+// Info shows a cyan toast marked ℹ.
+func (t *Toaster) Info(message string) {
+	t.Show(ToastInfo, message)
+}
+
+// Success shows a green toast marked ✔.
+func (t *Toaster) Success(message string) {
+	t.Show(ToastSuccess, message)
+}
+
+// Warn shows a yellow toast marked ⚠.
+func (t *Toaster) Warn(message string) {
+	t.Show(ToastWarn, message)
+}
+
+// Error shows a red toast marked ✖.
+func (t *Toaster) Error(message string) {
+	t.Show(ToastError, message)
+}
+
+// Clear removes all toasts at once.
+func (t *Toaster) Clear() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.toasts = nil
+	t.draw(time.Now())
+}
+
+// Write writes p to the output, waiting for any toast being drawn.
+func (t *Toaster) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.out.Write(p)
+}
+
+// animate moves and expires the toasts until none are left.
+func (t *Toaster) animate() {
+	ticker := time.NewTicker(30 * time.Millisecond)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		t.mu.Lock()
+		t.draw(now)
+		if len(t.toasts) == 0 {
+			t.running = false
+			t.mu.Unlock()
+			return
+		}
+		t.mu.Unlock()
+	}
+}
+
+// lines returns the rows of a toast of width columns, without styles.
+func (ts *toast) lines(width int) []string {
+	icon := toastStyles[ts.level][0]
+	rows := []string{"╭" + strings.Repeat("─", width-2) + "╮"}
+	for i, line := range wrapText(ts.message, width-6) {
+		if i == 0 {
+			line = icon + " " + line
+		} else {
+			line = "  " + line
+		}
+		rows = append(rows, "│ "+line+strings.Repeat(" ", max(width-4-visibleWidth(line), 0))+" │")
+	}
+	return append(rows, "╰"+strings.Repeat("─", width-2)+"╯")
+}
+
+// draw drops the expired toasts and draws the others as they are at now,
+// clearing what they no longer cover.
+func (t *Toaster) draw(now time.Time) {
+	live := t.toasts[:0]
+	for _, ts := range t.toasts {
+		if now.Before(ts.expires) {
+			live = append(live, ts)
+		}
+	}
+	clear(t.toasts[len(live):])
+	t.toasts = live
+
+	screenWidth, screenHeight := termSize(t.out)
+	width := max(min(t.width, screenWidth-2), 8)
+	right := t.corner == CornerTopRight || t.corner == CornerBottomRight
+	bottom := t.corner == CornerBottomRight || t.corner == CornerBottomLeft
+
+	covered := map[int][2]int{}
+	var draws strings.Builder
+	used := 0
+	for _, ts := range t.toasts {
+		lines := ts.lines(width)
+		if used+len(lines) > screenHeight {
+			break
+		}
+		// The part of the toast shown while sliding in or out.
+		shown := min(now.Sub(ts.shown), ts.expires.Sub(now), toastSlide)
+		visible := int(float64(width) * float64(shown) / float64(toastSlide))
+		if visible <= 0 {
+			used += len(lines)
+			continue
+		}
+		for i, line := range lines {
+			row := 1 + used + i
+			if bottom {
+				row = screenHeight - used - len(lines) + 1 + i
+			}
+			col := 2
+			if right {
+				col = screenWidth - visible
+				line = cutWidth(line, visible)
+			} else {
+				line = skipWidth(line, width-visible)
+			}
+			covered[row] = [2]int{col, visibleWidth(line)}
+			draws.WriteString(fmt.Sprintf("\033[%d;%dH%s%s%s", row, col, toastStyles[ts.level][1], line, End))
+		}
+		used += len(lines)
+	}
+
+	var sb strings.Builder
+	cleared := false
+	for row, old := range t.covered {
+		// Blank the columns of the row that are no longer covered.
+		start, end := old[0], old[0]+old[1]
+		span, ok := covered[row]
+		if !ok {
+			span = [2]int{end, 0}
+		}
+		for _, gap := range [][2]int{{start, min(span[0], end)}, {max(span[0]+span[1], start), end}} {
+			if gap[1] > gap[0] {
+				sb.WriteString(fmt.Sprintf("\033[%d;%dH%s", row, gap[0], strings.Repeat(" ", gap[1]-gap[0])))
+				cleared = true
+			}
+		}
+	}
+	if cleared && t.backdrop != nil {
+		sb.WriteString(t.backdrop())
+	}
+	if !cleared && draws.String() == t.drawn {
+		return
+	}
+	sb.WriteString(draws.String())
+	t.covered, t.drawn = covered, draws.String()
+	if sb.Len() > 0 {
+		fmt.Fprint(t.out, "\0337"+sb.String()+"\0338")
+	}
+}
@@ -0,0 +1,132 @@
+package ansi
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// --------------------
+// Tooltip
+// --------------------
+
+// Tooltip is a small hint drawn over the screen next to the cursor or a
+// widget, such as why a form field is invalid. It is placed below what it
+// points at, or above it if there is no room below, and moved left to stay
+// on screen. Drawing it elsewhere or hiding it blanks what it covered and
+// calls Backdrop to redraw that part of the screen.
+type Tooltip struct {
+	Text     string
+	Style    string // The default is Negative.
+	Border   Border // BorderNone, the default, draws the text alone with a space on each side.
+	MaxWidth int    // Longer text wraps. The default is 40 columns, borders included.
+
+	// Backdrop, if set, returns the escape sequences that redraw the screen
+	// below the tooltip, used to restore it where the tooltip no longer is.
+	// Without it that area is cleared.
+	Backdrop func() string
+
+	drawn Rect // Where the tooltip was last drawn, or empty.
+}
+
+// NewTooltip creates a tooltip showing text.
+func NewTooltip(text string) *Tooltip {
+	return &Tooltip{Text: text, Style: Negative, MaxWidth: 40}
+}
+
+// lines returns the rows of the text, at most width columns wide.
+func (t *Tooltip) lines(width int) []string {
+	return wrapText(t.Text, max(width, 1))
+}
+
+// frame returns the columns and rows the border and padding add around the
+// text.
+func (t *Tooltip) frame() (int, int) {
+	if t.Border.Vertical == "" {
+		return 2, 0
+	}
+	return 4, 2
+}
+
+// Rect returns where the tooltip is drawn for anchor, the cell of the cursor
+// or the rectangle of a widget, in screen.
+func (t *Tooltip) Rect(anchor, screen Rect) Rect {
+	padX, padY := t.frame()
+	maxWidth := t.MaxWidth
+	if maxWidth <= 0 {
+		maxWidth = 40
+	}
+	maxWidth = max(min(maxWidth, screen.Width), padX+1)
+	lines := t.lines(maxWidth - padX)
+	width := 0
+	for _, line := range lines {
+		width = max(width, visibleWidth(line))
+	}
+	width = min(width+padX, screen.Width)
+	height := min(len(lines)+padY, screen.Height)
+
+	row := anchor.Row + max(anchor.Height, 1)
+	if row+height > screen.Row+screen.Height && anchor.Row-height >= screen.Row {
+		row = anchor.Row - height
+	}
+	row = max(min(row, screen.Row+screen.Height-height), screen.Row)
+	col := max(min(anchor.Col, screen.Col+screen.Width-width), screen.Col)
+	return Rect{Row: row, Col: col, Width: width, Height: height}
+}
+
+// Render returns the escape sequences drawing the tooltip next to anchor in
+// screen, first restoring what it covered when last drawn elsewhere.
+func (t *Tooltip) Render(anchor, screen Rect) string {
+	r := t.Rect(anchor, screen)
+	s := ""
+	if r != t.drawn {
+		s = t.Clear()
+	}
+	t.drawn = r
+	if t.Border.Vertical != "" {
+		return s + renderBox(r, t.Border, t.Style, "", t.lines(r.Width-4))
+	}
+	var sb strings.Builder
+	lines := t.lines(r.Width - 2)
+	for i := 0; i < r.Height; i++ {
+		line := ""
+		if i < len(lines) {
+			line = truncateWidth(lines[i], r.Width-2)
+		}
+		line = " " + padWidth(line, r.Width-2) + " "
+		sb.WriteString(fmt.Sprintf("\033[%d;%dH%s%s%s", r.Row+i, r.Col, t.Style, line, End))
+	}
+	return s + sb.String()
+}
+
+// Clear returns the escape sequences blanking where the tooltip was last
+// drawn and redrawing the backdrop, or "" if it is not shown.
+func (t *Tooltip) Clear() string {
+	r := t.drawn
+	if r.Width <= 0 || r.Height <= 0 {
+		return ""
+	}
+	t.drawn = Rect{}
+	var sb strings.Builder
+	for row := 0; row < r.Height; row++ {
+		sb.WriteString(fmt.Sprintf("\033[%d;%dH%s", r.Row+row, r.Col, strings.Repeat(" ", r.Width)))
+	}
+	if t.Backdrop != nil {
+		sb.WriteString(t.Backdrop())
+	}
+	return sb.String()
+}
+
+// Show draws the tooltip on w next to the cell at row and col, counted from
+// 1, such as the position of the cursor, which is left where it was.
+func (t *Tooltip) Show(w io.Writer, row, col int) {
+	width, height := termSize(w)
+	fmt.Fprint(w, "\0337"+t.Render(Rect{row, col, 1, 1}, Rect{1, 1, width, height})+"\0338")
+}
+
+// Hide erases the tooltip from w, leaving the cursor where it was.
+func (t *Tooltip) Hide(w io.Writer) {
+	if s := t.Clear(); s != "" {
+		fmt.Fprint(w, "\0337"+s+"\0338")
+	}
+}
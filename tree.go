@@ -0,0 +1,347 @@
+package ansi
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// --------------------
+// Tree
+// --------------------
+
+// TreeConnectors are the strings drawn before the labels of a Tree.
+type TreeConnectors struct {
+	Branch    string // Before a node with siblings below it.
+	Last      string // Before the last node of its parent.
+	Pipe      string // Continues the line of a parent with siblings below it.
+	Space     string // Indents below the last node of its parent.
+	Collapsed string // Marks a node whose children are hidden.
+	Expanded  string // Marks a node whose children are shown.
+}
+
+// Connector styles for a Tree.
+var (
+	TreeLight = TreeConnectors{"├── ", "└── ", "│   ", "    ", "▸ ", "▾ "}
+	TreeASCII = TreeConnectors{"|-- ", "`-- ", "|   ", "    ", "+ ", "- "}
+)
+
+// TreeNode is a node of a Tree.
+type TreeNode struct {
+	Label    string // May contain colors.
+	Value    any    // Data of the caller, such as a path or a JSON value.
+	Children []*TreeNode
+	Expanded bool // Whether the children are shown.
+	Branch   bool // Treat the node as having children even with none, like an empty directory.
+
+	// Load, if set, is called the first time the node is expanded to fill
+	// Children, so large or expensive trees can be loaded lazily.
+	Load   func(n *TreeNode) ([]*TreeNode, error)
+	loaded bool
+}
+
+// isBranch reports whether the node has, or may load, children.
+func (n *TreeNode) isBranch() bool {
+	return n.Branch || len(n.Children) > 0 || (n.Load != nil && !n.loaded)
+}
+
+// expand shows the children of the node, loading them first if needed.
+func (n *TreeNode) expand() error {
+	if n.Load != nil && !n.loaded {
+		children, err := n.Load(n)
+		if err != nil {
+			return err
+		}
+		n.Children = children
+		n.loaded = true
+	}
+	n.Expanded = true
+	return nil
+}
+
+// Tree shows hierarchical data, like directories or JSON, as an indented
+// tree that can be printed or browsed with Pick.
+type Tree struct {
+	Roots      []*TreeNode
+	Connectors TreeConnectors // TreeLight, or TreeASCII without UTF-8, if not set with NewTree.
+	Style      string         // Style of the connectors. The default is Faint.
+}
+
+// NewTree creates a tree of roots. It uses TreeLight connectors, or
+// TreeASCII if the locale is not UTF-8.
+func NewTree(roots ...*TreeNode) *Tree {
+	t := &Tree{Roots: roots, Connectors: TreeLight, Style: Faint}
+	if !unicodeLocale() {
+		t.Connectors = TreeASCII
+	}
+	return t
+}
+
+// treeLine is a visible node of a Tree with what is drawn before its label.
+type treeLine struct {
+	node   *TreeNode
+	prefix string
+	parent int // Index of the parent's line, or -1 for roots.
+}
+
+// lines returns the visible nodes, those whose ancestors are all expanded.
+func (t *Tree) lines() []treeLine {
+	var lines []treeLine
+	var walk func(nodes []*TreeNode, indent string, parent int)
+	walk = func(nodes []*TreeNode, indent string, parent int) {
+		for i, n := range nodes {
+			last := i == len(nodes)-1
+			prefix, more := indent, indent
+			if parent >= 0 {
+				if last {
+					prefix += t.Connectors.Last
+					more += t.Connectors.Space
+				} else {
+					prefix += t.Connectors.Branch
+					more += t.Connectors.Pipe
+				}
+			}
+			lines = append(lines, treeLine{n, prefix, parent})
+			if n.Expanded {
+				walk(n.Children, more, len(lines)-1)
+			}
+		}
+	}
+	walk(t.Roots, "", -1)
+	return lines
+}
+
+// label returns the text of a line: its marker and label.
+func (t *Tree) label(line treeLine) string {
+	switch {
+	case line.node.isBranch() && line.node.Expanded:
+		return t.Connectors.Expanded + line.node.Label
+	case line.node.isBranch():
+		return t.Connectors.Collapsed + line.node.Label
+	case t.Connectors.Collapsed != "":
+		return strings.Repeat(" ", visibleWidth(t.Connectors.Collapsed)) + line.node.Label
+	}
+	return line.node.Label
+}
+
+// connectors returns the prefix of a line in the tree's style.
+func (t *Tree) connectors(line treeLine) string {
+	if t.Style == "" || line.prefix == "" {
+		return line.prefix
+	}
+	return t.Style + line.prefix + End
+}
+
+// Print prints the tree to stdout.
+func (t *Tree) Print() {
+	fmt.Print(t.String())
+}
+
+// String renders the expanded part of the tree, one node per line.
+func (t *Tree) String() string {
+	var sb strings.Builder
+	for _, line := range t.lines() {
+		sb.WriteString(t.connectors(line) + t.label(line) + "\n")
+	}
+	return sb.String()
+}
+
+// Pick lets the user browse the tree and returns the node chosen with Enter.
+// Up and down (or k and j) move the highlight; right expands a node, or
+// moves to its first child if expanded, and left collapses it, or moves to
+// its parent. Enter expands or collapses a node with children and chooses
+// any other node, and Space chooses any node. Children given by Load are
+// loaded on first expanding, and errors are shown below the tree. Long trees
+// scroll like the lists of Select. Escape and Ctrl-C cancel with
+// ErrInterrupted. InputPrompt sets a line shown above the tree;
+// InputPromptStyle, InputErrorStyle, SelectPageSize, InputWriter and
+// InputKeyReader may be given in opts as well.
+func (t *Tree) Pick(opts ...InputOption) (*TreeNode, error) {
+	nodes, err := t.pick(false, opts)
+	if err != nil {
+		return nil, err
+	}
+	return nodes[0], nil
+}
+
+// PickMany is Pick for choosing several nodes: Space checks or unchecks the
+// highlighted node and Enter returns the checked nodes in tree order. With
+// none checked, Enter chooses a node without children as in Pick.
+// InputCheckboxes sets the checkboxes.
+func (t *Tree) PickMany(opts ...InputOption) ([]*TreeNode, error) {
+	return t.pick(true, opts)
+}
+
+// pick runs Pick, or PickMany if many is set.
+func (t *Tree) pick(many bool, opts []InputOption) ([]*TreeNode, error) {
+	cfg := &inputConfig{out: os.Stdout, errorStyle: Red, checked: Green + "◉" + End, unchecked: Faint + "◯" + End}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.keys == nil {
+		cfg.keys = defaultKeyReader()
+	}
+	prompt := cfg.prompt
+	if cfg.promptStyle != "" {
+		prompt = cfg.promptStyle + prompt + End
+	}
+	page := cfg.listPage()
+	index, top := 0, 0
+	errMsg := ""
+	checked := make(map[*TreeNode]bool)
+	fmt.Fprint(cfg.out, "\033[?25l")
+	defer fmt.Fprint(cfg.out, "\033[?25h")
+	for {
+		lines := t.lines()
+		index = max(min(index, len(lines)-1), 0)
+		top = scrollList(index, top, page)
+
+		var rows []string
+		if prompt != "" {
+			rows = append(rows, prompt)
+		}
+		end := min(top+page, len(lines))
+		for i := top; i < end; i++ {
+			label := t.label(lines[i])
+			if i == index {
+				label = Negative + stripANSI(label) + End
+			}
+			switch {
+			case !many:
+			case checked[lines[i].node]:
+				label = cfg.checked + " " + label
+			default:
+				label = cfg.unchecked + " " + label
+			}
+			rows = append(rows, t.connectors(lines[i])+label)
+		}
+		if len(lines) > page {
+			rows = append(rows, listPosition(index, len(lines)))
+		}
+		if errMsg != "" {
+			rows = append(rows, cfg.errorStyle+errMsg+End)
+		}
+		out := "\r\033[J" + strings.Join(rows, "\r\n")
+		if len(rows) > 1 {
+			out += fmt.Sprintf("\033[%dA", len(rows)-1)
+		}
+		fmt.Fprint(cfg.out, out)
+
+		keyType, key, err := cfg.keys.readKey()
+		if err != nil {
+			fmt.Fprint(cfg.out, "\r\033[J")
+			return nil, err
+		}
+		errMsg = ""
+		var node *TreeNode
+		parent := -1
+		if len(lines) > 0 {
+			node, parent = lines[index].node, lines[index].parent
+		}
+		var chosen []*TreeNode
+		switch {
+		case keyType == "Special" && (key == "escape" || key == "ctrl-c"):
+			fmt.Fprint(cfg.out, "\r\033[J")
+			if prompt != "" {
+				fmt.Fprintln(cfg.out, prompt)
+			}
+			if key == "escape" && cfg.escapeBack {
+				return nil, errBack
+			}
+			return nil, ErrInterrupted
+		case node == nil:
+		case keyType == "Arrow" && key == "up", keyType == "Character" && key == "k":
+			index--
+		case keyType == "Arrow" && key == "down", keyType == "Character" && key == "j":
+			index++
+		case keyType == "Special" && key == "pageup":
+			index -= page
+		case keyType == "Special" && key == "pagedown":
+			index += page
+		case keyType == "Special" && key == "home":
+			index = 0
+		case keyType == "Special" && key == "end":
+			index = len(lines) - 1
+		case keyType == "Arrow" && key == "right", keyType == "Character" && key == "l":
+			if node.Expanded && len(node.Children) > 0 {
+				index++
+			} else if node.isBranch() {
+				if err := node.expand(); err != nil {
+					errMsg = err.Error()
+				}
+			}
+		case keyType == "Arrow" && key == "left", keyType == "Character" && key == "h":
+			if node.Expanded && node.isBranch() {
+				node.Expanded = false
+			} else if parent >= 0 {
+				index = parent
+			}
+		case keyType == "Character" && key == " ":
+			if !many {
+				chosen = []*TreeNode{node}
+			} else if checked[node] {
+				delete(checked, node)
+			} else {
+				checked[node] = true
+			}
+		case keyType == "Special" && key == "enter":
+			if len(checked) > 0 {
+				chosen = t.checkedNodes(checked)
+				break
+			}
+			if !node.isBranch() {
+				chosen = []*TreeNode{node}
+				break
+			}
+			if node.Expanded {
+				node.Expanded = false
+			} else if err := node.expand(); err != nil {
+				errMsg = err.Error()
+			}
+		}
+		if chosen != nil {
+			fmt.Fprint(cfg.out, "\r\033[J")
+			if prompt != "" {
+				labels := make([]string, len(chosen))
+				for i, n := range chosen {
+					labels[i] = stripANSI(n.Label)
+				}
+				fmt.Fprintf(cfg.out, "%s %s\n", prompt, Cyan+strings.Join(labels, ", ")+End)
+			}
+			return chosen, nil
+		}
+	}
+}
+
+// checkedNodes returns the nodes in checked in tree order, including those
+// below collapsed nodes.
+func (t *Tree) checkedNodes(checked map[*TreeNode]bool) []*TreeNode {
+	var nodes []*TreeNode
+	var walk func([]*TreeNode)
+	walk = func(children []*TreeNode) {
+		for _, n := range children {
+			if checked[n] {
+				nodes = append(nodes, n)
+			}
+			walk(n.Children)
+		}
+	}
+	walk(t.Roots)
+	return nodes
+}
+
+// unicodeLocale reports whether the locale, as set by LC_ALL, LC_CTYPE or
+// LANG, uses UTF-8. Windows terminals are assumed to.
+func unicodeLocale() bool {
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if v := os.Getenv(name); v != "" {
+			v = strings.ToLower(v)
+			return strings.Contains(v, "utf-8") || strings.Contains(v, "utf8")
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package ansi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// --------------------
+// Typewriter
+// --------------------
+
+// TypeOption configures Typewrite.
+type TypeOption func(*typeConfig)
+
+// typeConfig holds the settings of Typewrite.
+type typeConfig struct {
+	ctx  context.Context
+	out  io.Writer
+	keys *KeyReader
+	skip bool
+}
+
+// TypeContext stops Typewrite when ctx is canceled, leaving the text
+// printed so far.
+func TypeContext(ctx context.Context) TypeOption {
+	return func(c *typeConfig) {
+		c.ctx = ctx
+	}
+}
+
+// TypeWriter sets where Typewrite prints. The default is os.Stdout.
+func TypeWriter(w io.Writer) TypeOption {
+	return func(c *typeConfig) {
+		c.out = w
+	}
+}
+
+// TypeKeyReader sets the KeyReader whose keys skip to the end of the text.
+// The default is the one set with SetKeyReader.
+func TypeKeyReader(kr *KeyReader) TypeOption {
+	return func(c *typeConfig) {
+		c.keys = kr
+	}
+}
+
+// TypeSkip sets whether a key press prints the rest of the text at once. It
+// is on by default.
+func TypeSkip(on bool) TypeOption {
+	return func(c *typeConfig) {
+		c.skip = on
+	}
+}
+
+// Typewrite prints s one character at a time, waiting delay after each, like
+// a typewriter. Colors and other escape sequences in s are printed whole and
+// do not take a turn. Pressing a key prints the rest at once; the key is not
+// passed on. If the context set with TypeContext is canceled, Typewrite
+// stops, resets the style and returns the context's error.
+//
+// Keys can only be noticed while typing on readers with a file descriptor,
+// like a terminal or a pipe, and not on Windows.
+func Typewrite(s string, delay time.Duration, opts ...TypeOption) error {
+	cfg := &typeConfig{ctx: context.Background(), out: os.Stdout, skip: true}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.keys == nil {
+		cfg.keys = defaultKeyReader()
+	}
+	kr := cfg.keys
+	newline := "\n"
+	if cfg.skip && kr.tty {
+		// Raw mode lets a single key be seen, and not echoed, without Enter,
+		// but then a newline no longer returns the cursor.
+		if state, err := term.MakeRaw(kr.fd); err == nil {
+			defer term.Restore(kr.fd, state)
+			newline = "\r\n"
+		}
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	for i := 0; i < len(s); {
+		if s[i] == '\033' {
+			n := escapeLen(s[i:])
+			fmt.Fprint(cfg.out, s[i:i+n])
+			i += n
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		if s[i] == '\n' {
+			fmt.Fprint(cfg.out, newline)
+		} else {
+			fmt.Fprint(cfg.out, s[i:i+size])
+		}
+		i += size
+
+		timer.Reset(delay)
+		if cfg.skip && kr.keyPressed() {
+			kr.readKey()
+			fmt.Fprint(cfg.out, strings.ReplaceAll(s[i:], "\n", newline))
+			return nil
+		}
+		select {
+		case <-cfg.ctx.Done():
+			fmt.Fprint(cfg.out, End)
+			return cfg.ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return nil
+}
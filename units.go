@@ -0,0 +1,42 @@
+package ansi
+
+import (
+	"fmt"
+	"math"
+)
+
+// --------------------
+// Units
+// --------------------
+
+// FormatBytes formats n bytes with binary prefixes, e.g. "12.3 MiB".
+func FormatBytes(n float64) string {
+	return formatUnit(n, 1024, []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}, " ")
+}
+
+// FormatBytesSI formats n bytes with decimal prefixes, e.g. "12.3 MB".
+func FormatBytesSI(n float64) string {
+	return formatUnit(n, 1000, []string{"B", "kB", "MB", "GB", "TB", "PB", "EB"}, " ")
+}
+
+// FormatCount formats a count with decimal prefixes, e.g. "12.3k".
+func FormatCount(n float64) string {
+	return formatUnit(n, 1000, []string{"", "k", "M", "G", "T", "P", "E"}, "")
+}
+
+// formatUnit scales n by base until it is below base and appends the matching
+// unit. Values that need no scaling are shown without decimals.
+func formatUnit(n, base float64, units []string, sep string) string {
+	if math.Abs(n) < base {
+		if n == math.Trunc(n) {
+			return fmt.Sprintf("%.0f%s%s", n, sep, units[0])
+		}
+		return fmt.Sprintf("%.1f%s%s", n, sep, units[0])
+	}
+	i := 0
+	for math.Abs(n) >= base && i < len(units)-1 {
+		n /= base
+		i++
+	}
+	return fmt.Sprintf("%.1f%s%s", n, sep, units[i])
+}
@@ -0,0 +1,278 @@
+package ansi
+
+import (
+	"os"
+	"unicode"
+)
+
+// --------------------
+// Vi Editing Mode
+// --------------------
+
+// Editing modes accepted by InputEditMode and the ANSI_EDIT_MODE environment variable.
+const (
+	EmacsMode = "emacs"
+	ViMode    = "vi"
+)
+
+// editModeEnv is the environment variable selecting the editing mode when
+// InputEditMode is not given.
+const editModeEnv = "ANSI_EDIT_MODE"
+
+// InputEditMode selects the key bindings of the prompt: EmacsMode (the
+// default) or ViMode. Without this option the ANSI_EDIT_MODE environment
+// variable is used, much like EDITOR selects an editor.
+func InputEditMode(mode string) InputOption {
+	return func(c *inputConfig) {
+		c.editMode = mode
+	}
+}
+
+// resolveEditMode returns the editing mode to use for cfg.
+func (cfg *inputConfig) resolveEditMode() string {
+	if cfg.editMode != "" {
+		return cfg.editMode
+	}
+	if os.Getenv(editModeEnv) == ViMode {
+		return ViMode
+	}
+	return EmacsMode
+}
+
+// viState is the state of vi mode in a running prompt.
+type viState struct {
+	normal     bool   // Whether normal (command) mode is active rather than insert mode.
+	pending    string // Operator or command waiting for the rest of its keys, e.g. "d" or "r".
+	undo       []rune // The line before the last change, restored by u.
+	undoCursor int
+	canUndo    bool
+}
+
+// handleViKey handles keys in vi mode and reports whether the key was used.
+// In insert mode only Escape is handled here; the rest falls through to the
+// usual editing keys.
+func (st *inputState) handleViKey(keyType, key string) bool {
+	vi := &st.vi
+	if keyType == "Special" && len(key) == 5 && key[:4] == "alt-" {
+		// Escape followed quickly by a key arrives as Alt+key.
+		st.handleViKey("Special", "escape")
+		st.handleViKey("Character", key[4:])
+		return true
+	}
+	if !vi.normal {
+		if keyType == "Special" && key == "escape" {
+			vi.normal = true
+			if st.cursor > 0 {
+				st.cursor--
+			}
+			return true
+		}
+		return false
+	}
+	if keyType == "Special" && key == "escape" {
+		vi.pending = ""
+		return true
+	}
+	if keyType == "Special" && key == "backspace" {
+		keyType, key = "Character", "h"
+	}
+	if keyType == "Arrow" {
+		switch key {
+		case "left":
+			key = "h"
+		case "right":
+			key = "l"
+		case "up":
+			key = "k"
+		case "down":
+			key = "j"
+		default:
+			return true
+		}
+		keyType = "Character"
+	}
+	if keyType == "Special" {
+		// Control keys keep their usual meaning.
+		return false
+	}
+	if keyType != "Character" {
+		return true
+	}
+
+	if vi.pending != "" {
+		st.viPending(key)
+		st.clampViCursor()
+		return true
+	}
+	switch key {
+	case "i":
+		vi.normal = false
+	case "a":
+		vi.normal = false
+		if st.cursor < len(st.text) {
+			st.cursor++
+		}
+	case "I":
+		vi.normal = false
+		st.cursor = 0
+	case "A":
+		vi.normal = false
+		st.cursor = len(st.text)
+	case "x":
+		st.viChange(st.cursor, min(st.cursor+1, len(st.text)), false)
+	case "X":
+		st.viChange(max(st.cursor-1, 0), st.cursor, false)
+	case "D":
+		st.viChange(st.cursor, len(st.text), false)
+	case "C":
+		st.viChange(st.cursor, len(st.text), true)
+	case "S":
+		st.viChange(0, len(st.text), true)
+	case "p":
+		if len(st.killed) > 0 {
+			st.viSnapshot()
+			if st.cursor < len(st.text) {
+				st.cursor++
+			}
+			st.insert(st.killed)
+			st.cursor--
+		}
+	case "P":
+		if len(st.killed) > 0 {
+			st.viSnapshot()
+			st.insert(st.killed)
+			st.cursor--
+		}
+	case "u":
+		if vi.canUndo {
+			undo, cursor := vi.undo, vi.undoCursor
+			st.viSnapshot()
+			st.text, st.cursor = undo, cursor
+		}
+	case "k":
+		st.historyPrev()
+	case "j":
+		st.historyNext()
+	case "d", "c", "y", "r":
+		vi.pending = key
+	default:
+		if to, ok := st.viMotion(key); ok {
+			st.cursor = to
+		}
+	}
+	st.clampViCursor()
+	return true
+}
+
+// viPending completes an operator (d, c, y) with a motion, or a replace (r)
+// with the replacement character.
+func (st *inputState) viPending(key string) {
+	op := st.vi.pending
+	st.vi.pending = ""
+	if op == "r" {
+		if st.cursor < len(st.text) {
+			st.viSnapshot()
+			st.text[st.cursor] = []rune(key)[0]
+		}
+		return
+	}
+
+	from, to := 0, len(st.text)
+	if op == "c" && key == "w" {
+		// Like vi, cw changes to the end of the word rather than the next one.
+		key = "e"
+	}
+	if key != op {
+		// Doubled operators (dd, cc, yy) act on the whole line.
+		target, ok := st.viMotion(key)
+		if !ok {
+			return
+		}
+		from, to = st.cursor, target
+		if key == "e" && to < len(st.text) {
+			to++
+		}
+		if from > to {
+			from, to = to, from
+		}
+	}
+	switch op {
+	case "y":
+		st.killed = append([]rune(nil), st.text[from:to]...)
+	case "d":
+		st.viChange(from, to, false)
+	case "c":
+		st.viChange(from, to, true)
+	}
+}
+
+// viMotion returns the cursor position a motion key moves to.
+func (st *inputState) viMotion(key string) (int, bool) {
+	switch key {
+	case "h":
+		return max(st.cursor-1, 0), true
+	case "l":
+		return min(st.cursor+1, len(st.text)), true
+	case "0", "^":
+		return 0, true
+	case "$":
+		return len(st.text), true
+	case "w":
+		i := st.cursor
+		for i < len(st.text) && !unicode.IsSpace(st.text[i]) {
+			i++
+		}
+		for i < len(st.text) && unicode.IsSpace(st.text[i]) {
+			i++
+		}
+		return i, true
+	case "b":
+		return st.wordStart(), true
+	case "e":
+		i := st.cursor + 1
+		for i < len(st.text) && unicode.IsSpace(st.text[i]) {
+			i++
+		}
+		for i+1 < len(st.text) && !unicode.IsSpace(st.text[i+1]) {
+			i++
+		}
+		return min(i, max(len(st.text)-1, 0)), true
+	}
+	return 0, false
+}
+
+// viChange deletes the runes between from and to into the kill buffer,
+// switching to insert mode if insert is set.
+func (st *inputState) viChange(from, to int, insert bool) {
+	st.viSnapshot()
+	if from < to {
+		st.kill(from, to)
+	}
+	st.cursor = from
+	if insert {
+		st.vi.normal = false
+	}
+}
+
+// viSnapshot saves the line for undo.
+func (st *inputState) viSnapshot() {
+	st.vi.undo = append([]rune(nil), st.text...)
+	st.vi.undoCursor = st.cursor
+	st.vi.canUndo = true
+}
+
+// clampViCursor keeps the cursor on a character in normal mode, as vi does.
+func (st *inputState) clampViCursor() {
+	if st.vi.normal && st.cursor >= len(st.text) {
+		st.cursor = max(len(st.text)-1, 0)
+	}
+}
+
+// viCursorShape returns the sequence selecting a block cursor in normal mode
+// and a bar cursor in insert mode.
+func (st *inputState) viCursorShape() string {
+	if st.vi.normal {
+		return "\033[2 q"
+	}
+	return "\033[6 q"
+}
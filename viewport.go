@@ -0,0 +1,303 @@
+package ansi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// --------------------
+// Viewport
+// --------------------
+
+// Viewport holds content of any length, which may contain colors, and shows
+// a window of it in a rectangle of the screen. Lines longer than the width
+// wrap. It is not safe for use from several goroutines.
+type Viewport struct {
+	Row, Col      int    // Screen position of the top left corner, counted from 1.
+	Width, Height int    // Size in columns and rows.
+	Indicator     bool   // Show the scroll position as a percentage in the bottom row.
+	Highlight     string // Text shown in Negative wherever it appears, ignoring case.
+
+	// Scrollbar, if set, is drawn in the last column when the content is
+	// longer than the view.
+	Scrollbar *Scrollbar
+
+	lines    []string // The content, one entry per line.
+	rows     []string // The lines wrapped to wrapped columns.
+	wrap     int      // Width the rows were wrapped to, or 0 if not wrapped yet.
+	top      int      // First row shown.
+	dragging bool     // Whether the thumb of the scrollbar is being dragged.
+}
+
+// NewViewport creates an empty viewport at row and col of the screen.
+func NewViewport(row, col, width, height int) *Viewport {
+	return &Viewport{Row: row, Col: col, Width: width, Height: height}
+}
+
+// SetContent replaces the content with s, split into lines.
+func (v *Viewport) SetContent(s string) {
+	s = strings.TrimSuffix(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	v.SetLines(strings.Split(s, "\n"))
+}
+
+// SetLines replaces the content with lines, keeping the scroll position
+// where possible.
+func (v *Viewport) SetLines(lines []string) {
+	v.lines = v.lines[:0]
+	for _, line := range lines {
+		v.lines = append(v.lines, strings.ReplaceAll(line, "\t", "    "))
+	}
+	v.wrap = 0
+}
+
+// AppendLine adds line to the end of the content. If the view was at the
+// bottom it stays there, following the new line.
+func (v *Viewport) AppendLine(line string) {
+	follow := v.AtBottom()
+	line = strings.ReplaceAll(line, "\t", "    ")
+	v.lines = append(v.lines, line)
+	if v.wrap > 0 {
+		v.rows = append(v.rows, wrapRows(line, v.wrap)...)
+	}
+	if follow {
+		v.GotoBottom()
+	}
+}
+
+// Lines returns the number of lines of content.
+func (v *Viewport) Lines() int {
+	return len(v.lines)
+}
+
+// ScrollUp scrolls up n rows.
+func (v *Viewport) ScrollUp(n int) {
+	v.ScrollTo(v.top - n)
+}
+
+// ScrollDown scrolls down n rows.
+func (v *Viewport) ScrollDown(n int) {
+	v.ScrollTo(v.top + n)
+}
+
+// PageUp scrolls up a page.
+func (v *Viewport) PageUp() {
+	v.ScrollUp(v.page())
+}
+
+// PageDown scrolls down a page.
+func (v *Viewport) PageDown() {
+	v.ScrollDown(v.page())
+}
+
+// GotoTop scrolls to the first row.
+func (v *Viewport) GotoTop() {
+	v.top = 0
+}
+
+// GotoBottom scrolls to show the last row at the bottom.
+func (v *Viewport) GotoBottom() {
+	v.ScrollTo(len(v.wrapped()))
+}
+
+// ScrollTo scrolls to show row first, or as close as possible with the view
+// still full.
+func (v *Viewport) ScrollTo(row int) {
+	v.top = max(min(row, len(v.wrapped())-v.page()), 0)
+}
+
+// AtTop reports whether the first row is shown.
+func (v *Viewport) AtTop() bool {
+	return v.top == 0
+}
+
+// AtBottom reports whether the last row is shown.
+func (v *Viewport) AtBottom() bool {
+	return v.top+v.page() >= len(v.wrapped())
+}
+
+// Percent returns how far the view is scrolled, from 0 at the top to 100
+// at the bottom.
+func (v *Viewport) Percent() float64 {
+	rows := len(v.wrapped())
+	if rows <= v.page() {
+		return 100
+	}
+	return float64(v.top) * 100 / float64(rows-v.page())
+}
+
+// Find scrolls to the first row from the top of the view that contains text,
+// ignoring case, searching down if dir is positive and up otherwise. The row
+// at the top is skipped unless dir is 0. It reports whether a row was found.
+func (v *Viewport) Find(text string, dir int) bool {
+	rows := v.wrapped()
+	text = strings.ToLower(text)
+	from, step := v.top, 1
+	switch {
+	case dir > 0:
+		from++
+	case dir < 0:
+		from, step = from-1, -1
+	}
+	for i := from; i >= 0 && i < len(rows); i += step {
+		if strings.Contains(strings.ToLower(stripANSI(rows[i])), text) {
+			v.ScrollTo(i)
+			return true
+		}
+	}
+	return false
+}
+
+// View returns the rows shown, each padded to the width.
+func (v *Viewport) View() []string {
+	rows := v.wrapped()
+	v.ScrollTo(v.top)
+	view := make([]string, 0, v.Height)
+	var bar []string
+	if v.scrolled() {
+		bar = v.Scrollbar.Cells(v.page(), len(rows), v.page(), v.top)
+	}
+	for i := v.top; i < v.top+v.page(); i++ {
+		row := ""
+		if i < len(rows) {
+			row = highlightText(rows[i], v.Highlight, Negative)
+		}
+		if bar != nil {
+			row = padWidth(row, v.Width-1) + bar[i-v.top]
+		}
+		view = append(view, row+strings.Repeat(" ", max(v.Width-visibleWidth(row), 0)))
+	}
+	if v.Indicator && v.Height > 1 {
+		label := fmt.Sprintf("%.0f%%", v.Percent())
+		view = append(view, strings.Repeat(" ", max(v.Width-len(label), 0))+Faint+label+End)
+	}
+	return view
+}
+
+// Render returns the escape sequences drawing the view at its position.
+func (v *Viewport) Render() string {
+	var sb strings.Builder
+	for i, row := range v.View() {
+		sb.WriteString(fmt.Sprintf("\033[%d;%dH%s", v.Row+i, v.Col, row))
+	}
+	return sb.String()
+}
+
+// HandleMouse scrolls with the wheel and, when the scrollbar is shown, to
+// where its track is clicked or its thumb dragged with the left button, and
+// reports whether the event was used.
+func (v *Viewport) HandleMouse(ev MouseEvent) bool {
+	in := ev.Row >= v.Row && ev.Row < v.Row+v.page() && ev.Col >= v.Col && ev.Col < v.Col+v.Width
+	switch {
+	case v.dragging && ev.Release:
+		v.dragging = false
+		return true
+	case v.dragging && ev.Motion:
+		v.ScrollTo(ScrollOffset(ev.Row-v.Row, v.page(), len(v.wrapped()), v.page()))
+		return true
+	case !in || ev.Release || ev.Motion:
+		return false
+	case ev.Button == MouseWheelUp:
+		v.ScrollUp(3)
+	case ev.Button == MouseWheelDown:
+		v.ScrollDown(3)
+	case ev.Button == MouseLeft && v.scrolled() && ev.Col == v.Col+v.Width-1:
+		v.dragging = true
+		v.ScrollTo(ScrollOffset(ev.Row-v.Row, v.page(), len(v.wrapped()), v.page()))
+	default:
+		return false
+	}
+	return true
+}
+
+// scrolled reports whether the scrollbar is shown.
+func (v *Viewport) scrolled() bool {
+	return v.Scrollbar != nil && v.Width > 1 && len(v.wrapped()) > v.page()
+}
+
+// page returns the number of content rows shown.
+func (v *Viewport) page() int {
+	if v.Indicator && v.Height > 1 {
+		return v.Height - 1
+	}
+	return max(v.Height, 1)
+}
+
+// wrapped returns the content wrapped to the width, wrapping it again if the
+// width changed.
+func (v *Viewport) wrapped() []string {
+	width := max(v.Width, 1)
+	if v.Scrollbar != nil && v.Width > 1 {
+		// Leave the last column to the scrollbar.
+		width--
+	}
+	if v.wrap != width {
+		v.rows = v.rows[:0]
+		for _, line := range v.lines {
+			v.rows = append(v.rows, wrapRows(line, width)...)
+		}
+		v.wrap = width
+	}
+	return v.rows
+}
+
+// highlightText shows every match of query in s, ignoring case and escape
+// sequences, in style. The colors of s resume after each match.
+func highlightText(s, query, style string) string {
+	if query == "" {
+		return s
+	}
+	plain := stripANSI(s)
+	lower, lowerQuery := strings.ToLower(plain), strings.ToLower(query)
+	if len(lower) != len(plain) {
+		// Changing case changed the length, so match the case exactly.
+		lower, lowerQuery = plain, query
+	}
+	var matches []int // Start of each match in plain.
+	for i := 0; ; {
+		j := strings.Index(lower[i:], lowerQuery)
+		if j < 0 {
+			break
+		}
+		matches = append(matches, i+j)
+		i += j + len(lowerQuery)
+	}
+	if len(matches) == 0 {
+		return s
+	}
+
+	var sb strings.Builder
+	active := "" // Styles of s in effect, restored after a match.
+	pos, m, end := 0, 0, -1
+	for i := 0; i < len(s); {
+		if s[i] == '\033' {
+			n := escapeLen(s[i:])
+			seq := s[i : i+n]
+			sb.WriteString(seq)
+			if strings.HasPrefix(seq, "\033[") && strings.HasSuffix(seq, "m") {
+				if seq == End || seq == "\033[m" {
+					active = ""
+				} else {
+					active += seq
+				}
+				if end >= 0 {
+					sb.WriteString(style)
+				}
+			}
+			i += n
+			continue
+		}
+		if m < len(matches) && pos == matches[m] {
+			sb.WriteString(style)
+			end = pos + len(lowerQuery)
+			m++
+		}
+		sb.WriteByte(s[i])
+		i++
+		pos++
+		if pos == end {
+			sb.WriteString(End + active)
+			end = -1
+		}
+	}
+	return sb.String()
+}
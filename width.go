@@ -0,0 +1,263 @@
+package ansi
+
+import (
+	"io"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// --------------------
+// Display Width
+// --------------------
+
+// stripANSI removes CSI (colors, cursor movement) and OSC (e.g. Link)
+// escape sequences from s.
+func stripANSI(s string) string {
+	if !strings.Contains(s, "\033") {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\033' {
+			sb.WriteByte(s[i])
+			continue
+		}
+		i += escapeLen(s[i:]) - 1
+	}
+	return sb.String()
+}
+
+// escapeLen returns the length of the escape sequence at the start of s.
+func escapeLen(s string) int {
+	if len(s) < 2 {
+		return len(s)
+	}
+	switch s[1] {
+	case '[':
+		for i := 2; i < len(s); i++ {
+			if s[i] >= 0x40 && s[i] <= 0x7e {
+				return i + 1
+			}
+		}
+	case ']':
+		for i := 2; i < len(s); i++ {
+			if s[i] == '\a' {
+				return i + 1
+			}
+			if s[i] == '\033' && i+1 < len(s) && s[i+1] == '\\' {
+				return i + 2
+			}
+		}
+	default:
+		return 2
+	}
+	return len(s)
+}
+
+// visibleWidth returns the number of terminal columns s occupies, ignoring
+// escape sequences and counting wide characters as two columns.
+func visibleWidth(s string) int {
+	width := 0
+	for _, r := range stripANSI(s) {
+		width += runeWidth(r)
+	}
+	return width
+}
+
+// runesWidth returns the number of terminal columns the runes occupy.
+func runesWidth(rs []rune) int {
+	width := 0
+	for _, r := range rs {
+		width += runeWidth(r)
+	}
+	return width
+}
+
+// runeWidth returns the number of terminal columns r occupies: 0 for control
+// and combining characters, 2 for East Asian wide characters and emoji, 1 otherwise.
+func runeWidth(r rune) int {
+	switch {
+	case r == utf8.RuneError || r < 32 || (r >= 0x7f && r < 0xa0):
+		return 0
+	case unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Me, r) || r == 0x200b:
+		return 0
+	case r >= 0x1100 && r <= 0x115f,
+		r >= 0x2e80 && r <= 0xa4cf && r != 0x303f,
+		r >= 0xac00 && r <= 0xd7a3,
+		r >= 0xf900 && r <= 0xfaff,
+		r >= 0xfe30 && r <= 0xfe4f,
+		r >= 0xff00 && r <= 0xff60,
+		r >= 0xffe0 && r <= 0xffe6,
+		r >= 0x1f300 && r <= 0x1f64f,
+		r >= 0x1f900 && r <= 0x1f9ff,
+		r >= 0x20000 && r <= 0x3fffd:
+		return 2
+	}
+	return 1
+}
+
+// truncateWidth cuts s to at most width columns, ending it with "…" if it
+// was too long. Escape sequences are kept, and reset at the end if cut.
+func truncateWidth(s string, width int) string {
+	if visibleWidth(s) <= width {
+		return s
+	}
+	if width <= 0 {
+		return ""
+	}
+	var sb strings.Builder
+	cols := 0
+	for i := 0; i < len(s); {
+		if s[i] == '\033' {
+			n := escapeLen(s[i:])
+			sb.WriteString(s[i : i+n])
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if cols+runeWidth(r) > width-1 {
+			break
+		}
+		sb.WriteRune(r)
+		cols += runeWidth(r)
+		i += size
+	}
+	sb.WriteString("…")
+	if strings.Contains(s, "\033") {
+		sb.WriteString(End)
+	}
+	return sb.String()
+}
+
+// wrapText wraps s into lines of at most width columns, breaking at spaces
+// and, for words longer than a line, within words. Line breaks in s are kept.
+// Colors are closed at the end of each line and reopened on the next.
+func wrapText(s string, width int) []string {
+	width = max(width, 1)
+	var lines []string
+	for _, para := range strings.Split(s, "\n") {
+		start := len(lines)
+		line, col := "", 0
+		for _, word := range strings.Split(para, " ") {
+			w := visibleWidth(word)
+			switch {
+			case col > 0 && col+1+w <= width:
+				line += " " + word
+				col += 1 + w
+				continue
+			case col > 0:
+				lines = append(lines, line)
+			}
+			line, col = "", 0
+			for w > width {
+				head := cutWidth(word, width)
+				lines = append(lines, head)
+				word = word[len(head):]
+				w = visibleWidth(word)
+			}
+			line, col = word, w
+		}
+		lines = append(lines, line)
+		carryStyles(lines[start:])
+	}
+	return lines
+}
+
+// wrapRows splits line into rows of at most width columns, breaking
+// anywhere. Colors carry over from row to row.
+func wrapRows(line string, width int) []string {
+	width = max(width, 1)
+	var rows []string
+	for visibleWidth(line) > width {
+		row := cutWidth(line, width)
+		rows = append(rows, row)
+		line = line[len(row):]
+	}
+	rows = append(rows, line)
+	carryStyles(rows)
+	return rows
+}
+
+// cutWidth returns the longest prefix of s that is at most width columns
+// wide, including the escape sequences within it, and at least one rune.
+func cutWidth(s string, width int) string {
+	cols := 0
+	for i := 0; i < len(s); {
+		if s[i] == '\033' {
+			i += escapeLen(s[i:])
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if cols+runeWidth(r) > width && cols > 0 {
+			return s[:i]
+		}
+		cols += runeWidth(r)
+		i += size
+	}
+	return s
+}
+
+// skipWidth returns s without its first width columns. s must not contain
+// escape sequences.
+func skipWidth(s string, width int) string {
+	cols := 0
+	for i, r := range s {
+		if cols >= width {
+			return s[i:]
+		}
+		cols += runeWidth(r)
+	}
+	return ""
+}
+
+// carryStyles ends each line that leaves a color or style on with End and
+// starts the next line with the same styles.
+func carryStyles(lines []string) {
+	active := ""
+	for i, line := range lines {
+		prefix := active
+		for j := 0; j < len(line); j++ {
+			if line[j] != '\033' {
+				continue
+			}
+			n := escapeLen(line[j:])
+			if seq := line[j : j+n]; strings.HasSuffix(seq, "m") && seq[1] == '[' {
+				if seq == End || seq == "\033[m" {
+					active = ""
+				} else {
+					active += seq
+				}
+			}
+			j += n - 1
+		}
+		if active != "" {
+			line += End
+		}
+		lines[i] = prefix + line
+	}
+}
+
+// termSize returns the width and height of the terminal w writes to, or 80x24
+// if w is not a terminal.
+func termSize(w io.Writer) (int, int) {
+	if f, ok := w.(interface{ Fd() uintptr }); ok {
+		if width, height, err := term.GetSize(int(f.Fd())); err == nil && width > 0 {
+			return width, height
+		}
+	}
+	return 80, 24
+}
+
+// isTerminal reports whether w is a terminal that understands escape
+// sequences. It is false for files and pipes, and when TERM is "dumb".
+func isTerminal(w io.Writer) bool {
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	f, ok := w.(interface{ Fd() uintptr })
+	return ok && term.IsTerminal(int(f.Fd()))
+}
@@ -0,0 +1,175 @@
+package ansi
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// --------------------
+// Wizard
+// --------------------
+
+// Kinds of WizardStep.
+const (
+	WizardText    = "text"
+	WizardSelect  = "select"
+	WizardConfirm = "confirm"
+)
+
+// WizardStep is one question of a Wizard.
+type WizardStep struct {
+	Name     string             // Key of the answer in the result.
+	Prompt   string             // Question shown to the user.
+	Kind     string             // WizardText (the default), WizardSelect or WizardConfirm.
+	Options  []string           // Choices of a WizardSelect step.
+	Default  any                // Initial answer: a string, or a bool for WizardConfirm.
+	Validate func(string) error // Checks the answer of a WizardText step.
+
+	// When, if set, decides from the answers so far whether the step is asked.
+	When func(answers map[string]any) bool
+}
+
+// Wizard asks a series of questions, one step after the other. Escape goes
+// back to the previous step and Ctrl-C cancels the whole wizard.
+type Wizard struct {
+	Steps   []WizardStep
+	Summary bool // Show the answers at the end and ask for confirmation.
+}
+
+// errBack is returned by prompts when Escape is pressed and inputEscapeBack is set.
+var errBack = errors.New("ansi: back")
+
+// inputEscapeBack makes Escape return errBack instead of ErrInterrupted.
+func inputEscapeBack() InputOption {
+	return func(c *inputConfig) {
+		c.escapeBack = true
+	}
+}
+
+// Run asks the questions and returns the answers by step name: a string for
+// text and select steps and a bool for confirm steps. Steps skipped by their
+// When function have no answer. InputWriter, InputKeyReader and
+// InputPromptStyle in opts apply to every step.
+func (w *Wizard) Run(opts ...InputOption) (map[string]any, error) {
+	cfg := &inputConfig{out: os.Stdout}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	answers := make(map[string]any)
+	var asked []int // Indexes of the steps answered so far, for going back.
+
+	for i := 0; i <= len(w.Steps); {
+		if i == len(w.Steps) {
+			if !w.Summary {
+				break
+			}
+			ok, err := w.summary(cfg, answers, opts)
+			if err == nil && ok {
+				break
+			}
+			if err != nil && err != errBack {
+				return answers, err
+			}
+			// Erase the summary, its question and the last step's line.
+			fmt.Fprint(cfg.out, w.clearLines(len(asked)+2))
+		} else {
+			step := w.Steps[i]
+			if step.When != nil && !step.When(answers) {
+				delete(answers, step.Name)
+				i++
+				continue
+			}
+			answer, err := w.ask(step, answers[step.Name], opts)
+			if err == nil {
+				answers[step.Name] = answer
+				asked = append(asked, i)
+				i++
+				continue
+			}
+			if err != errBack {
+				return answers, err
+			}
+			if len(asked) == 0 {
+				return answers, ErrInterrupted
+			}
+			// Erase this step's line and the line of the step before it.
+			fmt.Fprint(cfg.out, w.clearLines(2))
+		}
+		// Go back to the last step that was asked.
+		i = asked[len(asked)-1]
+		asked = asked[:len(asked)-1]
+	}
+	return answers, nil
+}
+
+// clearLines returns the sequence erasing the n lines above the cursor.
+func (w *Wizard) clearLines(n int) string {
+	return fmt.Sprintf("\033[%dF\033[J", n)
+}
+
+// ask asks a single step, starting from the previous answer if there is one.
+func (w *Wizard) ask(step WizardStep, previous any, opts []InputOption) (any, error) {
+	def := step.Default
+	if previous != nil {
+		def = previous
+	}
+	opts = append(opts[:len(opts):len(opts)], inputEscapeBack())
+	switch step.Kind {
+	case WizardSelect:
+		cfg := &inputConfig{out: os.Stdout}
+		for _, opt := range opts {
+			opt(cfg)
+		}
+		index := 0
+		for j, option := range step.Options {
+			if option == def {
+				index = j
+			}
+		}
+		index, err := pickOne(cfg, step.Prompt, textOptions(step.Options), index)
+		if err != nil {
+			return nil, err
+		}
+		return step.Options[index], nil
+	case WizardConfirm:
+		b, _ := def.(bool)
+		return Confirm(step.Prompt, b, opts...)
+	default:
+		s, _ := def.(string)
+		opts = append(opts, InputPrompt(step.Prompt), InputDefault(s))
+		if step.Validate != nil {
+			opts = append(opts, InputValidator(step.Validate))
+		}
+		return Input(opts...)
+	}
+}
+
+// summary lists the answers and asks whether they are correct.
+func (w *Wizard) summary(cfg *inputConfig, answers map[string]any, opts []InputOption) (bool, error) {
+	var sb strings.Builder
+	for _, step := range w.Steps {
+		answer, ok := answers[step.Name]
+		if !ok {
+			continue
+		}
+		if b, isBool := answer.(bool); isBool {
+			answer = "No"
+			if b {
+				answer = "Yes"
+			}
+		}
+		label := strings.TrimRight(step.Prompt, "?: ")
+		sb.WriteString(fmt.Sprintf("  %s%s:%s %v\n", Bold, label, End, answer))
+	}
+	fmt.Fprint(cfg.out, sb.String())
+	ok, err := Confirm("Is this correct?", true, append(opts[:len(opts):len(opts)], inputEscapeBack())...)
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		return false, errBack
+	}
+	return true, nil
+}